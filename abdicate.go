@@ -0,0 +1,48 @@
+package shutdown
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+type abdication struct {
+	timeout time.Duration
+	fn      func(ctx context.Context) error
+}
+
+// WithAbdication adds a function that gives up leadership, such as by
+// releasing a leader election lock or revoking a lease, which is called at the
+// very start of the first soft or hard stop of the signaller, before the stop
+// is signalled to any channel, context or hook. This moves leadership to
+// another instance as soon as the process begins stopping, rather than once
+// the lease expires after the process has exited.
+//
+// Functions are called in the order they were added, each with a context that
+// is cancelled once the timeout elapses, and the stop is signalled once they
+// have all returned. Concurrent triggers of the signaller block until then, and
+// so the functions must not trigger the signaller themselves. Their errors are
+// recorded against the signaller and do not prevent the stop.
+func WithAbdication(timeout time.Duration, fn func(ctx context.Context) error) Option {
+	return func(o *options) {
+		o.abdications = append(o.abdications, abdication{timeout: timeout, fn: fn})
+	}
+}
+
+// abdicate calls the abdication functions of the signaller, once.
+func (s *Signaller) abdicate() {
+	x := s.ext.Load()
+	if x == nil || len(x.abdications) == 0 {
+		return
+	}
+	x.abdicated.Do(func() {
+		for _, a := range x.abdications {
+			ctx, cancel := context.WithTimeout(context.Background(), a.timeout)
+			err := a.fn(ctx)
+			cancel()
+			if err != nil {
+				s.RecordStopErr(fmt.Errorf("abdicating: %w", err))
+			}
+		}
+	})
+}
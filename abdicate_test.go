@@ -0,0 +1,48 @@
+package shutdown
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAbdication(t *testing.T) {
+	var s *Signaller
+	var calls []string
+	errLease := errors.New("lease already expired")
+	s = NewSignaller(
+		WithAbdication(time.Second, func(ctx context.Context) error {
+			assert.False(t, s.IsSoftStopSignalled())
+			_, hasDeadline := ctx.Deadline()
+			assert.True(t, hasDeadline)
+			calls = append(calls, "lock")
+			return nil
+		}),
+		WithAbdication(time.Second, func(ctx context.Context) error {
+			calls = append(calls, "lease")
+			return errLease
+		}),
+	)
+	s.OnSoftStop(func() {
+		calls = append(calls, "hook")
+	})
+
+	s.TriggerSoftStop()
+	s.TriggerHardStop()
+	assert.Equal(t, []string{"lock", "lease", "hook"}, calls)
+	assert.ErrorIs(t, s.StopErr(), errLease)
+}
+
+func TestAbdicationTimeout(t *testing.T) {
+	s := NewSignaller(WithAbdication(time.Millisecond, func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}))
+
+	s.TriggerHardStop()
+	assert.True(t, s.IsHardStopSignalled())
+	assert.ErrorIs(t, s.StopErr(), context.DeadlineExceeded)
+}
@@ -0,0 +1,128 @@
+package shutdown
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// UnacknowledgedError is recorded against a signaller when SoftStopAcks.Wait
+// gives up before every component has acknowledged the soft stop, and names
+// those that did not.
+type UnacknowledgedError struct {
+	Components []string
+}
+
+// Error returns a description of the components that did not acknowledge.
+func (e *UnacknowledgedError) Error() string {
+	return fmt.Sprintf("soft stop not acknowledged by: %v", strings.Join(e.Components, ", "))
+}
+
+// SoftStopAcks is an opt-in protocol where a set of named components, such as
+// request handlers, acknowledge that they have observed a soft stop of a
+// signaller before the next phase of a shutdown proceeds, for example
+// deregistering from a load balancer only once handlers are known to be
+// draining.
+//
+// Acknowledgements made before the soft stop has been signalled are ignored,
+// as they cannot have observed it.
+type SoftStopAcks struct {
+	s *Signaller
+
+	mut     sync.Mutex
+	missing map[string]struct{}
+	acked   chan struct{}
+}
+
+// NewSoftStopAcks creates acknowledgements of the soft stop of the signaller,
+// which are expected from each of the named components.
+func NewSoftStopAcks(s *Signaller, components ...string) *SoftStopAcks {
+	a := &SoftStopAcks{
+		s:       s,
+		missing: map[string]struct{}{},
+		acked:   make(chan struct{}),
+	}
+	for _, c := range components {
+		a.missing[c] = struct{}{}
+	}
+	if len(a.missing) == 0 {
+		close(a.acked)
+	}
+	return a
+}
+
+// AckSoftStop records that the named component has observed the soft stop.
+// Returns false if the acknowledgement was ignored, which is the case when the
+// soft stop has not been signalled yet, or when the component is unknown or
+// has already acknowledged.
+func (a *SoftStopAcks) AckSoftStop(component string) bool {
+	if !a.s.IsSoftStopSignalled() {
+		return false
+	}
+
+	a.mut.Lock()
+	defer a.mut.Unlock()
+
+	if _, ok := a.missing[component]; !ok {
+		return false
+	}
+	delete(a.missing, component)
+	if len(a.missing) == 0 {
+		close(a.acked)
+	}
+	return true
+}
+
+// Unacknowledged returns the names of the components that have not yet
+// acknowledged the soft stop, sorted.
+func (a *SoftStopAcks) Unacknowledged() []string {
+	a.mut.Lock()
+	names := make([]string, 0, len(a.missing))
+	for c := range a.missing {
+		names = append(names, c)
+	}
+	a.mut.Unlock()
+
+	sort.Strings(names)
+	return names
+}
+
+// Wait blocks until a soft stop has been signalled and every component has
+// acknowledged it. Once the soft stop is signalled, components are given until
+// the timeout elapses to acknowledge, and a timeout of zero or less waits until
+// a hard stop is signalled instead. If the wait is abandoned, by the timeout, a
+// hard stop or the context being cancelled, an *UnacknowledgedError naming the
+// components that did not acknowledge is recorded against the signaller and
+// returned.
+func (a *SoftStopAcks) Wait(ctx context.Context, timeout time.Duration) error {
+	select {
+	case <-a.s.SoftStopChan():
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	var done context.CancelFunc
+	if timeout > 0 {
+		ctx, done = a.s.HardStopCtxWithTimeout(ctx, timeout)
+	} else {
+		ctx, done = a.s.HardStopCtx(ctx)
+	}
+	defer done()
+
+	select {
+	case <-a.acked:
+		return nil
+	case <-ctx.Done():
+	}
+
+	missing := a.Unacknowledged()
+	if len(missing) == 0 {
+		return nil
+	}
+	err := &UnacknowledgedError{Components: missing}
+	a.s.RecordStopErr(err)
+	return err
+}
@@ -0,0 +1,83 @@
+package shutdown
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSoftStopAcks(t *testing.T) {
+	s := NewSignaller()
+	a := NewSoftStopAcks(s, "foo", "bar")
+
+	assert.False(t, a.AckSoftStop("foo"))
+	assert.Equal(t, []string{"bar", "foo"}, a.Unacknowledged())
+
+	waitErr := make(chan error, 1)
+	go func() {
+		waitErr <- a.Wait(context.Background(), time.Minute)
+	}()
+
+	s.TriggerSoftStop()
+	assert.True(t, a.AckSoftStop("foo"))
+	assert.False(t, a.AckSoftStop("foo"))
+	assert.False(t, a.AckSoftStop("baz"))
+	assert.Equal(t, []string{"bar"}, a.Unacknowledged())
+
+	select {
+	case err := <-waitErr:
+		t.Fatalf("returned early: %v", err)
+	case <-time.After(time.Millisecond * 10):
+	}
+
+	assert.True(t, a.AckSoftStop("bar"))
+	select {
+	case err := <-waitErr:
+		require.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("timed out")
+	}
+	assert.NoError(t, s.StopErr())
+}
+
+func TestSoftStopAcksTimeout(t *testing.T) {
+	s := NewSignaller()
+	a := NewSoftStopAcks(s, "foo", "bar")
+	s.TriggerSoftStop()
+	a.AckSoftStop("bar")
+
+	err := a.Wait(context.Background(), time.Millisecond*10)
+	var unacked *UnacknowledgedError
+	require.ErrorAs(t, err, &unacked)
+	assert.Equal(t, []string{"foo"}, unacked.Components)
+	assert.EqualError(t, err, "soft stop not acknowledged by: foo")
+	assert.ErrorIs(t, s.StopErr(), err)
+}
+
+func TestSoftStopAcksHardStop(t *testing.T) {
+	s := NewSignaller()
+	a := NewSoftStopAcks(s, "foo")
+	s.TriggerHardStop()
+
+	var unacked *UnacknowledgedError
+	require.ErrorAs(t, a.Wait(context.Background(), 0), &unacked)
+}
+
+func TestSoftStopAcksCancelled(t *testing.T) {
+	s := NewSignaller()
+	a := NewSoftStopAcks(s, "foo")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.ErrorIs(t, a.Wait(ctx, 0), context.Canceled)
+	assert.NoError(t, s.StopErr())
+}
+
+func TestSoftStopAcksNone(t *testing.T) {
+	s := NewSignaller()
+	s.TriggerSoftStop()
+	require.NoError(t, NewSoftStopAcks(s).Wait(context.Background(), 0))
+}
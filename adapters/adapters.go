@@ -0,0 +1,12 @@
+// Package adapters binds common clients, brokers and servers to the tiers of a
+// shutdown.Signaller.
+//
+// Each adapter runs for the lifetime of a signaller, stopping the client
+// gracefully once a soft stop is signalled and forcefully once a hard stop is
+// signalled, and triggers the signaller as having stopped once the client has
+// stopped, which makes them suitable for use with shutdown.Builder components.
+//
+// Adapters depend on the small subset of the API of each client that they use,
+// expressed as an interface, rather than on the client libraries themselves,
+// and so importing this package adds no dependencies to a module.
+package adapters
@@ -0,0 +1,79 @@
+package adapters
+
+import (
+	"errors"
+
+	"github.com/Jeffail/shutdown"
+)
+
+// AMQPChannel is the subset of the API of an *amqp.Channel used by RunAMQP.
+type AMQPChannel interface {
+	Cancel(consumer string, noWait bool) error
+	Close() error
+}
+
+// AMQPConnection is the subset of the API of an *amqp.Connection used by
+// RunAMQP.
+type AMQPConnection interface {
+	Close() error
+}
+
+// AMQPConsumer identifies a consumer by its channel and consumer tag.
+type AMQPConsumer struct {
+	Channel AMQPChannel
+	Tag     string
+}
+
+// RunAMQP blocks until a soft stop is signalled and then cancels each consumer,
+// which stops the broker from sending further deliveries, and waits for the
+// drained channel to be closed, which signals that the deliveries already
+// received have been acknowledged. Once it is closed, or if a hard stop is
+// signalled first, the channels of the consumers are closed, followed by the
+// connection, and any deliveries that remain unacknowledged are returned to
+// their queues by the broker. The signaller is triggered as having stopped
+// once the connection has closed.
+//
+// The client closes the deliveries channel of a consumer once its cancellation
+// has been confirmed, and so the drained channel is typically closed by the
+// worker that ranges over the deliveries once the range ends:
+//
+//	drained := make(chan struct{})
+//	go func() {
+//		defer close(drained)
+//		for d := range deliveries {
+//			process(d)
+//			_ = d.Ack(false)
+//		}
+//	}()
+func RunAMQP(s *shutdown.Signaller, conn AMQPConnection, drained <-chan struct{}, consumers ...AMQPConsumer) error {
+	defer s.TriggerHasStopped()
+
+	<-s.SoftStopChan()
+
+	var errs []error
+	for _, c := range consumers {
+		if err := c.Channel.Cancel(c.Tag, false); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	select {
+	case <-drained:
+	case <-s.HardStopChan():
+	}
+
+	closed := map[AMQPChannel]struct{}{}
+	for _, c := range consumers {
+		if _, exists := closed[c.Channel]; exists {
+			continue
+		}
+		closed[c.Channel] = struct{}{}
+		if err := c.Channel.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if err := conn.Close(); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,97 @@
+package adapters
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Jeffail/shutdown"
+)
+
+type amqpLog struct {
+	mut   sync.Mutex
+	calls []string
+}
+
+func (l *amqpLog) add(call string) {
+	l.mut.Lock()
+	l.calls = append(l.calls, call)
+	l.mut.Unlock()
+}
+
+func (l *amqpLog) get() []string {
+	l.mut.Lock()
+	defer l.mut.Unlock()
+	return append([]string(nil), l.calls...)
+}
+
+type fakeAMQPChannel struct {
+	name string
+	log  *amqpLog
+}
+
+func (c *fakeAMQPChannel) Cancel(consumer string, noWait bool) error {
+	c.log.add("cancel " + consumer)
+	return nil
+}
+
+func (c *fakeAMQPChannel) Close() error {
+	c.log.add("close " + c.name)
+	return nil
+}
+
+type fakeAMQPConnection struct {
+	log *amqpLog
+	err error
+}
+
+func (c *fakeAMQPConnection) Close() error {
+	c.log.add("close connection")
+	return c.err
+}
+
+func TestRunAMQP(t *testing.T) {
+	log := &amqpLog{}
+	ch := &fakeAMQPChannel{name: "channel", log: log}
+	conn := &fakeAMQPConnection{log: log}
+
+	drained := make(chan struct{})
+
+	s := shutdown.NewSignaller()
+	wait := runAdapter(t, func() error {
+		return RunAMQP(s, conn, drained,
+			AMQPConsumer{Channel: ch, Tag: "a"},
+			AMQPConsumer{Channel: ch, Tag: "b"},
+		)
+	})
+
+	s.TriggerSoftStop()
+	time.Sleep(time.Millisecond * 20)
+	assert.Equal(t, []string{"cancel a", "cancel b"}, log.get())
+
+	close(drained)
+	require.NoError(t, wait())
+	assert.Equal(t, []string{"cancel a", "cancel b", "close channel", "close connection"}, log.get())
+	assert.True(t, s.IsHasStoppedSignalled())
+}
+
+func TestRunAMQPHardStop(t *testing.T) {
+	errNope := errors.New("nope")
+	log := &amqpLog{}
+	ch := &fakeAMQPChannel{name: "channel", log: log}
+	conn := &fakeAMQPConnection{log: log, err: errNope}
+
+	s := shutdown.NewSignaller()
+	wait := runAdapter(t, func() error {
+		return RunAMQP(s, conn, make(chan struct{}), AMQPConsumer{Channel: ch, Tag: "a"})
+	})
+
+	s.TriggerSoftStop()
+	s.TriggerHardStop()
+	assert.ErrorIs(t, wait(), errNope)
+	assert.Equal(t, []string{"cancel a", "close channel", "close connection"}, log.get())
+}
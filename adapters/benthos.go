@@ -0,0 +1,52 @@
+package adapters
+
+import (
+	"context"
+
+	"github.com/Jeffail/shutdown"
+)
+
+// BenthosStream is the lifecycle API of a Benthos or Bento stream, or of any
+// component that follows the same conventions.
+type BenthosStream interface {
+	// CloseGracefully stops the stream from consuming new data while allowing
+	// data in flight to finish, without blocking.
+	CloseGracefully()
+
+	// CloseNow stops the stream immediately, without blocking.
+	CloseNow()
+
+	// WaitForClose blocks until the stream has closed or the context is
+	// cancelled.
+	WaitForClose(ctx context.Context) error
+}
+
+// RunBenthosStream maps the lifecycle of a stream onto the tiers of a
+// signaller, where a soft stop closes the stream gracefully, a hard stop closes
+// it immediately, and the signaller is triggered as having stopped once the
+// stream has closed. A stream that closes of its own accord triggers a soft
+// stop.
+func RunBenthosStream(s *shutdown.Signaller, stream BenthosStream) error {
+	defer s.TriggerHasStopped()
+
+	closed := make(chan error, 1)
+	go func() {
+		closed <- stream.WaitForClose(context.Background())
+	}()
+
+	select {
+	case err := <-closed:
+		s.TriggerSoftStop()
+		return err
+	case <-s.SoftStopChan():
+	}
+	stream.CloseGracefully()
+
+	select {
+	case err := <-closed:
+		return err
+	case <-s.HardStopChan():
+	}
+	stream.CloseNow()
+	return <-closed
+}
@@ -0,0 +1,90 @@
+package adapters
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Jeffail/shutdown"
+)
+
+type fakeBenthosStream struct {
+	mut      sync.Mutex
+	calls    []string
+	graceful bool
+	closed   chan struct{}
+	once     sync.Once
+}
+
+func newFakeBenthosStream(graceful bool) *fakeBenthosStream {
+	return &fakeBenthosStream{graceful: graceful, closed: make(chan struct{})}
+}
+
+func (f *fakeBenthosStream) record(call string) {
+	f.mut.Lock()
+	f.calls = append(f.calls, call)
+	f.mut.Unlock()
+}
+
+func (f *fakeBenthosStream) close() {
+	f.once.Do(func() { close(f.closed) })
+}
+
+func (f *fakeBenthosStream) CloseGracefully() {
+	f.record("graceful")
+	if f.graceful {
+		f.close()
+	}
+}
+
+func (f *fakeBenthosStream) CloseNow() {
+	f.record("now")
+	f.close()
+}
+
+func (f *fakeBenthosStream) WaitForClose(ctx context.Context) error {
+	select {
+	case <-f.closed:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func TestRunBenthosStreamGraceful(t *testing.T) {
+	s := shutdown.NewSignaller()
+	stream := newFakeBenthosStream(true)
+
+	wait := runAdapter(t, func() error { return RunBenthosStream(s, stream) })
+	s.TriggerSoftStop()
+
+	require.NoError(t, wait())
+	assert.Equal(t, []string{"graceful"}, stream.calls)
+	assert.True(t, s.IsHasStoppedSignalled())
+}
+
+func TestRunBenthosStreamHardStop(t *testing.T) {
+	s := shutdown.NewSignaller()
+	stream := newFakeBenthosStream(false)
+
+	wait := runAdapter(t, func() error { return RunBenthosStream(s, stream) })
+	s.TriggerSoftStop()
+	s.TriggerHardStop()
+
+	require.NoError(t, wait())
+	assert.Equal(t, []string{"graceful", "now"}, stream.calls)
+	assert.True(t, s.IsHasStoppedSignalled())
+}
+
+func TestRunBenthosStreamClosedEarly(t *testing.T) {
+	s := shutdown.NewSignaller()
+	stream := newFakeBenthosStream(false)
+	stream.close()
+
+	require.NoError(t, RunBenthosStream(s, stream))
+	assert.True(t, s.IsSoftStopSignalled())
+	assert.Empty(t, stream.calls)
+}
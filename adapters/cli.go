@@ -0,0 +1,40 @@
+package adapters
+
+import (
+	"context"
+
+	"github.com/Jeffail/shutdown"
+)
+
+// CLIAction wraps a command function as the Action of a urfave/cli v3 command,
+// which runs it with shutdown.RunContext. The function is provided a context
+// that is cancelled once a soft stop is signalled and that carries the
+// signaller, and the errors and exit code of the run are returned as a
+// *shutdown.ExitError:
+//
+//	cmd := &cli.Command{
+//		Name: "serve",
+//		Action: adapters.CLIAction(func(ctx context.Context, cmd *cli.Command, s *shutdown.Signaller) error {
+//			return serve(ctx, s)
+//		}, shutdown.WithHardStopGrace(20*time.Second)),
+//	}
+//	if err := cmd.Run(context.Background(), os.Args); err != nil {
+//		os.Exit(shutdown.ExitCode(err))
+//	}
+//
+// The actions of urfave/cli v2 carry their context in a field of *cli.Context,
+// which is replaced for the duration of the run instead:
+//
+//	Action: func(c *cli.Context) error {
+//		return shutdown.RunContext(c.Context, func(ctx context.Context, s *shutdown.Signaller) error {
+//			c.Context = ctx
+//			return serve(c, s)
+//		})
+//	},
+func CLIAction[C any](run func(ctx context.Context, cmd C, s *shutdown.Signaller) error, opts ...shutdown.Option) func(ctx context.Context, cmd C) error {
+	return func(ctx context.Context, cmd C) error {
+		return shutdown.RunContext(ctx, func(ctx context.Context, s *shutdown.Signaller) error {
+			return run(ctx, cmd, s)
+		}, opts...)
+	}
+}
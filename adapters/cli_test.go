@@ -0,0 +1,44 @@
+package adapters
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Jeffail/shutdown"
+)
+
+type fakeCLICommand struct {
+	name string
+}
+
+func TestCLIAction(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	action := CLIAction(func(ctx context.Context, cmd *fakeCLICommand, s *shutdown.Signaller) error {
+		assert.Equal(t, "serve", cmd.name)
+		assert.Equal(t, s, shutdown.SignallerFromContext(ctx))
+
+		// Cancelling the context of the command soft stops the signaller.
+		cancel()
+		select {
+		case <-s.SoftStopChan():
+		case <-time.After(time.Second):
+			t.Error("timed out waiting for soft stop")
+		}
+		return nil
+	})
+	require.NoError(t, action(ctx, &fakeCLICommand{name: "serve"}))
+}
+
+func TestCLIActionExitCode(t *testing.T) {
+	action := CLIAction(func(ctx context.Context, cmd *fakeCLICommand, s *shutdown.Signaller) error {
+		s.TriggerHardStop()
+		return nil
+	}, shutdown.WithExitCodes(shutdown.ExitCodes{HardStopped: 2}))
+	assert.Equal(t, 2, shutdown.ExitCode(action(context.Background(), &fakeCLICommand{})))
+}
@@ -0,0 +1,43 @@
+package adapters
+
+import (
+	"context"
+
+	"github.com/Jeffail/shutdown"
+)
+
+// CobraCommand is the subset of the API of a *cobra.Command used by CobraRunE.
+type CobraCommand interface {
+	Context() context.Context
+	SetContext(ctx context.Context)
+}
+
+// CobraRunE wraps a command function as the RunE of a cobra command, which
+// runs it with shutdown.RunContext. The context of the command is replaced for
+// the duration of the run with a context that is cancelled once a soft stop is
+// signalled and that carries the signaller, and the errors and exit code of
+// the run are returned as a *shutdown.ExitError:
+//
+//	cmd := &cobra.Command{
+//		Use: "serve",
+//		RunE: adapters.CobraRunE(func(cmd *cobra.Command, args []string, s *shutdown.Signaller) error {
+//			return serve(cmd.Context(), s)
+//		}, shutdown.WithHardStopGrace(20*time.Second)),
+//	}
+//	if err := cmd.Execute(); err != nil {
+//		os.Exit(shutdown.ExitCode(err))
+//	}
+func CobraRunE[C CobraCommand](run func(cmd C, args []string, s *shutdown.Signaller) error, opts ...shutdown.Option) func(cmd C, args []string) error {
+	return func(cmd C, args []string) error {
+		parent := cmd.Context()
+		if parent == nil {
+			parent = context.Background()
+		}
+		defer cmd.SetContext(parent)
+
+		return shutdown.RunContext(parent, func(ctx context.Context, s *shutdown.Signaller) error {
+			cmd.SetContext(ctx)
+			return run(cmd, args, s)
+		}, opts...)
+	}
+}
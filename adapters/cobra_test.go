@@ -0,0 +1,49 @@
+package adapters
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Jeffail/shutdown"
+)
+
+type fakeCobraCommand struct {
+	ctx context.Context
+}
+
+func (c *fakeCobraCommand) Context() context.Context {
+	return c.ctx
+}
+
+func (c *fakeCobraCommand) SetContext(ctx context.Context) {
+	c.ctx = ctx
+}
+
+func TestCobraRunE(t *testing.T) {
+	cmd := &fakeCobraCommand{}
+	runE := CobraRunE(func(cmd *fakeCobraCommand, args []string, s *shutdown.Signaller) error {
+		assert.Equal(t, []string{"a"}, args)
+		assert.Equal(t, s, shutdown.SignallerFromContext(cmd.Context()))
+
+		s.TriggerSoftStop()
+		<-cmd.Context().Done()
+		return nil
+	})
+	require.NoError(t, runE(cmd, []string{"a"}))
+	assert.Equal(t, context.Background(), cmd.Context())
+}
+
+func TestCobraRunEExitCode(t *testing.T) {
+	errFailed := errors.New("failed")
+	runE := CobraRunE(func(cmd *fakeCobraCommand, args []string, s *shutdown.Signaller) error {
+		return errFailed
+	}, shutdown.WithExitCodes(shutdown.ExitCodes{Failed: 4}))
+
+	err := runE(&fakeCobraCommand{ctx: context.Background()}, nil)
+	assert.ErrorIs(t, err, errFailed)
+	assert.Equal(t, 4, shutdown.ExitCode(err))
+}
@@ -0,0 +1,125 @@
+package adapters
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/Jeffail/shutdown"
+)
+
+// CronScheduler is the subset of the API of a job scheduler, such as a
+// *cron.Cron of robfig/cron, used by Cron.
+type CronScheduler interface {
+	Stop() context.Context
+}
+
+// CronInterruptedError is recorded against the signaller of a Cron when a hard
+// stop is signalled while jobs are still running, and names those jobs.
+type CronInterruptedError struct {
+	Jobs []string
+}
+
+// Error returns a description of the interrupted jobs.
+func (e *CronInterruptedError) Error() string {
+	return fmt.Sprintf("cron jobs interrupted by hard stop: %v", strings.Join(e.Jobs, ", "))
+}
+
+// Unwrap returns shutdown.ErrHardStop.
+func (e *CronInterruptedError) Unwrap() error {
+	return shutdown.ErrHardStop
+}
+
+// Cron binds a job scheduler to a signaller. Jobs wrapped with Job are run
+// with a context that is cancelled once a hard stop is signalled, and are
+// skipped if they are scheduled after a soft stop.
+type Cron[C CronScheduler] struct {
+	s     *shutdown.Signaller
+	sched C
+	gate  *shutdown.Gate
+
+	mut     sync.Mutex
+	nextID  uint64
+	running map[uint64]string
+}
+
+// NewCron binds a job scheduler to a signaller.
+func NewCron[C CronScheduler](s *shutdown.Signaller, sched C) *Cron[C] {
+	return &Cron[C]{
+		s:       s,
+		sched:   sched,
+		gate:    shutdown.NewGate(s),
+		running: map[uint64]string{},
+	}
+}
+
+// Job wraps a named job as a function that can be registered with the
+// scheduler:
+//
+//	jobs := adapters.NewCron(s, c)
+//	c.AddFunc("@every 1m", jobs.Job("reindex", reindex))
+func (c *Cron[C]) Job(name string, fn func(ctx context.Context) error) func() {
+	return func() {
+		_ = c.gate.Do(context.Background(), func(ctx context.Context) error {
+			c.mut.Lock()
+			id := c.nextID
+			c.nextID++
+			c.running[id] = name
+			c.mut.Unlock()
+
+			defer func() {
+				c.mut.Lock()
+				delete(c.running, id)
+				c.mut.Unlock()
+			}()
+			return fn(ctx)
+		})
+	}
+}
+
+// Running returns the names of the jobs that are currently running, sorted.
+func (c *Cron[C]) Running() []string {
+	c.mut.Lock()
+	names := make([]string, 0, len(c.running))
+	for _, name := range c.running {
+		names = append(names, name)
+	}
+	c.mut.Unlock()
+
+	sort.Strings(names)
+	return names
+}
+
+// Run blocks until a soft stop is signalled, stops the scheduler from starting
+// new runs, and waits for the running jobs to finish or for a hard stop to be
+// signalled, whichever comes first. Jobs that are still running when the hard
+// stop is signalled have their contexts cancelled and are recorded against the
+// signaller as a *CronInterruptedError, which is also returned. The signaller
+// is triggered as having stopped once every job has returned, including those
+// that were interrupted.
+func (c *Cron[C]) Run() error {
+	defer c.s.TriggerHasStopped()
+
+	<-c.s.SoftStopChan()
+	c.sched.Stop()
+
+	ctx, done := c.s.HardStopCtx(context.Background())
+	defer done()
+
+	if c.gate.Wait(ctx) == nil {
+		return nil
+	}
+	jobs := c.Running()
+
+	// The contexts of the running jobs are cancelled by the hard stop, but
+	// they have not stopped until they return.
+	_ = c.gate.Wait(context.Background())
+	if len(jobs) > 0 {
+		err := &CronInterruptedError{Jobs: jobs}
+		c.s.RecordStopErr(err)
+		return err
+	}
+	return nil
+}
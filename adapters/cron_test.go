@@ -0,0 +1,85 @@
+package adapters
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Jeffail/shutdown"
+)
+
+type fakeCronScheduler struct {
+	stopped atomic.Bool
+}
+
+func (c *fakeCronScheduler) Stop() context.Context {
+	c.stopped.Store(true)
+	return context.Background()
+}
+
+func TestCron(t *testing.T) {
+	s := shutdown.NewSignaller()
+	sched := &fakeCronScheduler{}
+	c := NewCron(s, sched)
+
+	wait := runAdapter(t, c.Run)
+
+	started, release := make(chan struct{}), make(chan struct{})
+	job := c.Job("reindex", func(ctx context.Context) error {
+		close(started)
+		<-release
+		return nil
+	})
+	go job()
+	<-started
+	assert.Equal(t, []string{"reindex"}, c.Running())
+
+	s.TriggerSoftStop()
+	c.Job("late", func(ctx context.Context) error {
+		t.Error("job run after soft stop")
+		return nil
+	})()
+
+	close(release)
+	require.NoError(t, wait())
+	assert.True(t, sched.stopped.Load())
+	assert.Empty(t, c.Running())
+	assert.NoError(t, s.StopErr())
+	assert.True(t, s.IsHasStoppedSignalled())
+}
+
+func TestCronHardStop(t *testing.T) {
+	s := shutdown.NewSignaller()
+	c := NewCron(s, &fakeCronScheduler{})
+
+	wait := runAdapter(t, c.Run)
+
+	started, release := make(chan struct{}), make(chan struct{})
+	go c.Job("backup", func(ctx context.Context) error {
+		close(started)
+		<-ctx.Done()
+		<-release
+		return ctx.Err()
+	})()
+	<-started
+
+	s.TriggerHardStop()
+
+	// Interrupted jobs are waited for.
+	time.Sleep(time.Millisecond * 10)
+	assert.False(t, s.IsHasStoppedSignalled())
+
+	close(release)
+	err := wait()
+
+	var interrupted *CronInterruptedError
+	require.ErrorAs(t, err, &interrupted)
+	assert.Equal(t, []string{"backup"}, interrupted.Jobs)
+	assert.ErrorIs(t, err, shutdown.ErrHardStop)
+	assert.ErrorAs(t, s.StopErr(), &interrupted)
+	assert.True(t, s.IsHasStoppedSignalled())
+}
@@ -0,0 +1,48 @@
+package adapters
+
+import (
+	"context"
+	"net"
+
+	"github.com/Jeffail/shutdown"
+)
+
+// FastHTTPServer is the subset of the API of a *fasthttp.Server used by
+// ServeFastHTTP.
+type FastHTTPServer interface {
+	Serve(ln net.Listener) error
+	ShutdownWithContext(ctx context.Context) error
+}
+
+// ServeFastHTTP runs a fasthttp server on the provided listener for the
+// lifetime of a signaller, mirroring shutdown.ServeHTTP. Once a soft stop is
+// signalled the server is shut down gracefully with a context that is
+// cancelled once a hard stop is signalled, at which point the server stops
+// waiting for open connections. The signaller is triggered as having stopped
+// once the server has stopped.
+//
+// The error of the server is returned if it fails before a soft stop, in which
+// case a soft stop is also triggered.
+func ServeFastHTTP(s *shutdown.Signaller, srv FastHTTPServer, ln net.Listener) error {
+	defer s.TriggerHasStopped()
+
+	errC := make(chan error, 1)
+	go func() {
+		errC <- srv.Serve(ln)
+	}()
+
+	select {
+	case err := <-errC:
+		s.TriggerSoftStop()
+		return err
+	case <-s.SoftStopChan():
+	}
+
+	ctx, done := s.HardStopCtx(context.Background())
+	defer done()
+
+	_ = srv.ShutdownWithContext(ctx)
+	_ = ln.Close()
+	<-errC
+	return nil
+}
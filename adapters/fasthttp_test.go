@@ -0,0 +1,81 @@
+package adapters
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Jeffail/shutdown"
+)
+
+// fakeFastHTTPServer accepts connections until shut down, and waits for the
+// shutdown context when hanging is set.
+type fakeFastHTTPServer struct {
+	ln       net.Listener
+	hanging  bool
+	shutdown chan error
+}
+
+func (f *fakeFastHTTPServer) Serve(ln net.Listener) error {
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			return nil
+		}
+		conn.Close()
+	}
+}
+
+func (f *fakeFastHTTPServer) ShutdownWithContext(ctx context.Context) error {
+	f.ln.Close()
+	if f.hanging {
+		<-ctx.Done()
+	}
+	f.shutdown <- ctx.Err()
+	return ctx.Err()
+}
+
+func TestServeFastHTTP(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	s := shutdown.NewSignaller()
+	srv := &fakeFastHTTPServer{ln: ln, shutdown: make(chan error, 1)}
+	wait := runAdapter(t, func() error { return ServeFastHTTP(s, srv, ln) })
+
+	s.TriggerSoftStop()
+	require.NoError(t, wait())
+	assert.NoError(t, <-srv.shutdown)
+	assert.True(t, s.IsHasStoppedSignalled())
+}
+
+func TestServeFastHTTPHardStop(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	s := shutdown.NewSignaller()
+	srv := &fakeFastHTTPServer{ln: ln, hanging: true, shutdown: make(chan error, 1)}
+	wait := runAdapter(t, func() error { return ServeFastHTTP(s, srv, ln) })
+
+	s.TriggerSoftStop()
+	s.TriggerHardStop()
+	require.NoError(t, wait())
+	assert.ErrorIs(t, <-srv.shutdown, context.Canceled)
+}
+
+type failingFastHTTPServer struct{ err error }
+
+func (f failingFastHTTPServer) Serve(ln net.Listener) error               { return f.err }
+func (f failingFastHTTPServer) ShutdownWithContext(context.Context) error { return nil }
+
+func TestServeFastHTTPFailure(t *testing.T) {
+	errNope := errors.New("nope")
+	s := shutdown.NewSignaller()
+	assert.ErrorIs(t, ServeFastHTTP(s, failingFastHTTPServer{errNope}, nil), errNope)
+	assert.True(t, s.IsSoftStopSignalled())
+	assert.True(t, s.IsHasStoppedSignalled())
+}
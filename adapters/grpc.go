@@ -0,0 +1,47 @@
+package adapters
+
+import (
+	"context"
+
+	"github.com/Jeffail/shutdown"
+)
+
+// GRPCClientConn is the subset of the API of a *grpc.ClientConn used by
+// CloseGRPCClientConn.
+type GRPCClientConn interface {
+	Close() error
+}
+
+// CloseGRPCClientConn blocks until a soft stop is signalled, waits for the RPCs
+// admitted by the gate to finish, or for a hard stop to be signalled, and then
+// closes the connection. The signaller is triggered as having stopped once the
+// connection has closed.
+//
+// RPCs are admitted by calling them through the gate from client interceptors,
+// which causes new RPCs and streams to be rejected with shutdown.ErrGateClosed
+// once a soft stop has been signalled:
+//
+//	gate := shutdown.NewGate(s)
+//	conn, err := grpc.NewClient(target,
+//		grpc.WithUnaryInterceptor(func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+//			return gate.Do(ctx, func(ctx context.Context) error {
+//				return invoker(ctx, method, req, reply, cc, opts...)
+//			})
+//		}),
+//	)
+//
+// Streams should call Enter on the gate when they are created and Exit once
+// they have ended.
+func CloseGRPCClientConn(s *shutdown.Signaller, conn GRPCClientConn, gate *shutdown.Gate) error {
+	defer s.TriggerHasStopped()
+
+	<-s.SoftStopChan()
+
+	ctx, done := s.HardStopCtx(context.Background())
+	defer done()
+
+	if gate != nil {
+		_ = gate.Wait(ctx)
+	}
+	return conn.Close()
+}
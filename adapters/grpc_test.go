@@ -0,0 +1,55 @@
+package adapters
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Jeffail/shutdown"
+)
+
+type fakeGRPCClientConn struct {
+	closed atomic.Bool
+}
+
+func (c *fakeGRPCClientConn) Close() error {
+	c.closed.Store(true)
+	return nil
+}
+
+func TestCloseGRPCClientConn(t *testing.T) {
+	s := shutdown.NewSignaller()
+	gate := shutdown.NewGate(s)
+	conn := &fakeGRPCClientConn{}
+
+	wait := runAdapter(t, func() error { return CloseGRPCClientConn(s, conn, gate) })
+
+	require.NoError(t, gate.Enter())
+	s.TriggerSoftStop()
+	assert.ErrorIs(t, gate.Do(context.Background(), func(ctx context.Context) error {
+		return nil
+	}), shutdown.ErrGateClosed)
+
+	time.Sleep(time.Millisecond * 10)
+	assert.False(t, conn.closed.Load())
+
+	gate.Exit()
+	require.NoError(t, wait())
+	assert.True(t, conn.closed.Load())
+	assert.True(t, s.IsHasStoppedSignalled())
+}
+
+func TestCloseGRPCClientConnHardStop(t *testing.T) {
+	s := shutdown.NewSignaller()
+	gate := shutdown.NewGate(s)
+	conn := &fakeGRPCClientConn{}
+	require.NoError(t, gate.Enter())
+
+	s.TriggerHardStop()
+	require.NoError(t, CloseGRPCClientConn(s, conn, gate))
+	assert.True(t, conn.closed.Load())
+}
@@ -0,0 +1,68 @@
+package adapters
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Jeffail/shutdown"
+)
+
+// KafkaConsumer is a member of a Kafka consumer group that polls for batches of
+// records of type B, expressed in terms that are satisfied by a thin wrapper
+// around a franz-go client or a sarama consumer group.
+type KafkaConsumer[B any] interface {
+	// Poll blocks until a batch of records is available, returning early with
+	// the error of the context if it is cancelled.
+	Poll(ctx context.Context) (B, error)
+
+	// Commit commits the offsets of the records that have been processed.
+	Commit(ctx context.Context) error
+
+	// Close leaves the consumer group and closes the consumer.
+	Close() error
+}
+
+// RunKafkaConsumer polls a consumer and processes each batch with the handler
+// until a soft stop is signalled. Once it is, polling stops, the batch being
+// processed is allowed to finish, the offsets of processed records are
+// committed and the consumer leaves its group cleanly, which allows the group
+// to rebalance once rather than waiting for the member to time out.
+//
+// The handler is provided a context that is cancelled when a hard stop is
+// signalled, in which case it should abandon the batch. Offsets are committed
+// with the same context, and so a hard stop also aborts the commit. The
+// signaller is triggered as having stopped once the consumer has closed.
+//
+// An error returned by Poll, other than that of a soft stop, or by the handler
+// stops the consumer without committing and is returned.
+func RunKafkaConsumer[B any](s *shutdown.Signaller, c KafkaConsumer[B], handle func(ctx context.Context, batch B) error) error {
+	defer s.TriggerHasStopped()
+
+	pollCtx, pollDone := s.SoftStopCtx(context.Background())
+	defer pollDone()
+
+	hardCtx, hardDone := s.HardStopCtx(context.Background())
+	defer hardDone()
+
+	for {
+		batch, err := c.Poll(pollCtx)
+		if err != nil {
+			if pollCtx.Err() != nil {
+				break
+			}
+			return errors.Join(err, c.Close())
+		}
+		if err := handle(hardCtx, batch); err != nil {
+			return errors.Join(err, c.Close())
+		}
+		if pollCtx.Err() != nil {
+			break
+		}
+	}
+
+	var err error
+	if hardCtx.Err() == nil {
+		err = c.Commit(hardCtx)
+	}
+	return errors.Join(err, c.Close())
+}
@@ -0,0 +1,101 @@
+package adapters
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Jeffail/shutdown"
+)
+
+type fakeKafkaConsumer struct {
+	batches   chan int
+	pollErr   error
+	committed []int
+	processed []int
+	closed    bool
+}
+
+func (c *fakeKafkaConsumer) Poll(ctx context.Context) (int, error) {
+	if c.pollErr != nil {
+		return 0, c.pollErr
+	}
+	select {
+	case b := <-c.batches:
+		return b, nil
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	}
+}
+
+func (c *fakeKafkaConsumer) Commit(ctx context.Context) error {
+	c.committed = append(c.committed[:0], c.processed...)
+	return nil
+}
+
+func (c *fakeKafkaConsumer) Close() error {
+	c.closed = true
+	return nil
+}
+
+func TestRunKafkaConsumer(t *testing.T) {
+	s := shutdown.NewSignaller()
+	c := &fakeKafkaConsumer{batches: make(chan int)}
+
+	wait := runAdapter(t, func() error {
+		return RunKafkaConsumer[int](s, c, func(ctx context.Context, batch int) error {
+			c.processed = append(c.processed, batch)
+			if batch == 2 {
+				// A soft stop during processing allows the batch to finish.
+				s.TriggerSoftStop()
+			}
+			return nil
+		})
+	})
+
+	c.batches <- 1
+	c.batches <- 2
+
+	require.NoError(t, wait())
+	assert.Equal(t, []int{1, 2}, c.processed)
+	assert.Equal(t, []int{1, 2}, c.committed)
+	assert.True(t, c.closed)
+	assert.True(t, s.IsHasStoppedSignalled())
+}
+
+func TestRunKafkaConsumerHardStop(t *testing.T) {
+	s := shutdown.NewSignaller()
+	c := &fakeKafkaConsumer{batches: make(chan int, 1)}
+	c.batches <- 1
+
+	err := RunKafkaConsumer[int](s, c, func(ctx context.Context, batch int) error {
+		s.TriggerHardStop()
+		<-ctx.Done()
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Empty(t, c.committed)
+	assert.True(t, c.closed)
+}
+
+func TestRunKafkaConsumerErrors(t *testing.T) {
+	errNope := errors.New("nope")
+
+	s := shutdown.NewSignaller()
+	c := &fakeKafkaConsumer{pollErr: errNope}
+	assert.ErrorIs(t, RunKafkaConsumer[int](s, c, nil), errNope)
+	assert.True(t, c.closed)
+	assert.True(t, s.IsHasStoppedSignalled())
+
+	s = shutdown.NewSignaller()
+	c = &fakeKafkaConsumer{batches: make(chan int, 1)}
+	c.batches <- 1
+	assert.ErrorIs(t, RunKafkaConsumer[int](s, c, func(ctx context.Context, batch int) error {
+		return errNope
+	}), errNope)
+	assert.Empty(t, c.committed)
+	assert.True(t, c.closed)
+}
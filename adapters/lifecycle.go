@@ -0,0 +1,149 @@
+package adapters
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Jeffail/shutdown"
+)
+
+// LifecycleService implements the Lifecycle gRPC service defined by
+// lifecycle.proto in terms of a signaller, independently of the generated
+// code, which streams the lifecycle transitions of the signaller to watchers
+// and accepts remote requests to stop. The methods of the generated server
+// interface are thin wrappers around Watch and Stop:
+//
+//	func (s *server) Watch(_ *pb.WatchRequest, stream pb.Lifecycle_WatchServer) error {
+//		return s.svc.Watch(stream.Context(), func(e shutdown.Event) error {
+//			return stream.Send(&pb.Transition{Kind: pb.Kind(e.Kind), UnixNano: unixNano(e.Time)})
+//		})
+//	}
+//
+//	func (s *server) Stop(ctx context.Context, req *pb.StopRequest) (*pb.StopResponse, error) {
+//		return &pb.StopResponse{}, s.svc.Stop(ctx, req.Hard)
+//	}
+type LifecycleService struct {
+	s         *shutdown.Signaller
+	authorize func(ctx context.Context, hard bool) error
+}
+
+// NewLifecycleService creates a lifecycle service for a signaller. Each
+// request to stop is first passed to the authorize function, which typically
+// inspects the peer or credentials of the context, and is rejected with its
+// error if it returns one. A nil authorize function rejects every request to
+// stop, leaving the service to observation only.
+func NewLifecycleService(s *shutdown.Signaller, authorize func(ctx context.Context, hard bool) error) *LifecycleService {
+	if authorize == nil {
+		authorize = func(context.Context, bool) error {
+			return ErrStopUnauthorized
+		}
+	}
+	return &LifecycleService{s: s, authorize: authorize}
+}
+
+// ErrStopUnauthorized is returned by LifecycleService.Stop when the service was
+// created without an authorize function.
+var ErrStopUnauthorized = errors.New("remote stop requests are not authorized")
+
+// Watch sends the lifecycle transitions of the signaller until it has stopped,
+// the context is cancelled or send returns an error, which is returned.
+// Transitions made before the call are sent first with a zero time, and so
+// watchers that join late still observe the current state of the signaller.
+// Each tier is sent once, whereas reloads are sent every time they occur.
+func (l *LifecycleService) Watch(ctx context.Context, send func(e shutdown.Event) error) error {
+	events, cancel := l.s.Subscribe(shutdown.WithDelivery(shutdown.DeliverBlocking))
+	defer cancel()
+
+	sent := map[shutdown.EventKind]bool{}
+	sendOnce := func(e shutdown.Event) error {
+		if e.Kind == shutdown.EventSoftStopAborted {
+			delete(sent, shutdown.EventSoftStop)
+		} else if sent[e.Kind] && e.Kind != shutdown.EventReload {
+			return nil
+		}
+		sent[e.Kind] = true
+		return send(e)
+	}
+
+	for _, past := range []struct {
+		kind shutdown.EventKind
+		made bool
+	}{
+		{shutdown.EventSoftStop, l.s.IsSoftStopSignalled()},
+		{shutdown.EventHardStop, l.s.IsHardStopSignalled()},
+		{shutdown.EventHasStopped, l.s.IsHasStoppedSignalled()},
+	} {
+		if !past.made {
+			continue
+		}
+		if err := sendOnce(shutdown.Event{Kind: past.kind}); err != nil {
+			return err
+		}
+	}
+	if sent[shutdown.EventHasStopped] {
+		return nil
+	}
+
+	for {
+		select {
+		case e := <-events:
+			if err := sendOnce(e); err != nil {
+				return err
+			}
+			if e.Kind == shutdown.EventHasStopped {
+				return nil
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// Stop triggers a soft or hard stop of the signaller once the request has been
+// authorized.
+func (l *LifecycleService) Stop(ctx context.Context, hard bool) error {
+	if err := l.authorize(ctx, hard); err != nil {
+		return err
+	}
+	if hard {
+		l.s.TriggerHardStopFrom(shutdown.SourceAdminAPI)
+	} else {
+		l.s.TriggerSoftStopFrom(shutdown.SourceAdminAPI)
+	}
+	return nil
+}
+
+// MirrorLifecycle receives the lifecycle transitions of a remote process
+// streamed by the Watch method of its Lifecycle service, and triggers the same
+// tiers of a local signaller, until the remote process has stopped or recv
+// returns an error, which is returned. This allows sidecars to drain in step
+// with the process they accompany:
+//
+//	stream, err := client.Watch(ctx, &pb.WatchRequest{})
+//	...
+//	err = adapters.MirrorLifecycle(s, func() (shutdown.EventKind, error) {
+//		t, err := stream.Recv()
+//		if err != nil {
+//			return 0, err
+//		}
+//		return shutdown.EventKind(t.Kind), nil
+//	})
+//
+// The has stopped tier of the local signaller is not triggered, as the local
+// component must still report its own stop.
+func MirrorLifecycle(s *shutdown.Signaller, recv func() (shutdown.EventKind, error)) error {
+	for {
+		kind, err := recv()
+		if err != nil {
+			return err
+		}
+		switch kind {
+		case shutdown.EventSoftStop:
+			s.TriggerSoftStopFrom(shutdown.SourceParentContext)
+		case shutdown.EventHardStop:
+			s.TriggerHardStopFrom(shutdown.SourceParentContext)
+		case shutdown.EventHasStopped:
+			return nil
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package adapters
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Jeffail/shutdown"
+)
+
+func TestLifecycleServiceWatch(t *testing.T) {
+	s := shutdown.NewSignaller()
+	svc := NewLifecycleService(s, nil)
+
+	s.TriggerSoftStop()
+
+	kinds := make(chan shutdown.EventKind, 3)
+	wait := runAdapter(t, func() error {
+		return svc.Watch(context.Background(), func(e shutdown.Event) error {
+			kinds <- e.Kind
+			return nil
+		})
+	})
+	assert.Equal(t, shutdown.EventSoftStop, <-kinds)
+
+	s.TriggerReload()
+	assert.Equal(t, shutdown.EventReload, <-kinds)
+	s.TriggerReload()
+	assert.Equal(t, shutdown.EventReload, <-kinds)
+
+	s.TriggerHardStop()
+	assert.Equal(t, shutdown.EventHardStop, <-kinds)
+
+	s.TriggerHasStopped()
+	assert.Equal(t, shutdown.EventHasStopped, <-kinds)
+	require.NoError(t, wait())
+}
+
+func TestLifecycleServiceWatchCancelled(t *testing.T) {
+	svc := NewLifecycleService(shutdown.NewSignaller(), nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*10)
+	defer cancel()
+	assert.ErrorIs(t, svc.Watch(ctx, func(e shutdown.Event) error {
+		t.Errorf("unexpected event: %v", e.Kind)
+		return nil
+	}), context.DeadlineExceeded)
+}
+
+func TestLifecycleServiceStop(t *testing.T) {
+	s := shutdown.NewSignaller()
+	assert.ErrorIs(t, NewLifecycleService(s, nil).Stop(context.Background(), false), ErrStopUnauthorized)
+	assert.False(t, s.IsSoftStopSignalled())
+
+	errDenied := errors.New("hard stops are not allowed")
+	svc := NewLifecycleService(s, func(ctx context.Context, hard bool) error {
+		if hard {
+			return errDenied
+		}
+		return nil
+	})
+	assert.ErrorIs(t, svc.Stop(context.Background(), true), errDenied)
+	assert.False(t, s.IsSoftStopSignalled())
+
+	require.NoError(t, svc.Stop(context.Background(), false))
+	assert.True(t, s.IsSoftStopSignalled())
+	assert.False(t, s.IsHardStopSignalled())
+}
+
+func TestMirrorLifecycle(t *testing.T) {
+	s := shutdown.NewSignaller()
+	kinds := []shutdown.EventKind{shutdown.EventSoftStop, shutdown.EventHardStop, shutdown.EventHasStopped}
+	require.NoError(t, MirrorLifecycle(s, func() (shutdown.EventKind, error) {
+		k := kinds[0]
+		kinds = kinds[1:]
+		return k, nil
+	}))
+	assert.True(t, s.IsHardStopSignalled())
+	assert.False(t, s.IsHasStoppedSignalled())
+
+	assert.ErrorIs(t, MirrorLifecycle(s, func() (shutdown.EventKind, error) {
+		return 0, io.EOF
+	}), io.EOF)
+}
@@ -0,0 +1,66 @@
+package adapters
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Jeffail/shutdown"
+)
+
+// LongPollConsumer is a consumer of a queue that long-polls for messages of
+// type M, such as Amazon SQS, where received messages are hidden from other
+// consumers until either they are deleted or their visibility timeout expires.
+type LongPollConsumer[M any] interface {
+	// Receive long-polls for messages, returning early with the error of the
+	// context if it is cancelled.
+	Receive(ctx context.Context) ([]M, error)
+
+	// Delete removes a processed message from the queue.
+	Delete(ctx context.Context, msg M) error
+}
+
+// RunLongPoll receives messages from a consumer and processes each of them with
+// the handler, deleting those that are processed successfully, until a soft
+// stop is signalled. The soft stop cancels any outstanding receive, after which
+// the messages already received are processed and deleted before returning.
+//
+// The handler, and deletions, are provided a context that is cancelled once a
+// hard stop is signalled, at which point any messages that have not been
+// processed are abandoned and become visible to other consumers once their
+// visibility timeout expires. Messages that fail to process are not deleted,
+// and are therefore redelivered once their visibility timeout expires. The
+// signaller is triggered as having stopped once the consumer has stopped.
+//
+// An error returned by Receive, other than that of a soft stop, stops the
+// consumer and is returned, along with any errors from deletions.
+func RunLongPoll[M any](s *shutdown.Signaller, c LongPollConsumer[M], handle func(ctx context.Context, msg M) error) error {
+	defer s.TriggerHasStopped()
+
+	receiveCtx, receiveDone := s.SoftStopCtx(context.Background())
+	defer receiveDone()
+
+	hardCtx, hardDone := s.HardStopCtx(context.Background())
+	defer hardDone()
+
+	var errs []error
+	for receiveCtx.Err() == nil {
+		msgs, err := c.Receive(receiveCtx)
+		if err != nil && receiveCtx.Err() == nil {
+			errs = append(errs, err)
+			break
+		}
+		for _, msg := range msgs {
+			if hardCtx.Err() != nil {
+				// Abandon the remaining messages.
+				break
+			}
+			if err := handle(hardCtx, msg); err != nil {
+				continue
+			}
+			if err := c.Delete(hardCtx, msg); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,88 @@
+package adapters
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Jeffail/shutdown"
+)
+
+type fakeLongPollConsumer struct {
+	batches    chan []string
+	receiveErr error
+	deleted    []string
+}
+
+func (c *fakeLongPollConsumer) Receive(ctx context.Context) ([]string, error) {
+	if c.receiveErr != nil {
+		return nil, c.receiveErr
+	}
+	select {
+	case b := <-c.batches:
+		return b, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (c *fakeLongPollConsumer) Delete(ctx context.Context, msg string) error {
+	c.deleted = append(c.deleted, msg)
+	return nil
+}
+
+func TestRunLongPoll(t *testing.T) {
+	s := shutdown.NewSignaller()
+	c := &fakeLongPollConsumer{batches: make(chan []string)}
+
+	var processed []string
+	wait := runAdapter(t, func() error {
+		return RunLongPoll[string](s, c, func(ctx context.Context, msg string) error {
+			processed = append(processed, msg)
+			switch msg {
+			case "b":
+				s.TriggerSoftStop()
+			case "c":
+				return errors.New("nope")
+			}
+			return nil
+		})
+	})
+
+	c.batches <- []string{"a", "b", "c", "d"}
+
+	require.NoError(t, wait())
+	assert.Equal(t, []string{"a", "b", "c", "d"}, processed)
+	assert.Equal(t, []string{"a", "b", "d"}, c.deleted)
+	assert.True(t, s.IsHasStoppedSignalled())
+}
+
+func TestRunLongPollHardStop(t *testing.T) {
+	s := shutdown.NewSignaller()
+	c := &fakeLongPollConsumer{batches: make(chan []string, 1)}
+	c.batches <- []string{"a", "b", "c"}
+
+	var processed []string
+	err := RunLongPoll[string](s, c, func(ctx context.Context, msg string) error {
+		processed = append(processed, msg)
+		if msg == "a" {
+			s.TriggerHardStop()
+		}
+		return ctx.Err()
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a"}, processed)
+	assert.Empty(t, c.deleted)
+}
+
+func TestRunLongPollReceiveError(t *testing.T) {
+	errNope := errors.New("nope")
+	s := shutdown.NewSignaller()
+	c := &fakeLongPollConsumer{receiveErr: errNope}
+
+	assert.ErrorIs(t, RunLongPoll[string](s, c, nil), errNope)
+	assert.True(t, s.IsHasStoppedSignalled())
+}
@@ -0,0 +1,38 @@
+package adapters
+
+import (
+	"context"
+
+	"github.com/Jeffail/shutdown"
+)
+
+// MongoClient is the subset of the API of a *mongo.Client used by
+// DisconnectMongo.
+type MongoClient interface {
+	Disconnect(ctx context.Context) error
+}
+
+// DisconnectMongo blocks until a soft stop is signalled, waits for the
+// operations admitted by the gate to finish, when a gate is provided, and then
+// disconnects the client. Both the wait and the disconnect are bounded by a
+// context that is cancelled once a hard stop is signalled, at which point the
+// driver closes any connections that remain in use.
+//
+// An error from the disconnect is recorded against the signaller, so that it is
+// reported by StopErr, before being returned. The signaller is triggered as
+// having stopped once the client has disconnected.
+func DisconnectMongo(s *shutdown.Signaller, client MongoClient, gate *shutdown.Gate) error {
+	defer s.TriggerHasStopped()
+
+	<-s.SoftStopChan()
+
+	ctx, done := s.HardStopCtx(context.Background())
+	defer done()
+
+	if gate != nil {
+		_ = gate.Wait(ctx)
+	}
+	err := client.Disconnect(ctx)
+	s.RecordStopErr(err)
+	return err
+}
@@ -0,0 +1,56 @@
+package adapters
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Jeffail/shutdown"
+)
+
+type fakeMongoClient struct {
+	err         error
+	disconnects chan error
+}
+
+func (c *fakeMongoClient) Disconnect(ctx context.Context) error {
+	c.disconnects <- ctx.Err()
+	return c.err
+}
+
+func TestDisconnectMongo(t *testing.T) {
+	s := shutdown.NewSignaller()
+	gate := shutdown.NewGate(s)
+	client := &fakeMongoClient{disconnects: make(chan error, 1)}
+
+	require.NoError(t, gate.Enter())
+	wait := runAdapter(t, func() error { return DisconnectMongo(s, client, gate) })
+
+	s.TriggerSoftStop()
+	select {
+	case <-client.disconnects:
+		t.Fatal("disconnected with operations in flight")
+	default:
+	}
+
+	gate.Exit()
+	require.NoError(t, wait())
+
+	assert.NoError(t, <-client.disconnects)
+	assert.True(t, s.IsHasStoppedSignalled())
+}
+
+func TestDisconnectMongoError(t *testing.T) {
+	errNope := errors.New("nope")
+	s := shutdown.NewSignaller()
+	client := &fakeMongoClient{err: errNope, disconnects: make(chan error, 1)}
+
+	s.TriggerHardStop()
+	assert.ErrorIs(t, DisconnectMongo(s, client, nil), errNope)
+	assert.ErrorIs(t, s.StopErr(), errNope)
+
+	assert.Error(t, <-client.disconnects)
+}
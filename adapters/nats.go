@@ -0,0 +1,92 @@
+package adapters
+
+import (
+	"github.com/Jeffail/shutdown"
+)
+
+// NATSConn is the subset of the API of a *nats.Conn used by DrainNATS.
+type NATSConn interface {
+	Drain() error
+	Close()
+}
+
+// NATSSubscription is the subset of the API of a *nats.Subscription used by
+// DrainNATSSubscriptions.
+type NATSSubscription interface {
+	Drain() error
+	Unsubscribe() error
+}
+
+// NATSSubscriptionDrain is a subscription drained by DrainNATSSubscriptions
+// along with a channel that is closed once the subscription has ended, which
+// for a *nats.Subscription is once nats.SubscriptionClosed has been received
+// from its StatusChanged channel.
+type NATSSubscriptionDrain struct {
+	Sub    NATSSubscription
+	Closed <-chan struct{}
+}
+
+// DrainNATS blocks until a soft stop is signalled and then drains the
+// connection, where its subscriptions stop receiving new messages, messages
+// already received are processed and pending publishes are flushed before the
+// connection closes. If a hard stop is signalled before the drain completes
+// then the connection is closed immediately. The signaller is triggered as
+// having stopped once the connection has closed.
+//
+// The completion of the drain is observed from the closed channel, which is
+// closed by the closed handler of the connection:
+//
+//	closed := make(chan struct{})
+//	conn, err := nats.Connect(url, nats.ClosedHandler(func(*nats.Conn) {
+//		close(closed)
+//	}))
+func DrainNATS(s *shutdown.Signaller, conn NATSConn, closed <-chan struct{}) error {
+	defer s.TriggerHasStopped()
+
+	<-s.SoftStopChan()
+	if err := conn.Drain(); err != nil {
+		conn.Close()
+		return err
+	}
+	select {
+	case <-closed:
+	case <-s.HardStopChan():
+		conn.Close()
+	}
+	return nil
+}
+
+// DrainNATSSubscriptions blocks until a soft stop is signalled and then drains
+// each subscription, allowing messages already received to be processed while
+// the connection itself remains open for publishing. If a hard stop is
+// signalled before the drains complete then the remaining subscriptions are
+// unsubscribed immediately. The signaller is triggered as having stopped once
+// every subscription has ended.
+func DrainNATSSubscriptions(s *shutdown.Signaller, subs ...NATSSubscriptionDrain) error {
+	defer s.TriggerHasStopped()
+
+	<-s.SoftStopChan()
+
+	var firstErr error
+	for _, sub := range subs {
+		if err := sub.Sub.Drain(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	for i, sub := range subs {
+		select {
+		case <-sub.Closed:
+		case <-s.HardStopChan():
+			for _, sub := range subs[i:] {
+				select {
+				case <-sub.Closed:
+				default:
+					_ = sub.Sub.Unsubscribe()
+				}
+			}
+			return firstErr
+		}
+	}
+	return firstErr
+}
@@ -0,0 +1,155 @@
+package adapters
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Jeffail/shutdown"
+)
+
+type fakeNATSConn struct {
+	drainErr   error
+	drainDelay time.Duration
+	drained    atomic.Bool
+	forced     atomic.Bool
+
+	closeOnce sync.Once
+	closed    chan struct{}
+}
+
+func newFakeNATSConn(drainDelay time.Duration, drainErr error) *fakeNATSConn {
+	return &fakeNATSConn{drainDelay: drainDelay, drainErr: drainErr, closed: make(chan struct{})}
+}
+
+// close mimics the closed handler of a connection.
+func (c *fakeNATSConn) close() {
+	c.closeOnce.Do(func() { close(c.closed) })
+}
+
+func (c *fakeNATSConn) Drain() error {
+	if c.drainErr != nil {
+		return c.drainErr
+	}
+	c.drained.Store(true)
+	if c.drainDelay >= 0 {
+		time.AfterFunc(c.drainDelay, c.close)
+	}
+	return nil
+}
+
+func (c *fakeNATSConn) Close() {
+	c.forced.Store(true)
+	c.close()
+}
+
+// runAdapter runs an adapter in the background and returns a function that
+// waits for its result.
+func runAdapter(t *testing.T, fn func() error) func() error {
+	t.Helper()
+	errC := make(chan error, 1)
+	go func() {
+		errC <- fn()
+	}()
+	return func() error {
+		t.Helper()
+		select {
+		case err := <-errC:
+			return err
+		case <-time.After(time.Second * 5):
+			t.Fatal("timed out waiting for adapter")
+		}
+		return nil
+	}
+}
+
+func TestDrainNATS(t *testing.T) {
+	s := shutdown.NewSignaller()
+	conn := newFakeNATSConn(time.Millisecond*20, nil)
+
+	wait := runAdapter(t, func() error { return DrainNATS(s, conn, conn.closed) })
+	s.TriggerSoftStop()
+
+	require.NoError(t, wait())
+	assert.True(t, conn.drained.Load())
+	assert.False(t, conn.forced.Load())
+	assert.True(t, s.IsHasStoppedSignalled())
+}
+
+func TestDrainNATSHardStop(t *testing.T) {
+	s := shutdown.NewSignaller()
+	conn := newFakeNATSConn(-1, nil)
+
+	wait := runAdapter(t, func() error { return DrainNATS(s, conn, conn.closed) })
+	s.TriggerSoftStop()
+	s.TriggerHardStop()
+
+	require.NoError(t, wait())
+	assert.True(t, conn.forced.Load())
+	assert.True(t, s.IsHasStoppedSignalled())
+}
+
+func TestDrainNATSError(t *testing.T) {
+	errNope := errors.New("nope")
+	s := shutdown.NewSignaller()
+	conn := newFakeNATSConn(0, errNope)
+
+	s.TriggerSoftStop()
+	assert.ErrorIs(t, DrainNATS(s, conn, conn.closed), errNope)
+	assert.True(t, conn.forced.Load())
+	assert.True(t, s.IsHasStoppedSignalled())
+}
+
+type fakeNATSSub struct {
+	drainDelay   time.Duration
+	unsubscribed atomic.Bool
+
+	closeOnce sync.Once
+	closed    chan struct{}
+}
+
+func newFakeNATSSub(delay time.Duration) *fakeNATSSub {
+	return &fakeNATSSub{drainDelay: delay, closed: make(chan struct{})}
+}
+
+func (s *fakeNATSSub) drain() NATSSubscriptionDrain {
+	return NATSSubscriptionDrain{Sub: s, Closed: s.closed}
+}
+
+func (s *fakeNATSSub) close() {
+	s.closeOnce.Do(func() { close(s.closed) })
+}
+
+func (s *fakeNATSSub) Drain() error {
+	if s.drainDelay >= 0 {
+		time.AfterFunc(s.drainDelay, s.close)
+	}
+	return nil
+}
+
+func (s *fakeNATSSub) Unsubscribe() error {
+	s.unsubscribed.Store(true)
+	s.close()
+	return nil
+}
+
+func TestDrainNATSSubscriptions(t *testing.T) {
+	s := shutdown.NewSignaller()
+	a, b := newFakeNATSSub(time.Millisecond*10), newFakeNATSSub(-1)
+
+	wait := runAdapter(t, func() error { return DrainNATSSubscriptions(s, a.drain(), b.drain()) })
+	s.TriggerSoftStop()
+	<-a.closed
+	assert.False(t, s.IsHasStoppedSignalled())
+
+	s.TriggerHardStop()
+	require.NoError(t, wait())
+	assert.False(t, a.unsubscribed.Load())
+	assert.True(t, b.unsubscribed.Load())
+	assert.True(t, s.IsHasStoppedSignalled())
+}
@@ -0,0 +1,71 @@
+package adapters
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Jeffail/shutdown"
+)
+
+// Producer is an asynchronous producer that buffers messages before sending
+// them in batches, expressed in terms that are satisfied by a thin wrapper
+// around a Kafka producer, a NATS connection or a statsd client.
+type Producer interface {
+	// Flush blocks until every buffered message has been sent, returning
+	// early with the error of the context if it is cancelled.
+	Flush(ctx context.Context) error
+
+	// Buffered returns the number of messages that have not yet been sent.
+	Buffered() int
+
+	// Close closes the producer, abandoning any buffered messages.
+	Close() error
+}
+
+// ProducerDroppedError is recorded against a signaller when a hard stop is
+// signalled before a producer has flushed, and counts the messages that were
+// abandoned.
+type ProducerDroppedError struct {
+	Dropped int
+}
+
+// Error returns a description of the dropped messages.
+func (e *ProducerDroppedError) Error() string {
+	return fmt.Sprintf("producer dropped %v buffered messages on hard stop", e.Dropped)
+}
+
+// Unwrap returns shutdown.ErrHardStop.
+func (e *ProducerDroppedError) Unwrap() error {
+	return shutdown.ErrHardStop
+}
+
+// FlushProducer blocks until a soft stop is signalled and then flushes the
+// producer, with a context that is cancelled once a hard stop is signalled,
+// before closing it. The signaller is triggered as having stopped once the
+// producer has closed, and so the messages buffered by a component are sent
+// before it reports having stopped.
+//
+// If the hard stop is signalled before the flush completes then the messages
+// that remain buffered are abandoned, and a *ProducerDroppedError counting them
+// is recorded against the signaller and returned. Any other error of the flush
+// or the close is also recorded and returned.
+func FlushProducer(s *shutdown.Signaller, p Producer) error {
+	defer s.TriggerHasStopped()
+
+	<-s.SoftStopChan()
+
+	ctx, done := s.HardStopCtx(context.Background())
+	defer done()
+
+	err := p.Flush(ctx)
+	if ctx.Err() != nil {
+		err = nil
+		if n := p.Buffered(); n > 0 {
+			err = &ProducerDroppedError{Dropped: n}
+		}
+	}
+	err = errors.Join(err, p.Close())
+	s.RecordStopErr(err)
+	return err
+}
@@ -0,0 +1,78 @@
+package adapters
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Jeffail/shutdown"
+)
+
+type fakeProducer struct {
+	buffered atomic.Int64
+	release  chan struct{}
+	closed   atomic.Bool
+}
+
+func (p *fakeProducer) Flush(ctx context.Context) error {
+	select {
+	case <-p.release:
+		p.buffered.Store(0)
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (p *fakeProducer) Buffered() int {
+	return int(p.buffered.Load())
+}
+
+func (p *fakeProducer) Close() error {
+	p.closed.Store(true)
+	return nil
+}
+
+func TestFlushProducer(t *testing.T) {
+	s := shutdown.NewSignaller()
+	p := &fakeProducer{release: make(chan struct{})}
+	p.buffered.Store(3)
+
+	wait := runAdapter(t, func() error {
+		return FlushProducer(s, p)
+	})
+
+	s.TriggerSoftStop()
+	assert.False(t, s.IsHasStoppedSignalled())
+
+	close(p.release)
+	require.NoError(t, wait())
+	assert.Equal(t, 0, p.Buffered())
+	assert.True(t, p.closed.Load())
+	assert.NoError(t, s.StopErr())
+	assert.True(t, s.IsHasStoppedSignalled())
+}
+
+func TestFlushProducerHardStop(t *testing.T) {
+	s := shutdown.NewSignaller()
+	p := &fakeProducer{release: make(chan struct{})}
+	p.buffered.Store(3)
+
+	wait := runAdapter(t, func() error {
+		return FlushProducer(s, p)
+	})
+
+	s.TriggerSoftStop()
+	s.TriggerHardStop()
+
+	var dropped *ProducerDroppedError
+	require.ErrorAs(t, wait(), &dropped)
+	assert.Equal(t, 3, dropped.Dropped)
+	assert.ErrorIs(t, dropped, shutdown.ErrHardStop)
+	assert.ErrorAs(t, s.StopErr(), &dropped)
+	assert.True(t, p.closed.Load())
+	assert.True(t, s.IsHasStoppedSignalled())
+}
@@ -0,0 +1,59 @@
+package adapters
+
+import (
+	"context"
+
+	"github.com/Jeffail/shutdown"
+)
+
+// RedisClient is the subset of the API of a Redis client or pool, such as a
+// *redis.Client of go-redis, used by Redis.
+type RedisClient interface {
+	Close() error
+}
+
+// Redis binds a Redis client to a signaller. Commands issued through Do are
+// admitted until a soft stop is signalled, after which they are rejected with
+// shutdown.ErrGateClosed, and the client is closed by Run once the commands in
+// flight have finished.
+type Redis[C RedisClient] struct {
+	s      *shutdown.Signaller
+	client C
+	gate   *shutdown.Gate
+}
+
+// NewRedis binds a Redis client to a signaller.
+func NewRedis[C RedisClient](s *shutdown.Signaller, client C) *Redis[C] {
+	return &Redis[C]{s: s, client: client, gate: shutdown.NewGate(s)}
+}
+
+// Do issues commands with the client. The provided function is called with a
+// context derived from ctx that is also cancelled once a hard stop has been
+// signalled, which bounds the final commands issued during a soft stop by the
+// hard stop. Returns shutdown.ErrGateClosed without calling the function if a
+// soft stop has been signalled.
+func (r *Redis[C]) Do(ctx context.Context, fn func(ctx context.Context, client C) error) error {
+	return r.gate.Do(ctx, func(ctx context.Context) error {
+		return fn(ctx, r.client)
+	})
+}
+
+// InFlight returns the number of calls to Do that have not yet returned.
+func (r *Redis[C]) InFlight() int {
+	return r.gate.InFlight()
+}
+
+// Run blocks until a soft stop is signalled, waits for the commands in flight
+// to finish, or for a hard stop to be signalled, and then closes the client.
+// The signaller is triggered as having stopped once the client has closed.
+func (r *Redis[C]) Run() error {
+	defer r.s.TriggerHasStopped()
+
+	<-r.s.SoftStopChan()
+
+	ctx, done := r.s.HardStopCtx(context.Background())
+	defer done()
+
+	_ = r.gate.Wait(ctx)
+	return r.client.Close()
+}
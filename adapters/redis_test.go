@@ -0,0 +1,81 @@
+package adapters
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Jeffail/shutdown"
+)
+
+type fakeRedisClient struct {
+	closed atomic.Bool
+}
+
+func (c *fakeRedisClient) Close() error {
+	c.closed.Store(true)
+	return nil
+}
+
+func TestRedis(t *testing.T) {
+	s := shutdown.NewSignaller()
+	client := &fakeRedisClient{}
+	r := NewRedis(s, client)
+
+	wait := runAdapter(t, r.Run)
+
+	started, release := make(chan struct{}), make(chan struct{})
+	cmdErr := make(chan error, 1)
+	go func() {
+		cmdErr <- r.Do(context.Background(), func(ctx context.Context, c *fakeRedisClient) error {
+			close(started)
+			<-release
+			return ctx.Err()
+		})
+	}()
+	<-started
+
+	s.TriggerSoftStop()
+	assert.ErrorIs(t, r.Do(context.Background(), func(ctx context.Context, c *fakeRedisClient) error {
+		t.Error("command issued after soft stop")
+		return nil
+	}), shutdown.ErrGateClosed)
+
+	time.Sleep(time.Millisecond * 10)
+	assert.False(t, client.closed.Load())
+	assert.Equal(t, 1, r.InFlight())
+
+	close(release)
+	require.NoError(t, <-cmdErr)
+	require.NoError(t, wait())
+	assert.True(t, client.closed.Load())
+	assert.True(t, s.IsHasStoppedSignalled())
+}
+
+func TestRedisHardStop(t *testing.T) {
+	s := shutdown.NewSignaller()
+	client := &fakeRedisClient{}
+	r := NewRedis(s, client)
+
+	wait := runAdapter(t, r.Run)
+
+	started := make(chan struct{})
+	cmdErr := make(chan error, 1)
+	go func() {
+		cmdErr <- r.Do(context.Background(), func(ctx context.Context, c *fakeRedisClient) error {
+			close(started)
+			<-ctx.Done()
+			return ctx.Err()
+		})
+	}()
+	<-started
+
+	s.TriggerHardStop()
+	assert.ErrorIs(t, <-cmdErr, context.Canceled)
+	require.NoError(t, wait())
+	assert.True(t, client.closed.Load())
+}
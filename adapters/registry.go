@@ -0,0 +1,40 @@
+package adapters
+
+import "context"
+
+// ConsulAgent is the subset of the API of an *api.Agent of the Consul client
+// used by ConsulDeregistration.
+type ConsulAgent interface {
+	ServiceDeregister(serviceID string) error
+}
+
+// ConsulDeregistration returns a function that deregisters a service from the
+// local Consul agent, for use with shutdown.WithDeregistration:
+//
+//	s := shutdown.NewSignaller(
+//		shutdown.WithSignals(syscall.SIGTERM),
+//		shutdown.WithDeregistration(adapters.ConsulDeregistration(client.Agent(), serviceID)),
+//		shutdown.WithDrainDelay(5*time.Second),
+//	)
+func ConsulDeregistration(agent ConsulAgent, serviceID string) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		return agent.ServiceDeregister(serviceID)
+	}
+}
+
+// EtcdLease is the subset of the API of a clientv3.Lease of the etcd client
+// used by EtcdDeregistration, where I is the type of lease IDs and R the type
+// of the response to a revocation.
+type EtcdLease[I, R any] interface {
+	Revoke(ctx context.Context, id I) (R, error)
+}
+
+// EtcdDeregistration returns a function that revokes the etcd lease to which
+// the registration keys of a service are attached, which deletes the keys,
+// for use with shutdown.WithDeregistration.
+func EtcdDeregistration[I, R any](lease EtcdLease[I, R], id I) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		_, err := lease.Revoke(ctx, id)
+		return err
+	}
+}
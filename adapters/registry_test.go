@@ -0,0 +1,46 @@
+package adapters
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeConsulAgent struct {
+	deregistered []string
+}
+
+func (a *fakeConsulAgent) ServiceDeregister(serviceID string) error {
+	a.deregistered = append(a.deregistered, serviceID)
+	return nil
+}
+
+func TestConsulDeregistration(t *testing.T) {
+	agent := &fakeConsulAgent{}
+	require.NoError(t, ConsulDeregistration(agent, "api-1")(context.Background()))
+	assert.Equal(t, []string{"api-1"}, agent.deregistered)
+}
+
+type fakeLeaseRevokeResponse struct{}
+
+type fakeEtcdLease struct {
+	revoked []int64
+	err     error
+}
+
+func (l *fakeEtcdLease) Revoke(ctx context.Context, id int64) (*fakeLeaseRevokeResponse, error) {
+	l.revoked = append(l.revoked, id)
+	return &fakeLeaseRevokeResponse{}, l.err
+}
+
+func TestEtcdDeregistration(t *testing.T) {
+	lease := &fakeEtcdLease{}
+	require.NoError(t, EtcdDeregistration[int64, *fakeLeaseRevokeResponse](lease, 42)(context.Background()))
+	assert.Equal(t, []int64{42}, lease.revoked)
+
+	lease.err = errors.New("lease not found")
+	assert.ErrorIs(t, EtcdDeregistration[int64, *fakeLeaseRevokeResponse](lease, 7)(context.Background()), lease.err)
+}
@@ -0,0 +1,144 @@
+package adapters
+
+import (
+	"context"
+	"io"
+	"net"
+	"sync"
+
+	"github.com/Jeffail/shutdown"
+)
+
+// SSHServer is the subset of the API of an *ssh.Server of gliderlabs/ssh used
+// by ServeSSH.
+type SSHServer interface {
+	Serve(ln net.Listener) error
+	Shutdown(ctx context.Context) error
+	Close() error
+}
+
+// SSHSession is the subset of the API of an ssh.Session of gliderlabs/ssh used
+// by SSHSessions.
+type SSHSession interface {
+	Stderr() io.ReadWriter
+	Close() error
+}
+
+// SSHSessions tracks the active sessions of an SSH server so that ServeSSH can
+// warn them of a shutdown and tear down those that remain once a hard stop is
+// signalled.
+type SSHSessions struct {
+	s       *shutdown.Signaller
+	warning string
+
+	mut      sync.Mutex
+	sessions map[*trackedSSHSession]struct{}
+}
+
+type trackedSSHSession struct {
+	sess SSHSession
+}
+
+// NewSSHSessions creates a tracker of SSH sessions. Once a soft stop is
+// signalled the warning is written to the stderr of each active session, and
+// should tell users to save their work and disconnect.
+func NewSSHSessions(s *shutdown.Signaller, warning string) *SSHSessions {
+	return &SSHSessions{
+		s:        s,
+		warning:  warning,
+		sessions: map[*trackedSSHSession]struct{}{},
+	}
+}
+
+// Track registers an active session, and returns a function that must be
+// called once the session has ended, typically deferred from the session
+// handler. Returns shutdown.ErrGateClosed if a soft stop has already been
+// signalled, in which case the session should be refused.
+func (t *SSHSessions) Track(sess SSHSession) (ended func(), err error) {
+	t.mut.Lock()
+	defer t.mut.Unlock()
+
+	if t.s.IsSoftStopSignalled() {
+		return nil, shutdown.ErrGateClosed
+	}
+	ts := &trackedSSHSession{sess: sess}
+	t.sessions[ts] = struct{}{}
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			t.mut.Lock()
+			delete(t.sessions, ts)
+			t.mut.Unlock()
+		})
+	}, nil
+}
+
+// Len returns the number of active sessions.
+func (t *SSHSessions) Len() int {
+	t.mut.Lock()
+	defer t.mut.Unlock()
+	return len(t.sessions)
+}
+
+func (t *SSHSessions) snapshot() (sessions []SSHSession) {
+	t.mut.Lock()
+	defer t.mut.Unlock()
+
+	for ts := range t.sessions {
+		sessions = append(sessions, ts.sess)
+	}
+	return
+}
+
+// ServeSSH runs an SSH server on the provided listener for the lifetime of a
+// signaller. Once a soft stop is signalled the warning of the sessions tracker,
+// when one is provided, is written to each active session without waiting for
+// the writes to complete, and the server is shut down gracefully, which stops
+// accepting new sessions and waits for the active sessions to end. Once a hard
+// stop is signalled the remaining sessions are closed along with the server,
+// which also unblocks any warnings that clients have not read. The signaller
+// is triggered as having stopped once the server has stopped.
+//
+// The error of the server is returned if it fails before a soft stop, in which
+// case a soft stop is also triggered.
+func ServeSSH(s *shutdown.Signaller, srv SSHServer, ln net.Listener, sessions *SSHSessions) error {
+	defer s.TriggerHasStopped()
+
+	errC := make(chan error, 1)
+	go func() {
+		errC <- srv.Serve(ln)
+	}()
+
+	select {
+	case err := <-errC:
+		s.TriggerSoftStop()
+		return err
+	case <-s.SoftStopChan():
+	}
+
+	ctx, done := s.HardStopCtx(context.Background())
+	defer done()
+
+	if sessions != nil && sessions.warning != "" {
+		// Warnings are written concurrently, as a client that has stopped
+		// reading blocks the write until its session is closed.
+		for _, sess := range sessions.snapshot() {
+			go func(sess SSHSession) {
+				_, _ = io.WriteString(sess.Stderr(), sessions.warning+"\n")
+			}(sess)
+		}
+	}
+
+	if err := srv.Shutdown(ctx); err != nil {
+		// Sessions were still active when the hard stop was signalled.
+		if sessions != nil {
+			for _, sess := range sessions.snapshot() {
+				_ = sess.Close()
+			}
+		}
+		_ = srv.Close()
+	}
+	<-errC
+	return nil
+}
@@ -0,0 +1,212 @@
+package adapters
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Jeffail/shutdown"
+)
+
+var errSSHServerClosed = errors.New("ssh: server closed")
+
+type fakeSSHServer struct {
+	sessions *SSHSessions
+	stop     chan struct{}
+	once     sync.Once
+	closed   atomic.Bool
+}
+
+func newFakeSSHServer(sessions *SSHSessions) *fakeSSHServer {
+	return &fakeSSHServer{sessions: sessions, stop: make(chan struct{})}
+}
+
+func (s *fakeSSHServer) Serve(ln net.Listener) error {
+	<-s.stop
+	return errSSHServerClosed
+}
+
+func (s *fakeSSHServer) Shutdown(ctx context.Context) error {
+	defer s.once.Do(func() { close(s.stop) })
+	if !pollUntil(ctx.Done(), func() bool { return s.sessions.Len() == 0 }) {
+		return ctx.Err()
+	}
+	return nil
+}
+
+func (s *fakeSSHServer) Close() error {
+	s.closed.Store(true)
+	s.once.Do(func() { close(s.stop) })
+	return nil
+}
+
+type fakeSSHSession struct {
+	mut    sync.Mutex
+	stderr bytes.Buffer
+	closed atomic.Bool
+	ended  func()
+
+	// When set, writes block until the session is closed, as with a client
+	// that has stopped reading.
+	stuck chan struct{}
+}
+
+func (s *fakeSSHSession) Stderr() io.ReadWriter {
+	return s
+}
+
+func (s *fakeSSHSession) Read(p []byte) (int, error) {
+	return 0, io.EOF
+}
+
+func (s *fakeSSHSession) Write(p []byte) (int, error) {
+	if s.stuck != nil {
+		<-s.stuck
+		return 0, io.ErrClosedPipe
+	}
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	return s.stderr.Write(p)
+}
+
+func (s *fakeSSHSession) written() string {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	return s.stderr.String()
+}
+
+func (s *fakeSSHSession) Close() error {
+	if s.closed.CompareAndSwap(false, true) && s.stuck != nil {
+		close(s.stuck)
+	}
+	s.ended()
+	return nil
+}
+
+func TestServeSSH(t *testing.T) {
+	s := shutdown.NewSignaller()
+	sessions := NewSSHSessions(s, "server restarting, please disconnect")
+	srv := newFakeSSHServer(sessions)
+
+	sess := &fakeSSHSession{}
+	ended, err := sessions.Track(sess)
+	require.NoError(t, err)
+	sess.ended = ended
+
+	wait := runAdapter(t, func() error {
+		return ServeSSH(s, srv, nil, sessions)
+	})
+
+	s.TriggerSoftStop()
+	_, err = sessions.Track(&fakeSSHSession{})
+	assert.ErrorIs(t, err, shutdown.ErrGateClosed)
+
+	assert.Eventually(t, func() bool {
+		return sess.written() == "server restarting, please disconnect\n"
+	}, time.Second, time.Millisecond)
+	assert.Equal(t, 1, sessions.Len())
+
+	ended()
+	require.NoError(t, wait())
+	assert.False(t, sess.closed.Load())
+	assert.False(t, srv.closed.Load())
+	assert.True(t, s.IsHasStoppedSignalled())
+}
+
+func TestServeSSHHardStop(t *testing.T) {
+	s := shutdown.NewSignaller()
+	sessions := NewSSHSessions(s, "")
+	srv := newFakeSSHServer(sessions)
+
+	sess := &fakeSSHSession{}
+	ended, err := sessions.Track(sess)
+	require.NoError(t, err)
+	sess.ended = ended
+
+	wait := runAdapter(t, func() error {
+		return ServeSSH(s, srv, nil, sessions)
+	})
+
+	s.TriggerSoftStop()
+	s.TriggerHardStop()
+	require.NoError(t, wait())
+	assert.Empty(t, sess.written())
+	assert.True(t, sess.closed.Load())
+	assert.True(t, srv.closed.Load())
+	assert.True(t, s.IsHasStoppedSignalled())
+}
+
+func TestServeSSHStuckSession(t *testing.T) {
+	s := shutdown.NewSignaller()
+	sessions := NewSSHSessions(s, "server restarting, please disconnect")
+	srv := newFakeSSHServer(sessions)
+
+	stuck := &fakeSSHSession{stuck: make(chan struct{})}
+	ended, err := sessions.Track(stuck)
+	require.NoError(t, err)
+	stuck.ended = ended
+
+	sess := &fakeSSHSession{}
+	ended, err = sessions.Track(sess)
+	require.NoError(t, err)
+	sess.ended = ended
+
+	wait := runAdapter(t, func() error {
+		return ServeSSH(s, srv, nil, sessions)
+	})
+
+	// A session that does not read its warning does not hold up the others,
+	// nor the hard stop.
+	s.TriggerSoftStop()
+	assert.Eventually(t, func() bool {
+		return sess.written() == "server restarting, please disconnect\n"
+	}, time.Second, time.Millisecond)
+
+	s.TriggerHardStop()
+	require.NoError(t, wait())
+	assert.True(t, stuck.closed.Load())
+	assert.True(t, srv.closed.Load())
+}
+
+func TestServeSSHError(t *testing.T) {
+	s := shutdown.NewSignaller()
+	srv := newFakeSSHServer(NewSSHSessions(s, ""))
+	_ = srv.Close()
+
+	assert.ErrorIs(t, ServeSSH(s, srv, nil, nil), errSSHServerClosed)
+	assert.True(t, s.IsSoftStopSignalled())
+	assert.True(t, s.IsHasStoppedSignalled())
+}
+
+// pollInterval is the interval at which the fake server polls for its
+// sessions to end.
+const pollInterval = 10 * time.Millisecond
+
+// pollUntil calls cond periodically until it returns true, returning false if
+// the abort channel is closed first.
+func pollUntil(abort <-chan struct{}, cond func() bool) bool {
+	if cond() {
+		return true
+	}
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if cond() {
+				return true
+			}
+		case <-abort:
+			return cond()
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package adapters
+
+import (
+	"encoding/binary"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/Jeffail/shutdown"
+)
+
+// WebSocketGoingAway is the close code sent to peers of WebSocket connections
+// when the server is shutting down.
+const WebSocketGoingAway = 1001
+
+// WebSocketConn is a WebSocket connection tracked by WebSockets.
+type WebSocketConn interface {
+	// SendClose sends a close frame to the peer without closing the
+	// underlying connection.
+	SendClose(code int, reason string) error
+
+	// Close closes the underlying connection immediately.
+	Close() error
+}
+
+// GorillaWebSocketConn is the subset of the API of a *websocket.Conn of
+// gorilla/websocket used by GorillaWebSocket.
+type GorillaWebSocketConn interface {
+	WriteControl(messageType int, data []byte, deadline time.Time) error
+	Close() error
+}
+
+// GorillaWebSocket adapts a gorilla/websocket connection to a WebSocketConn.
+func GorillaWebSocket(conn GorillaWebSocketConn) WebSocketConn {
+	return gorillaConn{conn}
+}
+
+type gorillaConn struct {
+	GorillaWebSocketConn
+}
+
+func (c gorillaConn) SendClose(code int, reason string) error {
+	const closeMessage = 8
+
+	data := make([]byte, 2+len(reason))
+	binary.BigEndian.PutUint16(data, uint16(code))
+	copy(data[2:], reason)
+	return c.WriteControl(closeMessage, data, time.Now().Add(time.Second))
+}
+
+// WebSockets tracks the open WebSocket connections of a server. Once a soft
+// stop is signalled Run sends each connection a close frame, waits for their
+// peers to close them, and closes any that remain open once a hard stop is
+// signalled.
+type WebSockets struct {
+	s *shutdown.Signaller
+
+	mut   sync.Mutex
+	conns map[*trackedWebSocket]struct{}
+	idle  chan struct{}
+}
+
+type trackedWebSocket struct {
+	conn WebSocketConn
+}
+
+// NewWebSockets creates a tracker of WebSocket connections.
+func NewWebSockets(s *shutdown.Signaller) *WebSockets {
+	return &WebSockets{s: s, conns: map[*trackedWebSocket]struct{}{}}
+}
+
+// Track registers an open connection, and returns a function that must be
+// called once the connection has closed, typically when its read loop ends.
+// Returns shutdown.ErrGateClosed if a soft stop has already been signalled, in
+// which case the connection should be refused.
+func (w *WebSockets) Track(conn WebSocketConn) (closed func(), err error) {
+	w.mut.Lock()
+	defer w.mut.Unlock()
+
+	if w.s.IsSoftStopSignalled() {
+		return nil, shutdown.ErrGateClosed
+	}
+	t := &trackedWebSocket{conn: conn}
+	w.conns[t] = struct{}{}
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			w.mut.Lock()
+			defer w.mut.Unlock()
+
+			delete(w.conns, t)
+			if len(w.conns) == 0 && w.idle != nil {
+				close(w.idle)
+				w.idle = nil
+			}
+		})
+	}, nil
+}
+
+// Len returns the number of open connections.
+func (w *WebSockets) Len() int {
+	w.mut.Lock()
+	defer w.mut.Unlock()
+	return len(w.conns)
+}
+
+func (w *WebSockets) snapshot() (conns []WebSocketConn, idle <-chan struct{}) {
+	w.mut.Lock()
+	defer w.mut.Unlock()
+
+	for t := range w.conns {
+		conns = append(conns, t.conn)
+	}
+	if len(w.conns) == 0 {
+		return nil, nil
+	}
+	if w.idle == nil {
+		w.idle = make(chan struct{})
+	}
+	return conns, w.idle
+}
+
+// Run blocks until a soft stop is signalled, sends a close frame with the code
+// WebSocketGoingAway to each open connection, and waits for every connection
+// to close. Connections that remain open once a hard stop is signalled are
+// closed immediately. The signaller is triggered as having stopped once every
+// connection has closed.
+func (w *WebSockets) Run() error {
+	defer w.s.TriggerHasStopped()
+
+	<-w.s.SoftStopChan()
+
+	conns, idle := w.snapshot()
+	if idle == nil {
+		return nil
+	}
+
+	var errs []error
+	for _, c := range conns {
+		if err := c.SendClose(WebSocketGoingAway, "server shutting down"); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	select {
+	case <-idle:
+		return errors.Join(errs...)
+	case <-w.s.HardStopChan():
+	}
+
+	conns, idle = w.snapshot()
+	for _, c := range conns {
+		_ = c.Close()
+	}
+	if idle != nil {
+		<-idle
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,101 @@
+package adapters
+
+import (
+	"encoding/binary"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Jeffail/shutdown"
+)
+
+type fakeWebSocket struct {
+	mut        sync.Mutex
+	closeCodes []int
+	closed     bool
+	onClose    func()
+}
+
+func (f *fakeWebSocket) SendClose(code int, reason string) error {
+	f.mut.Lock()
+	f.closeCodes = append(f.closeCodes, code)
+	f.mut.Unlock()
+	return nil
+}
+
+func (f *fakeWebSocket) Close() error {
+	f.mut.Lock()
+	f.closed = true
+	f.mut.Unlock()
+	f.onClose()
+	return nil
+}
+
+func TestWebSockets(t *testing.T) {
+	s := shutdown.NewSignaller()
+	w := NewWebSockets(s)
+
+	a, b := &fakeWebSocket{}, &fakeWebSocket{}
+	aDone, err := w.Track(a)
+	require.NoError(t, err)
+	bDone, err := w.Track(b)
+	require.NoError(t, err)
+	a.onClose, b.onClose = aDone, bDone
+	assert.Equal(t, 2, w.Len())
+
+	wait := runAdapter(t, w.Run)
+	s.TriggerSoftStop()
+
+	_, err = w.Track(&fakeWebSocket{})
+	assert.ErrorIs(t, err, shutdown.ErrGateClosed)
+
+	// One peer closes in response to the close frame.
+	time.Sleep(time.Millisecond * 10)
+	aDone()
+	assert.False(t, s.IsHasStoppedSignalled())
+
+	// The other is closed by the hard stop.
+	s.TriggerHardStop()
+	require.NoError(t, wait())
+
+	assert.Equal(t, []int{WebSocketGoingAway}, a.closeCodes)
+	assert.Equal(t, []int{WebSocketGoingAway}, b.closeCodes)
+	assert.False(t, a.closed)
+	assert.True(t, b.closed)
+	assert.Equal(t, 0, w.Len())
+	assert.True(t, s.IsHasStoppedSignalled())
+}
+
+func TestWebSocketsEmpty(t *testing.T) {
+	s := shutdown.NewSignaller()
+	w := NewWebSockets(s)
+
+	s.TriggerSoftStop()
+	require.NoError(t, w.Run())
+	assert.True(t, s.IsHasStoppedSignalled())
+}
+
+type fakeGorillaConn struct {
+	messageType int
+	data        []byte
+}
+
+func (f *fakeGorillaConn) WriteControl(messageType int, data []byte, deadline time.Time) error {
+	f.messageType, f.data = messageType, data
+	return nil
+}
+
+func (f *fakeGorillaConn) Close() error {
+	return nil
+}
+
+func TestGorillaWebSocket(t *testing.T) {
+	g := &fakeGorillaConn{}
+	require.NoError(t, GorillaWebSocket(g).SendClose(WebSocketGoingAway, "bye"))
+	assert.Equal(t, 8, g.messageType)
+	assert.Equal(t, uint16(WebSocketGoingAway), binary.BigEndian.Uint16(g.data))
+	assert.Equal(t, "bye", string(g.data[2:]))
+}
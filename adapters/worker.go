@@ -0,0 +1,106 @@
+package adapters
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/Jeffail/shutdown"
+)
+
+// Worker is a background worker that runs until it is stopped, such as the
+// pool of a task or job framework.
+type Worker interface {
+	// Run runs the worker, blocking until it has stopped.
+	Run() error
+
+	// Stop causes the worker to stop accepting new tasks and waits for its
+	// running tasks to finish, abandoning them once the context is cancelled.
+	Stop(ctx context.Context) error
+}
+
+// RunWorker runs a worker until a soft stop is signalled, at which point the
+// worker is stopped with a context that is cancelled once a hard stop is
+// signalled, which bounds the time given to running tasks. The signaller is
+// triggered as having stopped once Run has returned, or once the hard stop is
+// signalled if Run has not returned by then, in which case the worker is
+// abandoned and the error of the context is returned.
+//
+// If the worker stops of its own accord then a soft stop is triggered and the
+// error of Run is returned.
+func RunWorker(s *shutdown.Signaller, w Worker) error {
+	defer s.TriggerHasStopped()
+
+	errC := make(chan error, 1)
+	go func() {
+		errC <- w.Run()
+	}()
+
+	select {
+	case err := <-errC:
+		s.TriggerSoftStop()
+		return err
+	case <-s.SoftStopChan():
+	}
+
+	ctx, done := s.HardStopCtx(context.Background())
+	defer done()
+
+	stopErr := w.Stop(ctx)
+	select {
+	case err := <-errC:
+		return errors.Join(err, stopErr)
+	case <-ctx.Done():
+	}
+
+	// The worker may have returned along with the hard stop.
+	select {
+	case err := <-errC:
+		return errors.Join(err, stopErr)
+	default:
+	}
+	if errors.Is(stopErr, ctx.Err()) {
+		return stopErr
+	}
+	return errors.Join(ctx.Err(), stopErr)
+}
+
+// TemporalWorker is the subset of the API of a Temporal worker.Worker used by
+// TemporalAsWorker.
+type TemporalWorker interface {
+	Run(interruptCh <-chan interface{}) error
+}
+
+// TemporalAsWorker adapts a Temporal worker for use with RunWorker. Stopping the
+// worker stops its pollers, so that no new tasks are accepted, and waits for
+// running activities to finish until the context of the stop is cancelled,
+// which for RunWorker is once the hard stop is signalled. The worker itself
+// waits for up to its WorkerStopTimeout, which should therefore be configured
+// to be no shorter than the hard stop grace period of the signaller.
+func TemporalAsWorker(w TemporalWorker) Worker {
+	return &temporalWorker{w: w, interrupt: make(chan interface{}), done: make(chan struct{})}
+}
+
+type temporalWorker struct {
+	w         TemporalWorker
+	interrupt chan interface{}
+	once      sync.Once
+	done      chan struct{}
+}
+
+func (t *temporalWorker) Run() error {
+	defer close(t.done)
+	return t.w.Run(t.interrupt)
+}
+
+func (t *temporalWorker) Stop(ctx context.Context) error {
+	t.once.Do(func() {
+		close(t.interrupt)
+	})
+	select {
+	case <-t.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,133 @@
+package adapters
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Jeffail/shutdown"
+)
+
+type fakeWorker struct {
+	runErr   error
+	stopped  chan struct{}
+	returned chan struct{}
+	stopErrs chan error
+
+	// When set, running tasks only finish once released, regardless of the
+	// context of the stop.
+	release chan struct{}
+}
+
+func newFakeWorker() *fakeWorker {
+	return &fakeWorker{stopped: make(chan struct{}), returned: make(chan struct{}), stopErrs: make(chan error, 1)}
+}
+
+func (w *fakeWorker) Run() error {
+	if w.runErr != nil {
+		return w.runErr
+	}
+	<-w.stopped
+	close(w.returned)
+	return nil
+}
+
+func (w *fakeWorker) Stop(ctx context.Context) error {
+	if w.release != nil {
+		go func() {
+			<-w.release
+			close(w.stopped)
+		}()
+		return nil
+	}
+	// Running tasks finish once the hard stop is signalled, and the worker
+	// returns before Stop does.
+	<-ctx.Done()
+	w.stopErrs <- ctx.Err()
+	close(w.stopped)
+	<-w.returned
+	return nil
+}
+
+func TestRunWorker(t *testing.T) {
+	s := shutdown.NewSignaller()
+	w := newFakeWorker()
+
+	wait := runAdapter(t, func() error { return RunWorker(s, w) })
+	s.TriggerSoftStop()
+	assert.False(t, s.IsHasStoppedSignalled())
+
+	s.TriggerHardStop()
+	require.NoError(t, wait())
+	assert.ErrorIs(t, <-w.stopErrs, context.Canceled)
+	assert.True(t, s.IsHasStoppedSignalled())
+}
+
+func TestRunWorkerAbandoned(t *testing.T) {
+	s := shutdown.NewSignaller()
+	w := newFakeWorker()
+	w.release = make(chan struct{})
+	defer close(w.release)
+
+	wait := runAdapter(t, func() error { return RunWorker(s, w) })
+	s.TriggerSoftStop()
+	s.TriggerHardStop()
+	assert.ErrorIs(t, wait(), context.Canceled)
+	assert.True(t, s.IsHasStoppedSignalled())
+}
+
+func TestRunWorkerFailure(t *testing.T) {
+	errNope := errors.New("nope")
+	s := shutdown.NewSignaller()
+	w := newFakeWorker()
+	w.runErr = errNope
+
+	assert.ErrorIs(t, RunWorker(s, w), errNope)
+	assert.True(t, s.IsSoftStopSignalled())
+	assert.True(t, s.IsHasStoppedSignalled())
+}
+
+type fakeTemporalWorker struct {
+	// Closed once running activities have finished.
+	activities chan struct{}
+}
+
+func (w fakeTemporalWorker) Run(interruptCh <-chan interface{}) error {
+	<-interruptCh
+	if w.activities != nil {
+		<-w.activities
+	}
+	return nil
+}
+
+func TestTemporalAsWorker(t *testing.T) {
+	s := shutdown.NewSignaller()
+	wait := runAdapter(t, func() error {
+		return RunWorker(s, TemporalAsWorker(fakeTemporalWorker{}))
+	})
+
+	s.TriggerSoftStop()
+	require.NoError(t, wait())
+	assert.True(t, s.IsHasStoppedSignalled())
+	assert.False(t, s.IsHardStopSignalled())
+}
+
+func TestTemporalAsWorkerHardStop(t *testing.T) {
+	s := shutdown.NewSignaller()
+	activities := make(chan struct{})
+	defer close(activities)
+
+	wait := runAdapter(t, func() error {
+		return RunWorker(s, TemporalAsWorker(fakeTemporalWorker{activities: activities}))
+	})
+
+	s.TriggerSoftStop()
+	assert.False(t, s.IsHasStoppedSignalled())
+
+	s.TriggerHardStop()
+	assert.ErrorIs(t, wait(), context.Canceled)
+	assert.True(t, s.IsHasStoppedSignalled())
+}
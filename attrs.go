@@ -0,0 +1,91 @@
+package shutdown
+
+import (
+	"log/slog"
+)
+
+// MetricsWithAttrs is an optional extension of Metrics, where implementations
+// additionally receive the attributes attached to the signaller when a tier is
+// signalled, such as to be used as metric labels. When implemented,
+// SignalledWithAttrs is called instead of Signalled.
+type MetricsWithAttrs interface {
+	Metrics
+
+	SignalledWithAttrs(name string, t Tier, attrs []slog.Attr)
+}
+
+// TriggerSoftStopWith triggers a soft stop, as with TriggerSoftStop, and
+// attaches key/value pairs describing its cause to the signaller, which are
+// propagated into events, log records, metrics implementing MetricsWithAttrs,
+// the history and the diagnostics of the signaller. This allows a stop to be
+// correlated with the deploy or incident that caused it:
+//
+//	s.TriggerSoftStopWith("deploy_id", deployID, "admin_request_id", reqID)
+//
+// Arguments are interpreted in the same way as those of slog.Logger.Log, and
+// so slog.Attr values may be provided directly. Attributes are only attached
+// when the call triggers the soft stop.
+func (s *Signaller) TriggerSoftStopWith(args ...any) {
+	s.attachStopAttrs(TierSoftStop, args)
+	s.TriggerSoftStop()
+}
+
+// TriggerHardStopWith triggers a hard stop, as with TriggerHardStop, and
+// attaches key/value pairs describing its cause to the signaller in the same
+// way as TriggerSoftStopWith.
+func (s *Signaller) TriggerHardStopWith(args ...any) {
+	s.attachStopAttrs(TierHardStop, args)
+	s.TriggerHardStop()
+}
+
+// StopAttrs returns the attributes attached to the signaller with
+// TriggerSoftStopWith and TriggerHardStopWith, or by an OS signal, in the order
+// they were attached.
+func (s *Signaller) StopAttrs() []slog.Attr {
+	if s == nil {
+		return nil
+	}
+	x := s.ext.Load()
+	if x == nil {
+		return nil
+	}
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	return append([]slog.Attr(nil), x.stopAttrs...)
+}
+
+// attachStopAttrs attaches attributes to the signaller ahead of a trigger of
+// the tier, unless the tier has already been signalled.
+func (s *Signaller) attachStopAttrs(t Tier, args []any) {
+	if s == nil || len(args) == 0 || s.state.Load()&t.bit() != 0 {
+		return
+	}
+	attrs := slog.Group("", args...).Value.Group()
+
+	x := s.extra()
+	s.mut.Lock()
+	x.stopAttrs = append(x.stopAttrs, attrs...)
+	s.mut.Unlock()
+}
+
+// attrArgs returns attributes as arguments of a log record.
+func attrArgs(attrs []slog.Attr) []any {
+	args := make([]any, len(attrs))
+	for i, a := range attrs {
+		args[i] = a
+	}
+	return args
+}
+
+// attrsMap returns attributes as a map of their keys to resolved values, or
+// nil when there are none.
+func attrsMap(attrs []slog.Attr) map[string]any {
+	if len(attrs) == 0 {
+		return nil
+	}
+	m := make(map[string]any, len(attrs))
+	for _, a := range attrs {
+		m[a.Key] = a.Value.Resolve().Any()
+	}
+	return m
+}
@@ -0,0 +1,83 @@
+package shutdown
+
+import (
+	"bytes"
+	"log/slog"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type attrMetrics struct {
+	recordingMetrics
+
+	mut   sync.Mutex
+	attrs [][]slog.Attr
+}
+
+func (m *attrMetrics) SignalledWithAttrs(name string, t Tier, attrs []slog.Attr) {
+	m.mut.Lock()
+	m.attrs = append(m.attrs, attrs)
+	m.mut.Unlock()
+}
+
+func TestTriggerWithAttrs(t *testing.T) {
+	m := &attrMetrics{}
+	s := NewSignaller(WithMetrics(m), WithHistory(4))
+	events, cancel := s.Subscribe()
+	defer cancel()
+
+	s.TriggerSoftStopWith("deploy_id", "d-123")
+	s.TriggerSoftStopWith("ignored", true)
+	s.TriggerHardStopWith(slog.Int("incident", 42))
+
+	deploy := slog.String("deploy_id", "d-123")
+	incident := slog.Int("incident", 42)
+	assert.Equal(t, []slog.Attr{deploy, incident}, s.StopAttrs())
+
+	assert.Equal(t, []slog.Attr{deploy}, (<-events).Attrs)
+	assert.Equal(t, []slog.Attr{deploy, incident}, (<-events).Attrs)
+
+	assert.Equal(t, [][]slog.Attr{{deploy}, {deploy, incident}}, m.attrs)
+	assert.Empty(t, m.signals)
+
+	h := s.History()
+	require.Len(t, h, 2)
+	assert.Equal(t, []slog.Attr{deploy, incident}, h[1].Attrs)
+
+	d := s.Diagnostics()
+	assert.Equal(t, []slog.Attr{deploy, incident}, d.Attrs)
+
+	var buf bytes.Buffer
+	_, err := d.WriteTo(&buf)
+	require.NoError(t, err)
+	assert.Contains(t, buf.String(), "  attributes:\n    deploy_id=d-123\n    incident=42\n")
+}
+
+func TestTriggerWithAttrsLogged(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}))
+	s := NewSignaller(WithLogger(logger))
+	s.TriggerSoftStopWith("deploy_id", "d-123")
+
+	assert.Equal(t, "level=INFO msg=\"shutdown signalled\" tier=\"soft stop\" deploy_id=d-123\n", buf.String())
+}
+
+func TestTriggerWithAttrsNil(t *testing.T) {
+	var s *Signaller
+	s.TriggerSoftStopWith("foo", "bar")
+	assert.Nil(t, s.StopAttrs())
+
+	s = NewSignaller()
+	s.TriggerSoftStop()
+	assert.Nil(t, s.StopAttrs())
+}
@@ -0,0 +1,186 @@
+package shutdown
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// BarrierTransport delivers confirmations from remote participants of a
+// Barrier, over a transport of the choosing of the application. Listen is
+// called once by Barrier.Run and should call confirm with the name of each
+// participant that confirms, until the context is cancelled.
+type BarrierTransport interface {
+	Listen(ctx context.Context, confirm func(participant string)) error
+}
+
+// BarrierMissingError is recorded against a signaller when its Barrier is
+// abandoned before every participant has confirmed, and names those that did
+// not.
+type BarrierMissingError struct {
+	Missing []string
+}
+
+// Error returns a description of the missing participants.
+func (e *BarrierMissingError) Error() string {
+	return fmt.Sprintf("stop barrier abandoned without confirmation from: %v", strings.Join(e.Missing, ", "))
+}
+
+// Barrier gates the has stopped tier of a signaller on confirmations from a
+// set of named participants, such as replicas that must flush before a
+// component finishes stopping. Participants confirm in process with Confirm,
+// or remotely through a BarrierTransport.
+//
+// The barrier does not trigger the signaller itself. While it is waiting a call
+// to TriggerHasStopped by the component is deferred, and is carried out once
+// every participant has confirmed or the wait is abandoned by Run.
+type Barrier struct {
+	s *Signaller
+
+	mut       sync.Mutex
+	missing   map[string]struct{}
+	confirmed chan struct{}
+	released  bool
+}
+
+// NewBarrier creates a barrier for the signaller that waits for a confirmation
+// from each of the named participants, gating the has stopped tier of the
+// signaller from then on.
+func NewBarrier(s *Signaller, participants ...string) *Barrier {
+	b := &Barrier{
+		s:         s,
+		missing:   map[string]struct{}{},
+		confirmed: make(chan struct{}),
+	}
+	for _, p := range participants {
+		b.missing[p] = struct{}{}
+	}
+	if len(b.missing) == 0 || s == nil {
+		close(b.confirmed)
+		b.released = true
+		return b
+	}
+	s.extra().barriers.Add(1)
+	return b
+}
+
+// release stops the barrier from gating the has stopped tier, and returns true
+// if this was the last gate of a deferred TriggerHasStopped, which the caller
+// must then carry out once the mutex is unlocked. It must be called with the
+// mutex held.
+func (b *Barrier) release() bool {
+	if b.released {
+		return false
+	}
+	b.released = true
+	x := b.s.extra()
+	return x.barriers.Add(-1) == 0 && x.stopDeferred.Load()
+}
+
+// Confirm records the confirmation of a participant. Confirmations from
+// participants that are unknown to the barrier, or that have already
+// confirmed, are ignored.
+func (b *Barrier) Confirm(participant string) {
+	b.mut.Lock()
+	if _, ok := b.missing[participant]; !ok {
+		b.mut.Unlock()
+		return
+	}
+	delete(b.missing, participant)
+	var stop bool
+	if len(b.missing) == 0 {
+		close(b.confirmed)
+		stop = b.release()
+	}
+	b.mut.Unlock()
+
+	if stop {
+		b.s.TriggerHasStopped()
+	}
+}
+
+// Missing returns the names of the participants that have not yet confirmed,
+// sorted.
+func (b *Barrier) Missing() []string {
+	b.mut.Lock()
+	names := make([]string, 0, len(b.missing))
+	for p := range b.missing {
+		names = append(names, p)
+	}
+	b.mut.Unlock()
+
+	sort.Strings(names)
+	return names
+}
+
+// Run blocks until a soft stop is signalled and then waits for every
+// participant to confirm, listening for remote confirmations with the
+// transport when one is provided. The wait is abandoned once a hard stop is
+// signalled or the context is cancelled, such as by a deadline, in which case
+// the barrier stops gating the has stopped tier and a *BarrierMissingError
+// naming the participants that did not confirm is recorded against the
+// signaller and returned.
+//
+// If the transport fails before the wait is over then its error is also
+// recorded against the signaller and returned, and the wait continues for
+// participants that confirm in process.
+func (b *Barrier) Run(ctx context.Context, transport BarrierTransport) error {
+	defer func() {
+		b.mut.Lock()
+		stop := b.release()
+		b.mut.Unlock()
+		if stop {
+			b.s.TriggerHasStopped()
+		}
+	}()
+
+	select {
+	case <-b.s.SoftStopChan():
+	case <-ctx.Done():
+	}
+
+	ctx, done := b.s.HardStopCtx(ctx)
+	defer done()
+
+	var (
+		listenErr chan error
+		errs      []error
+	)
+	if transport != nil {
+		listenCtx, stopListening := context.WithCancel(ctx)
+		defer stopListening()
+
+		listenErr = make(chan error, 1)
+		go func() {
+			listenErr <- transport.Listen(listenCtx, b.Confirm)
+		}()
+	}
+
+wait:
+	for {
+		select {
+		case <-b.confirmed:
+			return errors.Join(errs...)
+		case err := <-listenErr:
+			listenErr = nil
+			if err != nil && ctx.Err() == nil {
+				err = fmt.Errorf("stop barrier transport: %w", err)
+				b.s.RecordStopErr(err)
+				errs = append(errs, err)
+			}
+		case <-ctx.Done():
+			break wait
+		}
+	}
+
+	missing := b.Missing()
+	if len(missing) == 0 {
+		return errors.Join(errs...)
+	}
+	err := &BarrierMissingError{Missing: missing}
+	b.s.RecordStopErr(err)
+	return errors.Join(append(errs, err)...)
+}
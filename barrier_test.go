@@ -0,0 +1,185 @@
+package shutdown
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type chanBarrierTransport chan string
+
+func (c chanBarrierTransport) Listen(ctx context.Context, confirm func(participant string)) error {
+	for {
+		select {
+		case p := <-c:
+			confirm(p)
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func runBarrier(ctx context.Context, b *Barrier, transport BarrierTransport) <-chan error {
+	errC := make(chan error, 1)
+	go func() {
+		errC <- b.Run(ctx, transport)
+	}()
+	return errC
+}
+
+func TestBarrier(t *testing.T) {
+	s := NewSignaller()
+	b := NewBarrier(s, "replica-a", "replica-b", "local")
+
+	remote := make(chanBarrierTransport)
+	errC := runBarrier(context.Background(), b, remote)
+
+	b.Confirm("local")
+	b.Confirm("unknown")
+	assert.Equal(t, []string{"replica-a", "replica-b"}, b.Missing())
+
+	s.TriggerSoftStop()
+	remote <- "replica-b"
+	s.TriggerHasStopped()
+	assertOpen(t, s.HasStoppedChan())
+
+	remote <- "replica-a"
+	require.NoError(t, <-errC)
+	assert.Empty(t, b.Missing())
+	assertClosed(t, s.HasStoppedChan())
+}
+
+func TestBarrierWaitsForComponent(t *testing.T) {
+	s := NewSignaller()
+	b := NewBarrier(s, "replica-a")
+
+	errC := runBarrier(context.Background(), b, nil)
+	s.TriggerSoftStop()
+	b.Confirm("replica-a")
+	require.NoError(t, <-errC)
+
+	// The barrier only gates the component, which has not stopped yet.
+	assertOpen(t, s.HasStoppedChan())
+
+	s.TriggerHasStopped()
+	assertClosed(t, s.HasStoppedChan())
+}
+
+func TestBarrierGoroutineGate(t *testing.T) {
+	s := NewSignaller(WithGoroutineGate())
+	b := NewBarrier(s, "replica-a")
+
+	release := make(chan struct{})
+	s.Go(func(ctx context.Context) {
+		<-release
+	})
+	s.TriggerSoftStop()
+	s.TriggerHasStopped()
+
+	b.Confirm("replica-a")
+	assertOpen(t, s.HasStoppedChan())
+
+	close(release)
+	select {
+	case <-s.HasStoppedChan():
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for has stopped")
+	}
+}
+
+func TestBarrierCancelledBeforeStop(t *testing.T) {
+	s := NewSignaller()
+	b := NewBarrier(s, "replica-a")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	var missing *BarrierMissingError
+	require.ErrorAs(t, b.Run(ctx, nil), &missing)
+	assertOpen(t, s.SoftStopChan())
+	assertOpen(t, s.HasStoppedChan())
+}
+
+type failingBarrierTransport struct{}
+
+func (failingBarrierTransport) Listen(ctx context.Context, confirm func(participant string)) error {
+	return errors.New("connection refused")
+}
+
+func TestBarrierTransportError(t *testing.T) {
+	s := NewSignaller()
+	b := NewBarrier(s, "replica-a")
+	s.TriggerSoftStop()
+
+	errC := runBarrier(context.Background(), b, failingBarrierTransport{})
+	assert.Eventually(t, func() bool {
+		return s.StopErr() != nil
+	}, time.Second, time.Millisecond)
+	assertOpen(t, s.HasStoppedChan())
+
+	// Participants may still confirm in process once the transport fails.
+	b.Confirm("replica-a")
+	err := <-errC
+	assert.EqualError(t, err, "stop barrier transport: connection refused")
+	assert.ErrorContains(t, s.StopErr(), "stop barrier transport: connection refused")
+
+	s = NewSignaller()
+	b = NewBarrier(s, "replica-a")
+	s.TriggerSoftStop()
+
+	errC = runBarrier(context.Background(), b, failingBarrierTransport{})
+	assert.Eventually(t, func() bool {
+		return s.StopErr() != nil
+	}, time.Second, time.Millisecond)
+	s.TriggerHardStop()
+
+	var missing *BarrierMissingError
+	err = <-errC
+	require.ErrorAs(t, err, &missing)
+	assert.ErrorContains(t, err, "stop barrier transport: connection refused")
+}
+
+func TestBarrierDeadline(t *testing.T) {
+	s := NewSignaller()
+	b := NewBarrier(s, "replica-a", "replica-b")
+	b.Confirm("replica-a")
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*10)
+	defer cancel()
+	s.TriggerSoftStop()
+
+	s.TriggerHasStopped()
+	assertOpen(t, s.HasStoppedChan())
+
+	var missing *BarrierMissingError
+	require.ErrorAs(t, b.Run(ctx, nil), &missing)
+	assert.Equal(t, []string{"replica-b"}, missing.Missing)
+	assert.ErrorAs(t, s.StopErr(), &missing)
+	assertClosed(t, s.HasStoppedChan())
+}
+
+func TestBarrierHardStop(t *testing.T) {
+	s := NewSignaller()
+	b := NewBarrier(s, "replica-a")
+
+	errC := runBarrier(context.Background(), b, nil)
+	s.TriggerHardStop()
+
+	var missing *BarrierMissingError
+	require.ErrorAs(t, <-errC, &missing)
+	assert.EqualError(t, missing, "stop barrier abandoned without confirmation from: replica-a")
+}
+
+func TestBarrierNoParticipants(t *testing.T) {
+	s := NewSignaller()
+	s.TriggerSoftStop()
+	require.NoError(t, NewBarrier(s).Run(context.Background(), nil))
+	assertOpen(t, s.HasStoppedChan())
+
+	s.TriggerHasStopped()
+	assertClosed(t, s.HasStoppedChan())
+}
@@ -0,0 +1,190 @@
+package shutdown
+
+import (
+	"reflect"
+	"slices"
+	"sync"
+)
+
+// BindTrigger causes the tier to be triggered once the channel is closed, or
+// receives a value, which suits libraries that only expose a done or closed
+// channel of their own. Triggers made by a binding are attributed to
+// SourceParentContext.
+//
+// The bindings of a signaller are observed together by a single goroutine,
+// rather than one each, which exits once no bindings remain. A binding ends
+// once its channel fires, its tier is signalled by other means, or the
+// returned function is called, which reports whether it ended the binding
+// before it fired.
+func (s *Signaller) BindTrigger(t Tier, ch <-chan struct{}) (stop func() bool) {
+	if s == nil || t < TierSoftStop || t > TierHasStopped {
+		return func() bool { return true }
+	}
+	if s.state.Load()&t.bit() != 0 {
+		return func() bool { return false }
+	}
+	x, b := s.extra(), &binding{tier: t, ch: ch}
+	x.binder.add(s, b)
+
+	var (
+		once   sync.Once
+		before bool
+	)
+	return func() bool {
+		once.Do(func() {
+			before = x.binder.remove(b) && s.state.Load()&t.bit() == 0
+		})
+		return before
+	}
+}
+
+// binding is a channel bound to a tier with BindTrigger.
+type binding struct {
+	tier Tier
+	ch   <-chan struct{}
+}
+
+// binder observes the bindings of a signaller from a single goroutine.
+type binder struct {
+	mut      sync.Mutex
+	bindings []*binding
+	running  bool
+	update   chan struct{}
+}
+
+// add registers a binding, starting the goroutine of the binder if it is not
+// already running.
+func (b *binder) add(s *Signaller, bound *binding) {
+	b.mut.Lock()
+	defer b.mut.Unlock()
+
+	b.bindings = append(b.bindings, bound)
+	if b.update == nil {
+		b.update = make(chan struct{}, 1)
+	}
+	if !b.running {
+		b.running = true
+		go b.run(s)
+		return
+	}
+	b.wake()
+}
+
+// remove deregisters a binding, returning false if it had already ended.
+func (b *binder) remove(bound *binding) bool {
+	b.mut.Lock()
+	defer b.mut.Unlock()
+
+	i := slices.Index(b.bindings, bound)
+	if i < 0 {
+		return false
+	}
+	b.bindings = slices.Delete(b.bindings, i, i+1)
+	b.wake()
+	return true
+}
+
+// wake causes the goroutine of the binder to observe the current bindings,
+// the mutex must be held.
+func (b *binder) wake() {
+	select {
+	case b.update <- struct{}{}:
+	default:
+	}
+}
+
+// run observes the channels of the bindings, and of the tiers they are bound
+// to, until no bindings remain.
+func (b *binder) run(s *Signaller) {
+	for {
+		b.mut.Lock()
+		if len(b.bindings) == 0 {
+			b.running = false
+			b.mut.Unlock()
+			return
+		}
+		bindings := slices.Clone(b.bindings)
+		b.mut.Unlock()
+
+		// The first cases are the update channel and the channels of the
+		// three tiers, followed by the channel of each binding.
+		cases := make([]reflect.SelectCase, 0, 4+len(bindings))
+		cases = append(cases, reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(b.update)})
+		for t := TierSoftStop; t <= TierHasStopped; t++ {
+			var c <-chan struct{}
+			for _, bound := range bindings {
+				if bound.tier == t {
+					c = s.tierChan(t)
+					break
+				}
+			}
+			cases = append(cases, reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(c)})
+		}
+		for _, bound := range bindings {
+			cases = append(cases, reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(bound.ch)})
+		}
+
+		chosen, _, _ := reflect.Select(cases)
+		switch {
+		case chosen == 0:
+		case chosen <= 3:
+			// The tier was signalled by other means, ending its bindings.
+			t := Tier(chosen - 1)
+			b.mut.Lock()
+			b.bindings = slices.DeleteFunc(b.bindings, func(bound *binding) bool {
+				return bound.tier == t
+			})
+			b.mut.Unlock()
+		default:
+			bound := bindings[chosen-4]
+			if b.remove(bound) {
+				s.triggerBound(bound.tier)
+			}
+		}
+	}
+}
+
+// triggerBound triggers a tier on behalf of a binding.
+func (s *Signaller) triggerBound(t Tier) {
+	switch t {
+	case TierSoftStop:
+		s.TriggerSoftStopFrom(SourceParentContext)
+	case TierHardStop:
+		s.TriggerHardStopFrom(SourceParentContext)
+	default:
+		s.TriggerHasStopped()
+	}
+}
+
+// mirror closes a channel owned by the caller once a tier is signalled.
+type mirror struct {
+	ch chan<- struct{}
+	w  waiter
+}
+
+func (m *mirror) notify() {
+	close(m.ch)
+}
+
+// MirrorInto closes the channel, which is owned by the caller, once the tier is
+// signalled, so that legacy APIs accepting a channel that is closed to stop
+// them can be driven by the signaller. No goroutine is created to observe the
+// tier, and the channel is closed immediately if the tier has already been
+// signalled. The channel must not be closed by anything else.
+//
+// The returned function cancels the mirror, and reports whether it did so
+// before the channel was closed, in which case the channel is left open.
+func (s *Signaller) MirrorInto(t Tier, ch chan<- struct{}) (stop func() bool) {
+	if s == nil {
+		return func() bool { return true }
+	}
+	m := &mirror{ch: ch}
+	m.w.n = m
+	if !s.addWaiter(t, &m.w) {
+		close(ch)
+		return func() bool { return false }
+	}
+	return func() bool {
+		return s.removeWaiter(&m.w)
+	}
+}
@@ -0,0 +1,109 @@
+package shutdown
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBindTrigger(t *testing.T) {
+	s := NewSignaller(WithName("bound"))
+	closed := make(chan struct{})
+	s.BindTrigger(TierHardStop, closed)
+
+	assertOpen(t, s.SoftStopChan())
+	close(closed)
+	assertClosed(t, s.HardStopChan())
+	assertClosed(t, s.SoftStopChan())
+}
+
+func TestBindTriggerStop(t *testing.T) {
+	s := NewSignaller()
+	closed := make(chan struct{})
+	stop := s.BindTrigger(TierSoftStop, closed)
+
+	assert.True(t, stop())
+	assert.True(t, stop())
+	close(closed)
+	assertOpen(t, s.SoftStopChan())
+}
+
+func TestBindTriggerFired(t *testing.T) {
+	s := NewSignaller()
+	closed := make(chan struct{})
+	stop := s.BindTrigger(TierHasStopped, closed)
+
+	close(closed)
+	assertClosed(t, s.HasStoppedChan())
+	assert.False(t, stop())
+}
+
+func TestBindTriggerSignalled(t *testing.T) {
+	s := NewSignaller()
+	stop := s.BindTrigger(TierSoftStop, make(chan struct{}))
+
+	// The binding exits once the tier is signalled by other means.
+	s.TriggerSoftStop()
+	assert.False(t, stop())
+}
+
+func TestBindTriggerMany(t *testing.T) {
+	s := NewSignaller()
+	soft, hard := make(chan struct{}), make(chan struct{})
+	stops := []func() bool{
+		s.BindTrigger(TierSoftStop, soft),
+		s.BindTrigger(TierHardStop, hard),
+		s.BindTrigger(TierHasStopped, make(chan struct{})),
+	}
+
+	close(soft)
+	assertClosed(t, s.SoftStopChan())
+	assertOpen(t, s.HardStopChan())
+	assert.False(t, stops[0]())
+
+	assert.True(t, stops[2]())
+	close(hard)
+	assertClosed(t, s.HardStopChan())
+	assert.False(t, stops[1]())
+	assertOpen(t, s.HasStoppedChan())
+}
+
+func TestBindTriggerNil(t *testing.T) {
+	var s *Signaller
+	stop := s.BindTrigger(TierSoftStop, make(chan struct{}))
+	assert.True(t, stop())
+}
+
+func TestMirrorInto(t *testing.T) {
+	s := NewSignaller()
+	soft, hard := make(chan struct{}), make(chan struct{})
+	s.MirrorInto(TierSoftStop, soft)
+	stop := s.MirrorInto(TierHardStop, hard)
+
+	s.TriggerSoftStop()
+	assertClosed(t, soft)
+	assertOpen(t, hard)
+
+	assert.True(t, stop())
+	assert.False(t, stop())
+	s.TriggerHardStop()
+	assertOpen(t, hard)
+}
+
+func TestMirrorIntoSignalled(t *testing.T) {
+	s := NewSignaller()
+	s.TriggerHardStop()
+
+	ch := make(chan struct{})
+	stop := s.MirrorInto(TierSoftStop, ch)
+	assertClosed(t, ch)
+	assert.False(t, stop())
+}
+
+func TestMirrorIntoNil(t *testing.T) {
+	var s *Signaller
+	ch := make(chan struct{})
+	stop := s.MirrorInto(TierSoftStop, ch)
+	assertOpen(t, ch)
+	assert.True(t, stop())
+}
@@ -0,0 +1,238 @@
+package shutdown
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Builder assembles the shutdown machinery of a program, which is an OS signal
+// listener, the escalation from soft to hard stop and the components that are
+// stopped by them, into a single call to Run. Builders are created with New:
+//
+//	err := shutdown.New().
+//		WithSignals().
+//		WithGrace(20 * time.Second).
+//		WithHTTPServer(srv).
+//		Run(ctx)
+type Builder struct {
+	opts       []Option
+	components []builderComponent
+	graces     map[string]time.Duration
+
+	// The components of the program, by name, while Run is in progress.
+	mut     sync.Mutex
+	running map[string]*runningComponent
+}
+
+type builderComponent struct {
+	name string
+	run  func(s *Signaller) error
+	opts []Option
+}
+
+// New creates a Builder.
+func New() *Builder {
+	return &Builder{}
+}
+
+// WithSignals causes Run to listen for the provided OS signals, or SIGINT and
+// SIGTERM when none are provided, where the first signal triggers a soft stop
+// and any further signal a hard stop.
+func (b *Builder) WithSignals(sigs ...os.Signal) *Builder {
+	if len(sigs) == 0 {
+		sigs = defaultSignals
+	}
+	b.opts = append(b.opts, WithSignals(sigs...))
+	return b
+}
+
+// WithGrace sets the duration after a soft stop at which a hard stop is
+// triggered, see WithHardStopGrace.
+func (b *Builder) WithGrace(grace time.Duration) *Builder {
+	b.opts = append(b.opts, WithHardStopGrace(grace))
+	return b
+}
+
+// WithEscalationPolicy sets a schedule of steps taken once a soft stop has been
+// signalled, see WithEscalationPolicy.
+func (b *Builder) WithEscalationPolicy(p EscalationPolicy) *Builder {
+	b.opts = append(b.opts, WithEscalationPolicy(p))
+	return b
+}
+
+// WithHardTimeout sets the maximum duration that Run waits for components to
+// stop once a hard stop has been signalled, see WithCloseTimeout.
+func (b *Builder) WithHardTimeout(timeout time.Duration) *Builder {
+	b.opts = append(b.opts, WithCloseTimeout(timeout))
+	return b
+}
+
+// WithOptions adds options to the signaller of the program.
+func (b *Builder) WithOptions(opts ...Option) *Builder {
+	b.opts = append(b.opts, opts...)
+	return b
+}
+
+// WithComponent adds a component to be run by Run. The component is provided
+// a signaller of its own, which is triggered along with the signaller of the
+// program, and it is expected to return once it has stopped. A component that
+// returns an error causes the program to soft stop. Options configure the
+// signaller of the component, such as WithWatchdog for a stop deadline of its
+// own.
+func (b *Builder) WithComponent(name string, run func(s *Signaller) error, opts ...Option) *Builder {
+	b.components = append(b.components, builderComponent{name: name, run: run, opts: opts})
+	return b
+}
+
+// WithComponentGrace overrides the grace of the named component, which is the
+// duration after the soft stop of the component at which it is hard stopped,
+// in place of the grace of the program configured with WithGrace. A component
+// with an override is not hard stopped once the grace of the program elapses,
+// and so it may be given longer to drain than the rest of the program, such as
+// a log writer that must flush, or shorter, such as a cache that may be dropped
+// instantly with a grace of zero. Hard stops of the program that are triggered
+// explicitly, such as by a second OS signal, are still propagated to it, and
+// the hard timeout of the program still applies.
+//
+// The effective hard stop deadline of the component is reported through the
+// Deadline method of contexts derived from its signaller once it is soft
+// stopped, and by its HardStopDeadline.
+func (b *Builder) WithComponentGrace(name string, grace time.Duration) *Builder {
+	if b.graces == nil {
+		b.graces = map[string]time.Duration{}
+	}
+	b.graces[name] = grace
+	return b
+}
+
+// WithHTTPServer adds an HTTP server as a component, which is run with
+// ServeHTTP on the address of the server.
+func (b *Builder) WithHTTPServer(srv *http.Server) *Builder {
+	return b.WithComponent("http_server "+srv.Addr, func(s *Signaller) error {
+		return ServeHTTP(s, srv, nil)
+	})
+}
+
+// Run starts each component and blocks until they have all stopped. A soft stop
+// is triggered when the provided context is cancelled, when a configured OS
+// signal is received or when a component fails. Returns the errors of any
+// failed components along with those recorded against the signaller of the
+// program, or a *PendingError naming the components that did not stop within
+// the hard timeout after a hard stop.
+func (b *Builder) Run(ctx context.Context) error {
+	s := NewSignaller(b.opts...)
+	defer s.triggerHasStopped(cause{}, true)
+
+	stopCtx := context.AfterFunc(ctx, func() {
+		s.TriggerSoftStopFrom(SourceParentContext)
+	})
+	defer stopCtx()
+
+	var (
+		wg      sync.WaitGroup
+		errMut  sync.Mutex
+		errs    []error
+		running = make([]*runningComponent, len(b.components))
+	)
+	for i, c := range b.components {
+		running[i] = &runningComponent{c: c, program: s}
+		if grace, ok := b.graces[c.name]; ok {
+			running[i].grace = &grace
+		}
+		running[i].m = running[i].newMember()
+	}
+	b.mut.Lock()
+	b.running = map[string]*runningComponent{}
+	for _, rc := range running {
+		b.running[rc.c.name] = rc
+	}
+	b.mut.Unlock()
+	defer func() {
+		b.mut.Lock()
+		b.running = nil
+		b.mut.Unlock()
+	}()
+
+	members := func() []*Signaller {
+		members := make([]*Signaller, len(running))
+		for i, rc := range running {
+			members[i] = rc.current()
+		}
+		return members
+	}
+	s.AddDiagnostics("components", func() string {
+		var b strings.Builder
+		for _, m := range members() {
+			fmt.Fprintf(&b, "%v: %v\n", m.Name(), lifecycleStateName(m))
+		}
+		return b.String()
+	})
+	var softAt atomic.Int64
+	s.OnSoftStop(func() {
+		softAt.Store(time.Now().UnixNano())
+		for _, rc := range running {
+			rc.softStop(rc.current())
+		}
+	})
+	s.OnHardStop(func() {
+		for _, rc := range running {
+			if rc.followsHardStop() {
+				rc.current().TriggerHardStopFrom(SourceParentContext)
+			}
+		}
+	})
+
+	for _, rc := range running {
+		wg.Add(1)
+		go func(rc *runningComponent) {
+			defer wg.Done()
+			if err := rc.run(); err != nil {
+				errMut.Lock()
+				errs = append(errs, fmt.Errorf("%v: %w", rc.c.name, err))
+				errMut.Unlock()
+				s.TriggerSoftStop()
+			}
+		}(rc)
+	}
+
+	finished := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(finished)
+	}()
+
+	select {
+	case <-finished:
+	case <-s.HardStopChan():
+		var timeout <-chan time.Time
+		if d := s.config().closeTimeout; d > 0 {
+			t := time.NewTimer(d)
+			defer t.Stop()
+			timeout = t.C
+		}
+		select {
+		case <-finished:
+		case <-timeout:
+			var draining time.Duration
+			if at := softAt.Load(); at != 0 {
+				draining = time.Since(time.Unix(0, at))
+			}
+			return &PendingError{
+				Pending:  pendingNames(members()),
+				Draining: draining,
+				Err:      fmt.Errorf("components: %w", ErrStopTimeout),
+			}
+		}
+	}
+
+	errMut.Lock()
+	defer errMut.Unlock()
+	return errors.Join(append(errs, s.StopErr())...)
+}
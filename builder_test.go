@@ -0,0 +1,189 @@
+package shutdown
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuilderRunContext(t *testing.T) {
+	ln := listenLocal(t)
+	srv := &http.Server{Handler: http.NotFoundHandler()}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var stopped []string
+
+	errC := make(chan error, 1)
+	go func() {
+		errC <- New().
+			WithGrace(time.Minute).
+			WithComponent("http", func(s *Signaller) error {
+				return ServeHTTP(s, srv, ln)
+			}).
+			WithComponent("worker", func(s *Signaller) error {
+				<-s.SoftStopChan()
+				stopped = append(stopped, s.Name())
+				return nil
+			}).
+			Run(ctx)
+	}()
+
+	res, err := http.Get("http://" + ln.Addr().String())
+	require.NoError(t, err)
+	res.Body.Close()
+
+	cancel()
+	require.NoError(t, <-errC)
+	assert.Equal(t, []string{"worker"}, stopped)
+}
+
+func TestBuilderRunComponentFailure(t *testing.T) {
+	errNope := errors.New("nope")
+
+	err := New().
+		WithComponent("failing", func(s *Signaller) error {
+			return errNope
+		}).
+		WithComponent("waiting", func(s *Signaller) error {
+			<-s.SoftStopChan()
+			return nil
+		}).
+		Run(context.Background())
+	assert.ErrorIs(t, err, errNope)
+	assert.EqualError(t, err, "failing: nope")
+}
+
+func TestBuilderRunHardTimeout(t *testing.T) {
+	block := make(chan struct{})
+	defer close(block)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := New().
+		WithGrace(time.Millisecond).
+		WithHardTimeout(time.Millisecond*10).
+		WithComponent("stuck", func(s *Signaller) error {
+			<-block
+			return nil
+		}).
+		WithComponent("quick", func(s *Signaller) error {
+			<-s.HardStopChan()
+			return nil
+		}).
+		Run(ctx)
+	assert.ErrorIs(t, err, ErrStopTimeout)
+	assert.Regexp(t, `^components: timed out waiting to stop after draining for \d+ms, pending: stuck$`, err.Error())
+
+	var pending *PendingError
+	require.ErrorAs(t, err, &pending)
+	assert.Equal(t, []string{"stuck"}, pending.Pending)
+}
+
+func TestBuilderComponentWatchdog(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	overran := make(chan string, 1)
+	err := New().
+		WithComponent("slow", func(s *Signaller) error {
+			<-s.HardStopChan()
+			return nil
+		}, WithWatchdog(time.Millisecond, func(s *Signaller) {
+			overran <- s.Name()
+			s.TriggerHardStop()
+		})).
+		WithComponent("fast", func(s *Signaller) error {
+			<-s.SoftStopChan()
+			return nil
+		}).
+		Run(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "slow", <-overran)
+}
+
+func TestBuilderComponentGrace(t *testing.T) {
+	members := make(chan *Signaller, 3)
+	release := make(chan struct{})
+	component := func(s *Signaller) error {
+		members <- s
+		<-s.SoftStopChan()
+		<-release
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errC := make(chan error, 1)
+	go func() {
+		errC <- New().
+			WithGrace(time.Millisecond).
+			WithComponent("api", component).
+			WithComponent("cache", component).
+			WithComponent("wal", component).
+			WithComponentGrace("cache", 0).
+			WithComponentGrace("wal", time.Minute).
+			Run(ctx)
+	}()
+
+	byName := map[string]*Signaller{}
+	for i := 0; i < 3; i++ {
+		m := <-members
+		byName[m.Name()] = m
+	}
+
+	cancel()
+	assertClosed(t, byName["cache"].HardStopChan())
+	assertClosed(t, byName["api"].HardStopChan())
+
+	// The program has hard stopped but the wal has a grace of its own.
+	wal := byName["wal"]
+	assertClosed(t, wal.SoftStopChan())
+	assertOpen(t, wal.HardStopChan())
+	deadline, ok := wal.HardStopDeadline()
+	require.True(t, ok)
+	assert.WithinDuration(t, time.Now().Add(time.Minute), deadline, time.Second*5)
+
+	hardCtx, done := wal.HardStopCtx(context.Background())
+	defer done()
+	ctxDeadline, ok := hardCtx.Deadline()
+	require.True(t, ok)
+	assert.Equal(t, deadline, ctxDeadline)
+
+	close(release)
+	require.NoError(t, <-errC)
+}
+
+func TestBuilderComponentGraceExplicitHardStop(t *testing.T) {
+	members := make(chan *Signaller, 1)
+	b := New().
+		WithGrace(time.Hour).
+		WithComponent("wal", func(s *Signaller) error {
+			members <- s
+			<-s.HardStopChan()
+			return nil
+		}).
+		WithComponentGrace("wal", time.Hour)
+
+	errC := make(chan error, 1)
+	go func() {
+		errC <- b.Run(context.Background())
+	}()
+	wal := <-members
+
+	b.mut.Lock()
+	program := b.running["wal"].program
+	b.mut.Unlock()
+
+	// An explicit hard stop of the program is not one of its grace, and so
+	// it is propagated to the wal regardless of the grace of its own.
+	program.TriggerHardStop()
+	assertClosed(t, wal.HardStopChan())
+	src, _ := wal.TriggeredBy(TierHardStop)
+	assert.Equal(t, SourceParentContext, src)
+	require.NoError(t, <-errC)
+}
@@ -0,0 +1,95 @@
+package shutdown
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"sync"
+)
+
+// Cleanup is a registry of filesystem artifacts, such as temporary
+// directories, lock files and unix sockets, that are removed once a signaller
+// has stopped, so that they do not outlive the program and break its next
+// start.
+//
+// Artifacts are removed when the signaller is triggered as having stopped,
+// which follows a hard stop just as it follows a soft stop, and before the has
+// stopped tier is signalled, so that any errors of their removal are recorded
+// against the signaller by the time HasStoppedChan is closed. Programs that may
+// exit without their components having stopped, such as when Close times out,
+// should also defer a call to Clean.
+type Cleanup struct {
+	mut   sync.Mutex
+	paths []cleanupPath
+}
+
+type cleanupPath struct {
+	path string
+	all  bool
+}
+
+// NewCleanup creates a registry of artifacts that are removed once the provided
+// signaller has stopped.
+func NewCleanup(s *Signaller) *Cleanup {
+	c := &Cleanup{}
+	if s != nil {
+		s.onStopping(func() {
+			s.RecordStopErr(c.Clean())
+		})
+	}
+	return c
+}
+
+// File registers a file, such as a lock file or a unix socket, to be removed.
+func (c *Cleanup) File(path string) {
+	c.add(cleanupPath{path: path})
+}
+
+// Dir registers a directory to be removed along with everything it contains.
+func (c *Cleanup) Dir(path string) {
+	c.add(cleanupPath{path: path, all: true})
+}
+
+// TempDir creates a new temporary directory with os.MkdirTemp and registers it
+// to be removed.
+func (c *Cleanup) TempDir(dir, pattern string) (string, error) {
+	path, err := os.MkdirTemp(dir, pattern)
+	if err != nil {
+		return "", err
+	}
+	c.Dir(path)
+	return path, nil
+}
+
+func (c *Cleanup) add(p cleanupPath) {
+	c.mut.Lock()
+	c.paths = append(c.paths, p)
+	c.mut.Unlock()
+}
+
+// Clean removes each registered artifact, in the reverse order of their
+// registration, and returns the errors of any that could not be removed.
+// Artifacts that no longer exist are ignored. Each artifact is only removed
+// once, and so calling Clean again only removes artifacts registered since.
+func (c *Cleanup) Clean() error {
+	c.mut.Lock()
+	paths := c.paths
+	c.paths = nil
+	c.mut.Unlock()
+
+	var errs []error
+	for i := len(paths) - 1; i >= 0; i-- {
+		p := paths[i]
+		var err error
+		if p.all {
+			err = os.RemoveAll(p.path)
+		} else {
+			err = os.Remove(p.path)
+		}
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			errs = append(errs, fmt.Errorf("removing %v: %w", p.path, err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,66 @@
+package shutdown
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCleanup(t *testing.T) {
+	s := NewSignaller()
+	c := NewCleanup(s)
+
+	root := t.TempDir()
+	lock := filepath.Join(root, "app.lock")
+	require.NoError(t, os.WriteFile(lock, nil, 0o600))
+	c.File(lock)
+
+	dir, err := c.TempDir(root, "scratch-")
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "data"), []byte("hello"), 0o600))
+
+	c.File(filepath.Join(root, "missing.sock"))
+
+	s.TriggerHardStop()
+	assert.FileExists(t, lock)
+	assert.DirExists(t, dir)
+
+	s.TriggerHasStopped()
+	assert.NoFileExists(t, lock)
+	assert.NoDirExists(t, dir)
+	assert.NoError(t, s.StopErr())
+}
+
+func TestCleanupErrors(t *testing.T) {
+	c := NewCleanup(NewSignaller())
+
+	// Removing a non-empty directory as a file fails.
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "data"), nil, 0o600))
+	c.File(dir)
+
+	assert.ErrorContains(t, c.Clean(), "removing "+dir)
+	assert.NoError(t, c.Clean())
+}
+
+func TestCleanupErrorsBeforeHasStopped(t *testing.T) {
+	s := NewSignaller()
+	c := NewCleanup(s)
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "data"), nil, 0o600))
+	c.File(dir)
+
+	var errAtStop error
+	s.OnHasStopped(func() {
+		errAtStop = s.StopErr()
+	})
+	s.TriggerHardStop()
+	s.TriggerHasStopped()
+
+	assert.ErrorContains(t, errAtStop, "removing "+dir)
+	assert.ErrorContains(t, s.StopErr(), "removing "+dir)
+}
@@ -0,0 +1,33 @@
+package shutdown
+
+import "time"
+
+// Clock is a source of time for a Signaller, which drives the hard stop grace
+// period, context timeouts and the timestamps of events. Providing a Clock
+// with WithClock allows these to be controlled deterministically in tests.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+
+	// AfterFunc calls fn in its own goroutine once the duration has elapsed,
+	// and returns a Timer that can be used to cancel the call.
+	AfterFunc(d time.Duration, fn func()) Timer
+}
+
+// Timer is a scheduled call created by a Clock.
+type Timer interface {
+	// Stop prevents the call from being made, returning false if it has
+	// already been made or stopped.
+	Stop() bool
+}
+
+// systemClock is the Clock of signallers constructed without WithClock.
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (systemClock) AfterFunc(d time.Duration, fn func()) Timer {
+	return time.AfterFunc(d, fn)
+}
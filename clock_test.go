@@ -0,0 +1,124 @@
+package shutdown
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// manualClock is a Clock that only advances when told to.
+type manualClock struct {
+	mut    sync.Mutex
+	now    time.Time
+	timers []*manualTimer
+}
+
+type manualTimer struct {
+	c       *manualClock
+	at      time.Time
+	fn      func()
+	stopped bool
+}
+
+func newManualClock() *manualClock {
+	return &manualClock{now: time.Unix(1000, 0)}
+}
+
+func (c *manualClock) Now() time.Time {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	return c.now
+}
+
+func (c *manualClock) AfterFunc(d time.Duration, fn func()) Timer {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	t := &manualTimer{c: c, at: c.now.Add(d), fn: fn}
+	c.timers = append(c.timers, t)
+	return t
+}
+
+// Advance moves the clock forward and calls any timers that are due.
+func (c *manualClock) Advance(d time.Duration) {
+	c.mut.Lock()
+	c.now = c.now.Add(d)
+	var due []func()
+	pending := c.timers[:0]
+	for _, t := range c.timers {
+		if t.stopped {
+			continue
+		}
+		if !t.at.After(c.now) {
+			t.stopped = true
+			due = append(due, t.fn)
+			continue
+		}
+		pending = append(pending, t)
+	}
+	c.timers = pending
+	c.mut.Unlock()
+
+	for _, fn := range due {
+		fn()
+	}
+}
+
+func (t *manualTimer) Stop() bool {
+	t.c.mut.Lock()
+	defer t.c.mut.Unlock()
+	if t.stopped {
+		return false
+	}
+	t.stopped = true
+	return true
+}
+
+func TestWithClockEscalation(t *testing.T) {
+	clock := newManualClock()
+	s := NewSignaller(WithClock(clock), WithHardStopGrace(time.Minute))
+
+	s.TriggerSoftStop()
+	at, ok := s.HardStopDeadline()
+	assert.True(t, ok)
+	assert.Equal(t, clock.Now().Add(time.Minute), at)
+
+	clock.Advance(time.Second * 59)
+	assert.False(t, s.IsHardStopSignalled())
+
+	clock.Advance(time.Second)
+	assert.True(t, s.IsHardStopSignalled())
+}
+
+func TestWithClockCtxTimeout(t *testing.T) {
+	clock := newManualClock()
+	s := NewSignaller(WithClock(clock))
+
+	ctx, done := s.SoftStopCtxWithTimeout(context.Background(), time.Minute)
+	defer done()
+
+	deadline, ok := ctx.Deadline()
+	assert.True(t, ok)
+	assert.Equal(t, clock.Now().Add(time.Minute), deadline)
+
+	clock.Advance(time.Second * 30)
+	assertOpen(t, ctx.Done())
+
+	clock.Advance(time.Second * 30)
+	assertClosed(t, ctx.Done())
+	assert.Equal(t, context.DeadlineExceeded, ctx.Err())
+}
+
+func TestWithClockEvents(t *testing.T) {
+	clock := newManualClock()
+	s := NewSignaller(WithClock(clock))
+
+	events, cancel := s.Subscribe()
+	defer cancel()
+
+	s.TriggerSoftStop()
+	e := <-events
+	assert.Equal(t, clock.Now(), e.Time)
+}
@@ -0,0 +1,529 @@
+package shutdown
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+)
+
+// PooledCtx is a context.Context derived from a Signaller that is recycled once
+// released, and is intended for hot paths such as servers that derive a stop
+// aware context for each request they handle.
+//
+// A PooledCtx is obtained with one of the Acquire*Ctx methods of a Signaller
+// and must be returned with Release once the caller is finished with it. After
+// Release has been called the context, and any channel obtained from its Done
+// method, must no longer be used.
+type PooledCtx struct {
+	parent context.Context
+	sig    *Signaller
+	tier   Tier
+
+	// When the parent context can be cancelled we need a channel of our own
+	// that merges the two. This channel is reused between acquisitions for as
+	// long as it remains open.
+	merged     bool
+	mut        sync.Mutex
+	done       chan struct{}
+	err        error
+	stopParent func() bool
+
+	// Set when a hard stop scheduled at the time of acquisition is earlier than
+	// the deadline of the parent.
+	deadline time.Time
+
+	// The channel of the tier when the context is not merged, which is
+	// captured at acquisition so that the context is never un-cancelled by
+	// AbortSoftStop, along with err once it is observed to be closed.
+	sigDone <-chan struct{}
+
+	w        waiter
+	onParent func()
+
+	// Guarded by mut.
+	afterFuncs afterFuncs
+}
+
+var pooledCtxPool = sync.Pool{
+	New: func() any {
+		c := &PooledCtx{}
+		c.w.n = c
+		c.onParent = c.onParentDone
+		return c
+	},
+}
+
+func (s *Signaller) acquireCtx(ctx context.Context, t Tier) *PooledCtx {
+	c := pooledCtxPool.Get().(*PooledCtx)
+	c.parent, c.sig, c.tier = ctx, s, t
+	if s == nil {
+		c.merged = false
+		return c
+	}
+	s.trackCtx(c)
+	if at, ok := s.escalationDeadline(t); ok && beforeDeadline(ctx, at) {
+		c.deadline = at
+	}
+
+	if ctx.Done() == nil || s.state.Load()&t.bit() != 0 {
+		// Either the parent can never be cancelled or the signal has already
+		// been made, and therefore the signal channel can be used as our own.
+		c.merged = false
+		c.sigDone = s.tierChan(t)
+		return c
+	}
+
+	c.merged = true
+	if c.done == nil {
+		c.done = make(chan struct{})
+	}
+	if err := ctx.Err(); err != nil {
+		c.cancel(err)
+		return c
+	}
+	if !s.addWaiter(t, &c.w) {
+		c.cancel(context.Canceled)
+		return c
+	}
+	c.stopParent = context.AfterFunc(ctx, c.onParent)
+	return c
+}
+
+// AcquireSoftStopCtx returns a pooled context.Context that will be terminated
+// when either the provided context is cancelled or the signal to soft or hard
+// stop has been made. The context must be released with Release once it is no
+// longer needed.
+//
+// This is a cheaper alternative to SoftStopCtx for code that derives large
+// numbers of short lived contexts, as the returned context is recycled rather
+// than allocated, and no goroutine is created in order to observe it.
+func (s *Signaller) AcquireSoftStopCtx(ctx context.Context) *PooledCtx {
+	return s.acquireCtx(ctx, TierSoftStop)
+}
+
+// AcquireHardStopCtx returns a pooled context.Context that will be terminated
+// when either the provided context is cancelled or the signal to hard stop has
+// been made. The context must be released with Release once it is no longer
+// needed.
+func (s *Signaller) AcquireHardStopCtx(ctx context.Context) *PooledCtx {
+	return s.acquireCtx(ctx, TierHardStop)
+}
+
+// AcquireHasStoppedCtx returns a pooled context.Context that will be terminated
+// when either the provided context is cancelled or the signal that the
+// component has stopped has been made. The context must be released with
+// Release once it is no longer needed.
+func (s *Signaller) AcquireHasStoppedCtx(ctx context.Context) *PooledCtx {
+	return s.acquireCtx(ctx, TierHasStopped)
+}
+
+// Release returns the context to the pool. The context must not be used after
+// it has been released.
+func (c *PooledCtx) Release() {
+	c.sig.untrackCtx(c)
+	if c.merged {
+		// If either callback has already been called then it might still be
+		// running, in which case the context is abandoned rather than recycled.
+		stopped := c.stopParent == nil || c.stopParent()
+		if !c.sig.removeWaiter(&c.w) || !stopped {
+			return
+		}
+		c.mut.Lock()
+		pending := len(c.afterFuncs) > 0
+		c.mut.Unlock()
+		if pending {
+			// Functions registered with AfterFunc could otherwise be called
+			// by the next acquisition.
+			return
+		}
+		if c.err != nil {
+			// Closed channels cannot be reused.
+			c.done, c.err = nil, nil
+		}
+	} else {
+		c.sigDone, c.err = nil, nil
+	}
+	c.parent, c.sig, c.stopParent, c.deadline = nil, nil, nil, time.Time{}
+	pooledCtxPool.Put(c)
+}
+
+func (c *PooledCtx) cancel(err error) {
+	c.mut.Lock()
+	if c.err == nil {
+		c.err = err
+		close(c.done)
+		c.afterFuncs.call()
+	}
+	c.mut.Unlock()
+}
+
+func (c *PooledCtx) notify() {
+	c.cancel(c.sig.signalErr(c.tier, c.deadline))
+}
+
+func (c *PooledCtx) onParentDone() {
+	c.cancel(c.parent.Err())
+}
+
+// Deadline returns the deadline of the parent context, or the time of any hard
+// stop scheduled at the time of acquisition if it is earlier. As the deadline
+// of a context must not change it is not moved by RequestExtension, in which
+// case the context is cancelled after its deadline.
+func (c *PooledCtx) Deadline() (deadline time.Time, ok bool) {
+	if !c.deadline.IsZero() {
+		return c.deadline, true
+	}
+	return c.parent.Deadline()
+}
+
+// Done returns a channel that is closed when either the parent context is
+// cancelled or the signal has been made.
+func (c *PooledCtx) Done() <-chan struct{} {
+	if !c.merged {
+		if c.sig == nil {
+			return c.parent.Done()
+		}
+		return c.sigDone
+	}
+	return c.done
+}
+
+// Err returns context.Canceled if the signal has been made, or
+// context.DeadlineExceeded if it was made at or after the deadline of the
+// context, or the error of the parent context if it was cancelled first.
+func (c *PooledCtx) Err() error {
+	if !c.merged {
+		if c.sig == nil {
+			return c.parent.Err()
+		}
+		select {
+		case <-c.sigDone:
+		default:
+			return nil
+		}
+	}
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	if c.err == nil && !c.merged {
+		c.err = c.sig.signalErr(c.tier, c.deadline)
+	}
+	return c.err
+}
+
+// Value returns the value associated with the key from the parent context.
+func (c *PooledCtx) Value(key any) any {
+	return c.parent.Value(key)
+}
+
+// AfterFunc arranges to call f in its own goroutine once the context is done,
+// as context.AfterFunc does, and returns a function that stops the call,
+// reporting whether it did so before f was started. Contexts derived from a
+// PooledCtx with the context package, such as with context.WithTimeout, are
+// cancelled through this method rather than with a goroutine of their own.
+func (c *PooledCtx) AfterFunc(f func()) (stop func() bool) {
+	if !c.merged {
+		if c.sig == nil {
+			return context.AfterFunc(c.parent, f)
+		}
+		select {
+		case <-c.sigDone:
+			go f()
+			return func() bool { return false }
+		default:
+		}
+		return c.sig.afterTier(c.tier, f)
+	}
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	return c.afterFuncs.add(&c.mut, c.err != nil, f)
+}
+
+//------------------------------------------------------------------------------
+
+// closedChan is a channel that is always closed, and is used in place of
+// allocating a channel only to close it.
+var closedChan = func() chan struct{} {
+	c := make(chan struct{})
+	close(c)
+	return c
+}()
+
+// stopCtx is the context.Context returned by the *Ctx methods of a Signaller.
+// Rather than deriving a context with context.WithCancel and spawning a
+// goroutine that waits for the signal, the context registers itself with the
+// signaller directly and is merged with the parent via context.AfterFunc only
+// when the parent can actually be cancelled.
+type stopCtx struct {
+	parent context.Context
+	sig    *Signaller
+	tier   Tier
+	w      waiter
+
+	mut        sync.Mutex
+	done       chan struct{} // Created lazily
+	err        error
+	stopParent func() bool
+
+	// Set when a deadline earlier than that of the parent is configured.
+	deadline time.Time
+	timer    Timer
+
+	// Guarded by mut.
+	released   bool
+	afterFuncs afterFuncs
+}
+
+// cancelledCtx is returned by the *Ctx methods of a Signaller when the signal
+// has already been made.
+type cancelledCtx struct {
+	context.Context
+	deadline time.Time
+	err      error
+}
+
+func (c cancelledCtx) Deadline() (deadline time.Time, ok bool) {
+	if !c.deadline.IsZero() {
+		return c.deadline, true
+	}
+	return c.Context.Deadline()
+}
+
+func (cancelledCtx) Done() <-chan struct{} {
+	return closedChan
+}
+
+func (c cancelledCtx) Err() error {
+	return c.err
+}
+
+// signalErr returns the error of a context that was cancelled by the tier
+// being signalled, which is context.DeadlineExceeded when the tier was
+// signalled at or after the deadline of the context, as is the case for a hard
+// stop made by the escalation of the signaller, and otherwise
+// context.Canceled.
+func (s *Signaller) signalErr(t Tier, deadline time.Time) error {
+	if deadline.IsZero() {
+		return context.Canceled
+	}
+	at, ok := s.SignalledAt(t)
+	if !ok {
+		// Contexts are notified before the time of the signal is recorded.
+		at = s.clock().Now()
+	}
+	if at.Before(deadline) {
+		return context.Canceled
+	}
+	return context.DeadlineExceeded
+}
+
+// deriveCtx returns a context derived from the parent that is cancelled once
+// the tier is signalled, or once the timeout deadline is reached if non-zero.
+func (s *Signaller) deriveCtx(ctx context.Context, t Tier, timeout time.Time) (context.Context, context.CancelFunc) {
+	if s == nil {
+		if !timeout.IsZero() {
+			return context.WithDeadline(ctx, timeout)
+		}
+		return context.WithCancel(ctx)
+	}
+	if s.state.Load()&t.bit() != 0 {
+		// Fast path for when the signal has already been made, which is common
+		// once a shutdown is underway.
+		c := cancelledCtx{Context: ctx}
+		if at, ok := s.escalationDeadline(t); ok && beforeDeadline(ctx, at) {
+			c.deadline = at
+		}
+		c.err = s.signalErr(t, c.deadline)
+		return c, func() {}
+	}
+
+	c := &stopCtx{parent: ctx, sig: s, tier: t}
+	c.w.n = c
+
+	// The deadline is set before the context is registered, as it determines
+	// the error of the context once notified.
+	timed := !timeout.IsZero() && beforeDeadline(ctx, timeout)
+	if timed {
+		c.deadline = timeout
+	}
+	if at, ok := s.escalationDeadline(t); ok && beforeDeadline(c, at) {
+		// No timer is needed as the hard stop itself cancels the context.
+		c.deadline = at
+	}
+
+	if err := ctx.Err(); err != nil {
+		c.err = err
+		return c, func() {}
+	}
+	if !s.addWaiter(t, &c.w) {
+		c.err = s.signalErr(t, c.deadline)
+		return c, func() {}
+	}
+	if ctx.Done() != nil {
+		c.stopParent = context.AfterFunc(ctx, func() {
+			c.cancel(ctx.Err())
+		})
+	}
+	if timed {
+		clock := s.clock()
+		if d := timeout.Sub(clock.Now()); d <= 0 {
+			c.cancel(context.DeadlineExceeded)
+		} else {
+			c.timer = clock.AfterFunc(d, func() {
+				c.cancel(context.DeadlineExceeded)
+			})
+		}
+	}
+	s.trackCtx(c)
+	return c, c.release
+}
+
+func (c *stopCtx) cancel(err error) {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	if c.err != nil {
+		return
+	}
+	c.err = err
+	if c.done != nil {
+		close(c.done)
+	}
+	c.afterFuncs.call()
+}
+
+func (c *stopCtx) notify() {
+	c.cancel(c.sig.signalErr(c.tier, c.deadline))
+}
+
+func (c *stopCtx) release() {
+	c.mut.Lock()
+	released := c.released
+	c.released = true
+	c.mut.Unlock()
+	if !released {
+		c.sig.untrackCtx(c)
+	}
+
+	c.sig.removeWaiter(&c.w)
+	if c.stopParent != nil {
+		c.stopParent()
+	}
+	if c.timer != nil {
+		c.timer.Stop()
+	}
+	c.cancel(context.Canceled)
+}
+
+// Deadline returns the deadline of the context, which is the earliest of the
+// parent deadline, any timeout of the derivation, and any hard stop scheduled at
+// the time of the derivation. As the deadline of a context must not change it
+// is not moved by RequestExtension, in which case the context is cancelled
+// after its deadline.
+func (c *stopCtx) Deadline() (deadline time.Time, ok bool) {
+	if !c.deadline.IsZero() {
+		return c.deadline, true
+	}
+	return c.parent.Deadline()
+}
+
+// Done returns a channel that is closed when the context is cancelled.
+func (c *stopCtx) Done() <-chan struct{} {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	if c.done == nil {
+		if c.err != nil {
+			return closedChan
+		}
+		c.done = make(chan struct{})
+	}
+	return c.done
+}
+
+// Err returns a non-nil error once the context has been cancelled.
+func (c *stopCtx) Err() error {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	return c.err
+}
+
+// Value returns the value associated with the key from the parent context.
+func (c *stopCtx) Value(key any) any {
+	return c.parent.Value(key)
+}
+
+// AfterFunc arranges to call f in its own goroutine once the context is done,
+// as context.AfterFunc does, and returns a function that stops the call,
+// reporting whether it did so before f was started. Contexts derived with the
+// context package, such as with context.WithTimeout, are cancelled through
+// this method rather than with a goroutine of their own.
+func (c *stopCtx) AfterFunc(f func()) (stop func() bool) {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	return c.afterFuncs.add(&c.mut, c.err != nil, f)
+}
+
+//------------------------------------------------------------------------------
+
+// afterFuncs holds the functions registered with the AfterFunc method of a
+// context, which the context package uses in order to propagate cancellation
+// to derived contexts without starting a goroutine for each. It is guarded by
+// the mutex of the context.
+type afterFuncs []*func()
+
+// add registers f, or starts it immediately if the context is already done,
+// and returns a function that deregisters it. The mutex must be held.
+func (a *afterFuncs) add(mut *sync.Mutex, done bool, f func()) (stop func() bool) {
+	if done {
+		go f()
+		return func() bool { return false }
+	}
+	p := &f
+	*a = append(*a, p)
+	return func() bool {
+		mut.Lock()
+		defer mut.Unlock()
+
+		i := slices.Index(*a, p)
+		if i < 0 {
+			return false
+		}
+		*a = slices.Delete(*a, i, i+1)
+		return true
+	}
+}
+
+// call starts each registered function in a goroutine of its own, once the
+// context is done. The mutex must be held.
+func (a *afterFuncs) call() {
+	for _, f := range *a {
+		go (*f)()
+	}
+	*a = nil
+}
+
+// tierAfterFunc calls a function once a tier is signalled.
+type tierAfterFunc struct {
+	w waiter
+	f func()
+}
+
+func (a *tierAfterFunc) notify() {
+	go a.f()
+}
+
+// afterTier arranges to call f in its own goroutine once the tier is
+// signalled, and returns a function that stops the call, reporting whether it
+// did so before f was started.
+func (s *Signaller) afterTier(t Tier, f func()) (stop func() bool) {
+	a := &tierAfterFunc{f: f}
+	a.w.n = a
+	if !s.addWaiter(t, &a.w) {
+		go f()
+		return func() bool { return false }
+	}
+	return func() bool {
+		return s.removeWaiter(&a.w)
+	}
+}
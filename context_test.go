@@ -0,0 +1,313 @@
+package shutdown
+
+import (
+	"context"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAcquireSoftStopCtx(t *testing.T) {
+	s := NewSignaller()
+
+	// Background parent, released before a signal
+	ctx := s.AcquireSoftStopCtx(context.Background())
+	assertOpen(t, ctx.Done())
+	assert.NoError(t, ctx.Err())
+	ctx.Release()
+
+	// Cancelled from original context
+	inCtx, inDone := context.WithCancel(context.Background())
+	ctx = s.AcquireSoftStopCtx(inCtx)
+	assertOpen(t, ctx.Done())
+	inDone()
+	assertClosed(t, ctx.Done())
+	assert.Equal(t, context.Canceled, ctx.Err())
+	ctx.Release()
+
+	// Released from a cancellable parent, and then reused
+	inCtx, inDone = context.WithCancel(context.Background())
+	ctx = s.AcquireSoftStopCtx(inCtx)
+	assertOpen(t, ctx.Done())
+	ctx.Release()
+	ctx = s.AcquireSoftStopCtx(inCtx)
+	assertOpen(t, ctx.Done())
+	assert.NoError(t, ctx.Err())
+
+	// Cancelled from soft stop signal
+	bgCtx := s.AcquireSoftStopCtx(context.Background())
+	assertOpen(t, bgCtx.Done())
+	s.TriggerSoftStop()
+	assertClosed(t, ctx.Done())
+	assert.Equal(t, context.Canceled, ctx.Err())
+	assertClosed(t, bgCtx.Done())
+	assert.Equal(t, context.Canceled, bgCtx.Err())
+	ctx.Release()
+	bgCtx.Release()
+	inDone()
+
+	// Already signalled
+	inCtx, inDone = context.WithCancel(context.Background())
+	ctx = s.AcquireSoftStopCtx(inCtx)
+	assertClosed(t, ctx.Done())
+	assert.Equal(t, context.Canceled, ctx.Err())
+	ctx.Release()
+	inDone()
+}
+
+func TestAcquireHardStopCtx(t *testing.T) {
+	s := NewSignaller()
+
+	inCtx, inDone := context.WithCancel(context.Background())
+	defer inDone()
+
+	ctx := s.AcquireHardStopCtx(inCtx)
+	bgCtx := s.AcquireHardStopCtx(context.Background())
+
+	s.TriggerSoftStop()
+	assertOpen(t, ctx.Done())
+	assertOpen(t, bgCtx.Done())
+
+	s.TriggerHardStop()
+	assertClosed(t, ctx.Done())
+	assertClosed(t, bgCtx.Done())
+
+	ctx.Release()
+	bgCtx.Release()
+}
+
+func TestAcquireHasStoppedCtx(t *testing.T) {
+	s := NewSignaller()
+
+	inCtx, inDone := context.WithCancel(context.Background())
+	defer inDone()
+
+	ctx := s.AcquireHasStoppedCtx(inCtx)
+
+	s.TriggerHardStop()
+	assertOpen(t, ctx.Done())
+
+	s.TriggerHasStopped()
+	assertClosed(t, ctx.Done())
+	ctx.Release()
+}
+
+func TestAcquireCtxValue(t *testing.T) {
+	type key struct{}
+
+	s := NewSignaller()
+	ctx := s.AcquireSoftStopCtx(context.WithValue(context.Background(), key{}, "foo"))
+	assert.Equal(t, "foo", ctx.Value(key{}))
+	ctx.Release()
+}
+
+func BenchmarkSoftStopCtx(b *testing.B) {
+	s := NewSignaller()
+	parent, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	b.Run("background", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_, done := s.SoftStopCtx(context.Background())
+			done()
+		}
+	})
+
+	b.Run("cancellable", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_, done := s.SoftStopCtx(parent)
+			done()
+		}
+	})
+}
+
+func BenchmarkAcquireSoftStopCtx(b *testing.B) {
+	s := NewSignaller()
+	parent, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	b.Run("background", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			s.AcquireSoftStopCtx(context.Background()).Release()
+		}
+	})
+
+	b.Run("cancellable", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			s.AcquireSoftStopCtx(parent).Release()
+		}
+	})
+}
+
+func TestStopCtxLazyDone(t *testing.T) {
+	s := NewSignaller()
+
+	// Cancelled before Done is ever called
+	ctx, done := s.SoftStopCtx(context.Background())
+	done()
+	assert.Equal(t, context.Canceled, ctx.Err())
+	assertClosed(t, ctx.Done())
+
+	// Signalled before Done is ever called
+	ctx, done = s.SoftStopCtx(context.Background())
+	s.TriggerSoftStop()
+	assert.Equal(t, context.Canceled, ctx.Err())
+	assertClosed(t, ctx.Done())
+	done()
+}
+
+func TestStopCtxParentAlreadyCancelled(t *testing.T) {
+	s := NewSignaller()
+
+	inCtx, inDone := context.WithTimeout(context.Background(), 0)
+	defer inDone()
+
+	ctx, done := s.HardStopCtx(inCtx)
+	assertClosed(t, ctx.Done())
+	assert.Equal(t, context.DeadlineExceeded, ctx.Err())
+	done()
+}
+
+func TestStopCtxAlreadySignalled(t *testing.T) {
+	type key struct{}
+
+	s := NewSignaller()
+	s.TriggerHardStop()
+
+	inCtx, inDone := context.WithDeadline(context.WithValue(context.Background(), key{}, "foo"), time.Now().Add(time.Hour))
+	defer inDone()
+
+	for _, fn := range []func(context.Context) (context.Context, context.CancelFunc){
+		s.SoftStopCtx, s.HardStopCtx,
+	} {
+		ctx, done := fn(inCtx)
+		assertClosed(t, ctx.Done())
+		assert.Equal(t, context.Canceled, ctx.Err())
+		assert.Equal(t, "foo", ctx.Value(key{}))
+
+		_, ok := ctx.Deadline()
+		assert.True(t, ok)
+		done()
+	}
+
+	ctx := s.AcquireHardStopCtx(inCtx)
+	assertClosed(t, ctx.Done())
+	assert.Equal(t, context.Canceled, ctx.Err())
+	ctx.Release()
+}
+
+func BenchmarkSoftStopCtxSignalled(b *testing.B) {
+	s := NewSignaller()
+	s.TriggerSoftStop()
+	parent, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		ctx, done := s.SoftStopCtx(parent)
+		<-ctx.Done()
+		done()
+	}
+}
+
+func TestDerivedCtxNoGoroutines(t *testing.T) {
+	s := NewSignaller()
+	parent, cancelParent := context.WithCancel(context.Background())
+	defer cancelParent()
+
+	before := runtime.NumGoroutine()
+
+	var ctxs []context.Context
+	var cancels []context.CancelFunc
+	for i := 0; i < 100; i++ {
+		soft, softDone := s.SoftStopCtx(context.Background())
+		cancels = append(cancels, softDone)
+
+		pooled := s.AcquireSoftStopCtx(parent)
+		unmerged := s.AcquireHardStopCtx(context.Background())
+		for _, c := range []context.Context{soft, pooled, unmerged} {
+			ctx, cancel := context.WithTimeout(c, time.Hour)
+			ctxs, cancels = append(ctxs, ctx), append(cancels, cancel)
+		}
+	}
+	assert.LessOrEqual(t, runtime.NumGoroutine(), before)
+
+	s.TriggerHardStop()
+	for _, ctx := range ctxs {
+		assertClosed(t, ctx.Done())
+		assert.ErrorIs(t, ctx.Err(), context.Canceled)
+	}
+	for _, cancel := range cancels {
+		cancel()
+	}
+}
+
+func TestStopCtxAfterFunc(t *testing.T) {
+	s := NewSignaller()
+	ctx, done := s.SoftStopCtx(context.Background())
+	defer done()
+
+	// The context package propagates cancellation through this interface.
+	a, ok := ctx.(interface {
+		AfterFunc(f func()) (stop func() bool)
+	})
+	require.True(t, ok)
+
+	called := make(chan struct{})
+	a.AfterFunc(func() { close(called) })
+	stop := a.AfterFunc(func() { t.Error("stopped function called") })
+	assert.True(t, stop())
+	assert.False(t, stop())
+
+	s.TriggerSoftStop()
+	assertClosed(t, called)
+}
+
+func TestPooledCtxAfterFunc(t *testing.T) {
+	s := NewSignaller()
+	parent, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	merged, unmerged := s.AcquireSoftStopCtx(parent), s.AcquireSoftStopCtx(context.Background())
+	mergedCalled, unmergedCalled := make(chan struct{}), make(chan struct{})
+	merged.AfterFunc(func() { close(mergedCalled) })
+	unmerged.AfterFunc(func() { close(unmergedCalled) })
+	assert.True(t, merged.AfterFunc(func() {})())
+	assert.True(t, unmerged.AfterFunc(func() {})())
+
+	s.TriggerSoftStop()
+	assertClosed(t, mergedCalled)
+	assertClosed(t, unmergedCalled)
+	assert.False(t, merged.AfterFunc(func() {})())
+	merged.Release()
+	unmerged.Release()
+}
+
+func TestPooledCtxAbortSoftStop(t *testing.T) {
+	s := NewSignaller(WithReversibleSoftStop())
+
+	before := s.AcquireSoftStopCtx(context.Background())
+	defer before.Release()
+	s.TriggerSoftStop()
+	after := s.AcquireSoftStopCtx(context.Background())
+	defer after.Release()
+
+	// Cancelled contexts remain cancelled once the soft stop is aborted.
+	require.True(t, s.AbortSoftStop())
+	for _, ctx := range []*PooledCtx{before, after} {
+		assertClosed(t, ctx.Done())
+		assert.Equal(t, context.Canceled, ctx.Err())
+	}
+
+	fresh := s.AcquireSoftStopCtx(context.Background())
+	defer fresh.Release()
+	assertOpen(t, fresh.Done())
+	assert.NoError(t, fresh.Err())
+}
@@ -0,0 +1,59 @@
+package shutdown
+
+import (
+	"context"
+)
+
+// NewContexts provides the lifecycle of a Signaller purely in terms of
+// contexts, for code that never deals with channels directly. A soft stop is
+// triggered when the parent context is cancelled, and softCtx and hardCtx are
+// cancelled once a soft or hard stop respectively has been signalled. The
+// component calls markStopped once it has finished stopping, which cancels
+// stoppedCtx:
+//
+//	soft, hard, markStopped, stopped := shutdown.NewContexts(ctx,
+//		shutdown.WithHardStopGrace(10*time.Second))
+//	go func() {
+//		defer markStopped()
+//		serve(soft, hard)
+//	}()
+//	<-stopped.Done()
+//
+// The returned contexts carry the values of the parent but are only cancelled
+// by the tiers of the underlying signaller, which is configured with the
+// provided options.
+func NewContexts(parent context.Context, opts ...Option) (softCtx, hardCtx context.Context, markStopped func(), stoppedCtx context.Context) {
+	s := NewSignaller(opts...)
+	context.AfterFunc(parent, func() {
+		s.TriggerSoftStopFrom(SourceParentContext)
+	})
+
+	base := context.WithoutCancel(parent)
+	softCtx, _ = s.SoftStopCtx(base)
+	hardCtx, _ = s.HardStopCtx(base)
+	stoppedCtx, _ = s.HasStoppedCtx(base)
+	return softCtx, hardCtx, s.TriggerHasStopped, stoppedCtx
+}
+
+// FromContexts creates a signaller that is driven by existing contexts, such as
+// those provided by a framework, so that the components it owns can be given
+// the standard Signaller surface. A soft stop is triggered when softCtx is
+// cancelled and a hard stop when hardCtx is cancelled, either of which may be
+// nil when the framework provides no such context. The owner of the signaller
+// remains responsible for calling TriggerHasStopped.
+func FromContexts(softCtx, hardCtx context.Context, opts ...Option) *Signaller {
+	s := NewSignaller(opts...)
+	if softCtx != nil {
+		stop := context.AfterFunc(softCtx, func() {
+			s.TriggerSoftStopFrom(SourceParentContext)
+		})
+		s.OnHasStopped(func() { stop() })
+	}
+	if hardCtx != nil {
+		stop := context.AfterFunc(hardCtx, func() {
+			s.TriggerHardStopFrom(SourceParentContext)
+		})
+		s.OnHasStopped(func() { stop() })
+	}
+	return s
+}
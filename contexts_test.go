@@ -0,0 +1,68 @@
+package shutdown
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type contextsKey struct{}
+
+func TestNewContexts(t *testing.T) {
+	parent, cancel := context.WithCancel(context.WithValue(context.Background(), contextsKey{}, "bar"))
+	soft, hard, markStopped, stopped := NewContexts(parent, WithHardStopGrace(time.Millisecond))
+
+	assert.Equal(t, "bar", soft.Value(contextsKey{}))
+	assert.NoError(t, soft.Err())
+	assert.NoError(t, hard.Err())
+
+	cancel()
+	assertClosed(t, soft.Done())
+	<-hard.Done()
+	assertOpen(t, stopped.Done())
+
+	markStopped()
+	assertClosed(t, stopped.Done())
+}
+
+func TestNewContextsStoppedEarly(t *testing.T) {
+	soft, hard, markStopped, stopped := NewContexts(context.Background())
+
+	markStopped()
+	assertClosed(t, stopped.Done())
+	assertOpen(t, soft.Done())
+	assertOpen(t, hard.Done())
+}
+
+func TestFromContexts(t *testing.T) {
+	softCtx, softCancel := context.WithCancel(context.Background())
+	defer softCancel()
+	hardCtx, hardCancel := context.WithCancel(context.Background())
+	defer hardCancel()
+
+	s := FromContexts(softCtx, hardCtx, WithName("framework"))
+	assertOpen(t, s.SoftStopChan())
+
+	softCancel()
+	assertClosed(t, s.SoftStopChan())
+	assertOpen(t, s.HardStopChan())
+
+	hardCancel()
+	assertClosed(t, s.HardStopChan())
+
+	assert.Eventually(t, func() bool {
+		src, _ := s.TriggeredBy(TierHardStop)
+		return src == SourceParentContext
+	}, time.Second, time.Millisecond)
+}
+
+func TestFromContextsNil(t *testing.T) {
+	hardCtx, hardCancel := context.WithCancel(context.Background())
+	s := FromContexts(nil, hardCtx)
+
+	hardCancel()
+	assertClosed(t, s.SoftStopChan())
+	assertClosed(t, s.HardStopChan())
+}
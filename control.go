@@ -0,0 +1,87 @@
+package shutdown
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+// controlEvents maps event kinds to their names in the control protocol.
+var controlEvents = map[EventKind]string{
+	EventSoftStop:        "soft-stop",
+	EventHardStop:        "hard-stop",
+	EventHasStopped:      "has-stopped",
+	EventSoftStopAborted: "soft-stop-aborted",
+	EventReload:          "reload",
+}
+
+// ServeControl runs a line based control protocol for processes run by
+// supervisors that communicate over pipes, typically with os.Stdin and
+// os.Stdout, until the signaller has stopped.
+//
+// Each line read from r is a command, which is one of:
+//
+//	soft-stop   triggers a soft stop
+//	hard-stop   triggers a hard stop
+//	reload      triggers a reload
+//	status      writes "status <state>", where the state is one of running,
+//	            soft-stopping, hard-stopping or stopped
+//
+// Each lifecycle transition of the signaller is written to w as a line naming
+// it, which is one of soft-stop, hard-stop, has-stopped, soft-stop-aborted or
+// reload, and unrecognised commands are answered with a line beginning with
+// "error". Reaching the end of r stops the reading of commands but not the
+// writing of transitions. Returns once has-stopped has been written, or with
+// the error of w if writing fails.
+func ServeControl(s *Signaller, r io.Reader, w io.Writer) error {
+	events, cancel := s.Subscribe(WithDelivery(DeliverBlocking))
+	defer cancel()
+
+	var (
+		mut      sync.Mutex
+		writeErr error
+	)
+	writeLine := func(format string, args ...any) error {
+		mut.Lock()
+		defer mut.Unlock()
+		if writeErr == nil {
+			_, writeErr = fmt.Fprintf(w, format+"\n", args...)
+		}
+		return writeErr
+	}
+
+	go func() {
+		lines := bufio.NewScanner(r)
+		for lines.Scan() {
+			switch cmd := strings.TrimSpace(lines.Text()); cmd {
+			case "":
+			case "soft-stop":
+				s.TriggerSoftStopFrom(SourceAdminAPI)
+			case "hard-stop":
+				s.TriggerHardStopFrom(SourceAdminAPI)
+			case "reload":
+				s.triggerReload(cause{source: SourceAdminAPI})
+			case "status":
+				state := strings.ReplaceAll(lifecycleStateName(s), " ", "-")
+				_ = writeLine("status %v", state)
+			default:
+				_ = writeLine("error unrecognised command: %q", cmd)
+			}
+		}
+	}()
+
+	if s.IsHasStoppedSignalled() {
+		return writeLine("has-stopped")
+	}
+	for e := range events {
+		if err := writeLine("%v", controlEvents[e.Kind]); err != nil {
+			return err
+		}
+		if e.Kind == EventHasStopped {
+			return nil
+		}
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package shutdown
+
+import (
+	"bufio"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServeControl(t *testing.T) {
+	s := NewSignaller()
+
+	inR, inW := io.Pipe()
+	outR, outW := io.Pipe()
+	defer inW.Close()
+
+	errC := make(chan error, 1)
+	go func() {
+		errC <- ServeControl(s, inR, outW)
+		outW.Close()
+	}()
+
+	out := bufio.NewScanner(outR)
+	nextLine := func() string {
+		t.Helper()
+		require.True(t, out.Scan(), "output ended")
+		return out.Text()
+	}
+	send := func(line string) {
+		t.Helper()
+		_, err := io.WriteString(inW, line+"\n")
+		require.NoError(t, err)
+	}
+
+	send("status")
+	assert.Equal(t, "status running", nextLine())
+
+	send("reboot")
+	assert.Equal(t, `error unrecognised command: "reboot"`, nextLine())
+
+	send("reload")
+	assert.Equal(t, "reload", nextLine())
+	assert.Equal(t, int64(1), s.Reloads())
+
+	send("soft-stop")
+	assert.Equal(t, "soft-stop", nextLine())
+
+	send("status")
+	assert.Equal(t, "status soft-stopping", nextLine())
+
+	send("hard-stop")
+	assert.Equal(t, "hard-stop", nextLine())
+
+	s.TriggerHasStopped()
+	assert.Equal(t, "has-stopped", nextLine())
+
+	select {
+	case err := <-errC:
+		require.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for control to return")
+	}
+}
+
+func TestServeControlAlreadyStopped(t *testing.T) {
+	s := NewSignaller()
+	s.TriggerHardStop()
+	s.TriggerHasStopped()
+
+	inR, inW := io.Pipe()
+	defer inW.Close()
+
+	outR, outW := io.Pipe()
+	go func() {
+		assert.NoError(t, ServeControl(s, inR, outW))
+		outW.Close()
+	}()
+
+	out, err := io.ReadAll(outR)
+	require.NoError(t, err)
+	assert.Equal(t, "has-stopped\n", string(out))
+}
@@ -0,0 +1,77 @@
+package shutdown
+
+// WithContextSites is a debug option that records where each context derived
+// from the signaller with the *Ctx and Acquire*Ctx methods was created, for as
+// long as it has not been released, which is reported by
+// OutstandingContextSites. Recording sites captures the stack of the caller and
+// is therefore expensive.
+func WithContextSites() Option {
+	return func(o *options) {
+		o.recordCtxSites = true
+	}
+}
+
+// OutstandingContexts returns the number of contexts derived from the
+// signaller with the *Ctx and Acquire*Ctx methods that have not yet been
+// released, by calling their cancel function or Release method respectively.
+// A count that remains high long after the signaller has stopped identifies
+// code that holds on to contexts, and WithContextSites identifies where they
+// were created.
+//
+// Contexts derived after their tier has been signalled, or from a parent that
+// has already been cancelled, require no release and are not counted.
+func (s *Signaller) OutstandingContexts() int {
+	if s == nil {
+		return 0
+	}
+	return int(s.ctxs.Load())
+}
+
+// OutstandingContextSites returns the number of outstanding contexts created
+// at each site, formatted as the function along with its file and line, as
+// recorded when constructed with WithContextSites. Returns nil otherwise.
+func (s *Signaller) OutstandingContextSites() map[string]int {
+	if s == nil {
+		return nil
+	}
+	x := s.ext.Load()
+	if x == nil || !x.recordCtxSites {
+		return nil
+	}
+	sites := map[string]int{}
+	s.mut.Lock()
+	for _, site := range x.ctxSiteMap {
+		sites[site]++
+	}
+	s.mut.Unlock()
+	return sites
+}
+
+func (s *Signaller) trackCtx(c any) {
+	s.ctxs.Add(1)
+	x := s.ext.Load()
+	if x == nil || !x.recordCtxSites {
+		return
+	}
+	site := callerSource()
+	s.mut.Lock()
+	if x.ctxSiteMap == nil {
+		x.ctxSiteMap = map[any]string{}
+	}
+	x.ctxSiteMap[c] = site
+	s.mut.Unlock()
+}
+
+func (s *Signaller) untrackCtx(c any) {
+	if s == nil {
+		return
+	}
+	s.ctxs.Add(-1)
+	x := s.ext.Load()
+	if x == nil || !x.recordCtxSites {
+		return
+	}
+	s.mut.Lock()
+	delete(x.ctxSiteMap, c)
+	s.mut.Unlock()
+}
@@ -0,0 +1,74 @@
+package shutdown
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOutstandingContexts(t *testing.T) {
+	s := NewSignaller()
+	assert.Zero(t, s.OutstandingContexts())
+
+	_, doneA := s.SoftStopCtx(context.Background())
+	_, doneB := s.HardStopCtx(context.Background())
+	pooled := s.AcquireSoftStopCtx(context.Background())
+	assert.Equal(t, 3, s.OutstandingContexts())
+
+	doneA()
+	doneA()
+	pooled.Release()
+	assert.Equal(t, 1, s.OutstandingContexts())
+
+	// Contexts are still outstanding once cancelled by the signaller, until
+	// they are released.
+	s.TriggerHardStop()
+	assert.Equal(t, 1, s.OutstandingContexts())
+
+	var buf bytes.Buffer
+	_, err := s.Diagnostics().WriteTo(&buf)
+	require.NoError(t, err)
+	assert.Contains(t, buf.String(), "  outstanding contexts: 1\n")
+
+	doneB()
+	assert.Zero(t, s.OutstandingContexts())
+
+	// Contexts derived after the signal need no release.
+	_, doneC := s.SoftStopCtx(context.Background())
+	assert.Zero(t, s.OutstandingContexts())
+	doneC()
+	assert.Zero(t, s.OutstandingContexts())
+	assert.Nil(t, s.OutstandingContextSites())
+}
+
+func TestOutstandingContextSites(t *testing.T) {
+	s := NewSignaller(WithContextSites())
+
+	var dones []context.CancelFunc
+	for i := 0; i < 2; i++ {
+		_, done := s.SoftStopCtx(context.Background())
+		dones = append(dones, done)
+	}
+	pooled := s.AcquireHardStopCtx(context.Background())
+
+	sites := s.OutstandingContextSites()
+	require.Len(t, sites, 2)
+	for site, n := range sites {
+		assert.True(t, strings.HasPrefix(site, "github.com/Jeffail/shutdown.TestOutstandingContextSites "), site)
+		assert.Contains(t, []int{1, 2}, n)
+	}
+
+	for _, done := range dones {
+		done()
+	}
+	pooled.Release()
+	assert.Empty(t, s.OutstandingContextSites())
+
+	var nilS *Signaller
+	assert.Zero(t, nilS.OutstandingContexts())
+	assert.Nil(t, nilS.OutstandingContextSites())
+}
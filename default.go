@@ -0,0 +1,88 @@
+package shutdown
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+var defaultSignaller atomic.Pointer[Signaller]
+
+func init() {
+	defaultSignaller.Store(NewSignaller())
+}
+
+// Default returns the process-wide default signaller, which the package-level
+// functions such as TriggerHardStop and SoftStopChan operate on. This allows
+// small programs, and libraries that wish to observe the shutdown of the
+// program that imports them, to coordinate without passing a signaller through
+// every layer, similar to http.DefaultServeMux.
+//
+// Components that are stopped independently of the program should own a
+// signaller of their own instead.
+func Default() *Signaller {
+	return defaultSignaller.Load()
+}
+
+// SetDefault replaces the process-wide default signaller, which is useful for
+// programs that wish to configure it with options. Channels, contexts and hooks
+// obtained from the previous default signaller continue to observe it rather
+// than the replacement, and so this should be called early, before the default
+// signaller is used.
+func SetDefault(s *Signaller) {
+	defaultSignaller.Store(s)
+}
+
+// TriggerSoftStop signals a soft stop to the default signaller.
+func TriggerSoftStop() {
+	Default().TriggerSoftStop()
+}
+
+// TriggerHardStop signals a hard stop to the default signaller.
+func TriggerHardStop() {
+	Default().TriggerHardStop()
+}
+
+// TriggerHasStopped signals that the program has stopped to the default
+// signaller.
+func TriggerHasStopped() {
+	Default().TriggerHasStopped()
+}
+
+// SoftStopChan returns a channel that will be closed once the default
+// signaller receives the signal to soft or hard stop.
+func SoftStopChan() <-chan struct{} {
+	return Default().SoftStopChan()
+}
+
+// HardStopChan returns a channel that will be closed once the default
+// signaller receives the signal to hard stop.
+func HardStopChan() <-chan struct{} {
+	return Default().HardStopChan()
+}
+
+// HasStoppedChan returns a channel that will be closed once the default
+// signaller receives the signal that the program has stopped.
+func HasStoppedChan() <-chan struct{} {
+	return Default().HasStoppedChan()
+}
+
+// SoftStopCtx returns a context.Context that will be terminated when either the
+// provided context is cancelled or the default signaller receives the signal
+// to soft or hard stop.
+func SoftStopCtx(ctx context.Context) (context.Context, context.CancelFunc) {
+	return Default().SoftStopCtx(ctx)
+}
+
+// HardStopCtx returns a context.Context that will be terminated when either the
+// provided context is cancelled or the default signaller receives the signal
+// to hard stop.
+func HardStopCtx(ctx context.Context) (context.Context, context.CancelFunc) {
+	return Default().HardStopCtx(ctx)
+}
+
+// HasStoppedCtx returns a context.Context that will be terminated when either
+// the provided context is cancelled or the default signaller receives the
+// signal that the program has stopped.
+func HasStoppedCtx(ctx context.Context) (context.Context, context.CancelFunc) {
+	return Default().HasStoppedCtx(ctx)
+}
@@ -0,0 +1,48 @@
+package shutdown
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefaultSignaller(t *testing.T) {
+	prev := Default()
+	require.NotNil(t, prev)
+	t.Cleanup(func() {
+		SetDefault(prev)
+	})
+
+	s := NewSignaller()
+	SetDefault(s)
+	assert.Equal(t, s, Default())
+
+	softCtx, softDone := SoftStopCtx(context.Background())
+	defer softDone()
+	hardCtx, hardDone := HardStopCtx(context.Background())
+	defer hardDone()
+	stoppedCtx, stoppedDone := HasStoppedCtx(context.Background())
+	defer stoppedDone()
+
+	assertOpen(t, SoftStopChan())
+	TriggerSoftStop()
+	assert.True(t, s.IsSoftStopSignalled())
+	assertClosed(t, SoftStopChan())
+	assertClosed(t, softCtx.Done())
+
+	assertOpen(t, HardStopChan())
+	TriggerHardStop()
+	assert.True(t, s.IsHardStopSignalled())
+	assertClosed(t, HardStopChan())
+	assertClosed(t, hardCtx.Done())
+
+	assertOpen(t, HasStoppedChan())
+	TriggerHasStopped()
+	assert.True(t, s.IsHasStoppedSignalled())
+	assertClosed(t, HasStoppedChan())
+	assertClosed(t, stoppedCtx.Done())
+
+	assert.False(t, prev.IsSoftStopSignalled())
+}
@@ -0,0 +1,71 @@
+package shutdown
+
+import (
+	"context"
+	"fmt"
+)
+
+// WithDeregistration adds a function that deregisters the process from a
+// service registry, such as Consul or etcd, which is called when a soft stop
+// is requested by an OS signal or with RequestSoftStop, before the soft stop
+// is triggered. Combined with WithDrainDelay, which then acts as the time
+// allowed for the deregistration to propagate, this ensures that clients stop
+// routing to the process before its listeners stop accepting.
+//
+// Functions are called in the order they were added, with a context that is
+// cancelled once a hard stop is signalled. Their errors are recorded against
+// the signaller and do not prevent the soft stop.
+func WithDeregistration(fn func(ctx context.Context) error) Option {
+	return func(o *options) {
+		o.deregister = append(o.deregister, fn)
+	}
+}
+
+// RequestSoftStop begins a soft stop in the same way as an OS signal configured
+// with WithSignals does, by first calling any functions configured with
+// WithDeregistration and then waiting for the delay configured with
+// WithDrainDelay before triggering the soft stop. Returns immediately, and a
+// repeated request cuts the stage short, triggering the soft stop immediately.
+// Without deregistration functions or a drain delay this is equivalent to
+// TriggerSoftStop.
+func (s *Signaller) RequestSoftStop() {
+	s.requestSoftStop(cause{})
+}
+
+// requestSoftStop is RequestSoftStop with a cause recorded in the history of
+// the signaller.
+func (s *Signaller) requestSoftStop(c cause) {
+	trigger := func() { s.triggerSoftStop(c) }
+
+	o := s.config()
+	if len(o.deregister) == 0 && o.drainDelay <= 0 {
+		trigger()
+		return
+	}
+
+	x := s.extra()
+	if !x.softRequested.CompareAndSwap(false, true) {
+		trigger()
+		return
+	}
+	if len(o.deregister) == 0 {
+		s.clock().AfterFunc(o.drainDelay, trigger)
+		return
+	}
+
+	go func() {
+		ctx, done := s.HardStopCtx(context.Background())
+		defer done()
+
+		for _, fn := range o.deregister {
+			if err := fn(ctx); err != nil {
+				s.RecordStopErr(fmt.Errorf("deregistering: %w", err))
+			}
+		}
+		if o.drainDelay > 0 {
+			s.clock().AfterFunc(o.drainDelay, trigger)
+		} else {
+			trigger()
+		}
+	}()
+}
@@ -0,0 +1,70 @@
+package shutdown
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestSoftStopWithoutStage(t *testing.T) {
+	s := NewSignaller()
+	s.RequestSoftStop()
+	assert.True(t, s.IsSoftStopSignalled())
+}
+
+func TestRequestSoftStopDeregistersFirst(t *testing.T) {
+	clock := newManualClock()
+
+	deregistered := make(chan struct{})
+	errDeregister := errors.New("registry unavailable")
+	var s *Signaller
+	s = NewSignaller(
+		WithClock(clock),
+		WithDrainDelay(time.Second),
+		WithDeregistration(func(ctx context.Context) error {
+			assert.False(t, s.IsSoftStopSignalled())
+			return nil
+		}),
+		WithDeregistration(func(ctx context.Context) error {
+			close(deregistered)
+			return errDeregister
+		}),
+	)
+
+	s.RequestSoftStop()
+	<-deregistered
+
+	// Wait for the drain delay to be scheduled.
+	assert.Eventually(t, func() bool {
+		clock.mut.Lock()
+		defer clock.mut.Unlock()
+		return len(clock.timers) > 0
+	}, time.Second*5, time.Millisecond)
+	assert.False(t, s.IsSoftStopSignalled())
+
+	clock.Advance(time.Second)
+	assert.True(t, s.IsSoftStopSignalled())
+	assert.ErrorIs(t, s.StopErr(), errDeregister)
+}
+
+func TestRequestSoftStopRepeated(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+
+	s := NewSignaller(WithDeregistration(func(ctx context.Context) error {
+		select {
+		case <-release:
+		case <-ctx.Done():
+		}
+		return nil
+	}))
+
+	s.RequestSoftStop()
+	assert.False(t, s.IsSoftStopSignalled())
+
+	s.RequestSoftStop()
+	assert.True(t, s.IsSoftStopSignalled())
+}
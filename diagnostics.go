@@ -0,0 +1,266 @@
+package shutdown
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+)
+
+// Diagnostics describes the lifecycle state of a Signaller at a point in time,
+// as returned by its Diagnostics method, for the investigation of components
+// that are stuck stopping.
+type Diagnostics struct {
+	Name string
+	Time time.Time
+
+	// The labels of the signaller, see WithLabels.
+	Labels map[string]string
+
+	SoftStop   bool
+	HardStop   bool
+	HasStopped bool
+
+	// The time of a scheduled hard stop, or zero if none is scheduled.
+	HardStopDeadline time.Time
+
+	// The errors recorded against the signaller so far.
+	StopErr error
+
+	// The attributes attached to the signaller, see TriggerSoftStopWith.
+	Attrs []slog.Attr
+
+	// The reasons added with AddStopReason.
+	Reasons []string
+
+	// The number of derived contexts that have not been released, see
+	// OutstandingContexts.
+	OutstandingContexts int
+
+	// The number of goroutines started with Go that are running.
+	Goroutines int
+
+	// The requests made with RequestExtension.
+	Extensions []Extension
+
+	// The transitions recorded when constructed with WithHistory.
+	History []Transition
+
+	// The stacks of the goroutines that triggered each tier, indexed by tier,
+	// as recorded when constructed with WithTriggerStacks.
+	TriggerStacks [3][]byte
+
+	// The sections added with AddDiagnostics, in the order they were added.
+	Sections []DiagnosticsSection
+}
+
+// DiagnosticsSection is a named section of Diagnostics, such as the progress of
+// a set of drainers.
+type DiagnosticsSection struct {
+	Name   string `json:"name"`
+	Report string `json:"report"`
+}
+
+type diagnosticsSection struct {
+	name   string
+	report func() string
+}
+
+// AddDiagnostics adds a named section to the diagnostics of the signaller, the
+// report of which is generated by calling the provided function each time the
+// diagnostics are taken. Calling the returned function removes the section.
+func (s *Signaller) AddDiagnostics(name string, report func() string) (remove func()) {
+	if s == nil {
+		return func() {}
+	}
+	d := &diagnosticsSection{name: name, report: report}
+	x := s.extra()
+	s.mut.Lock()
+	x.diagnostics = append(x.diagnostics, d)
+	s.mut.Unlock()
+
+	return func() {
+		s.mut.Lock()
+		defer s.mut.Unlock()
+		for i, e := range x.diagnostics {
+			if e == d {
+				x.diagnostics = append(x.diagnostics[:i:i], x.diagnostics[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// Diagnostics returns the current lifecycle state of the signaller, which is
+// an empty report for a nil signaller.
+func (s *Signaller) Diagnostics() Diagnostics {
+	if s == nil {
+		return Diagnostics{}
+	}
+	d := Diagnostics{
+		Name:       s.Name(),
+		Time:       s.clock().Now(),
+		Labels:     s.Labels(),
+		SoftStop:   s.IsSoftStopSignalled(),
+		HardStop:   s.IsHardStopSignalled(),
+		HasStopped: s.IsHasStoppedSignalled(),
+		StopErr:    s.StopErr(),
+		Attrs:      s.StopAttrs(),
+		Reasons:    s.Reasons(),
+
+		OutstandingContexts: s.OutstandingContexts(),
+		Goroutines:          s.Goroutines(),
+	}
+	if deadline, ok := s.HardStopDeadline(); ok {
+		d.HardStopDeadline = deadline
+	}
+
+	x := s.ext.Load()
+	if x == nil {
+		return d
+	}
+	s.mut.Lock()
+	sections := x.diagnostics
+	d.Extensions = append([]Extension(nil), x.extensions...)
+	s.mut.Unlock()
+	d.History = s.History()
+	for t := TierSoftStop; t <= TierHasStopped; t++ {
+		d.TriggerStacks[t] = s.TriggerStack(t)
+	}
+
+	// Reports are generated without the lock held, as they may call back
+	// into the signaller.
+	for _, e := range sections {
+		d.Sections = append(d.Sections, DiagnosticsSection{Name: e.name, Report: e.report()})
+	}
+	return d
+}
+
+// WriteTo writes a human readable description of the diagnostics to w.
+func (d Diagnostics) WriteTo(w io.Writer) (int64, error) {
+	var buf bytes.Buffer
+
+	name := d.Name
+	if name == "" {
+		name = "(unnamed)"
+	}
+	fmt.Fprintf(&buf, "signaller %v at %v\n", name, d.Time.Format(time.RFC3339Nano))
+	if len(d.Labels) > 0 {
+		fmt.Fprintf(&buf, "  labels: %v\n", formatLabels(d.Labels))
+	}
+	fmt.Fprintf(&buf, "  soft stop: %v\n  hard stop: %v\n  has stopped: %v\n", d.SoftStop, d.HardStop, d.HasStopped)
+	if !d.HardStopDeadline.IsZero() {
+		fmt.Fprintf(&buf, "  hard stop deadline: %v\n", d.HardStopDeadline.Format(time.RFC3339Nano))
+	}
+	if d.OutstandingContexts > 0 {
+		fmt.Fprintf(&buf, "  outstanding contexts: %v\n", d.OutstandingContexts)
+	}
+	if d.Goroutines > 0 {
+		fmt.Fprintf(&buf, "  goroutines: %v\n", d.Goroutines)
+	}
+	if len(d.Reasons) > 0 {
+		buf.WriteString("  reasons:\n")
+		for _, r := range d.Reasons {
+			fmt.Fprintf(&buf, "    %v\n", r)
+		}
+	}
+	if len(d.Attrs) > 0 {
+		buf.WriteString("  attributes:\n")
+		for _, a := range d.Attrs {
+			fmt.Fprintf(&buf, "    %v\n", a)
+		}
+	}
+	if d.StopErr != nil {
+		fmt.Fprintf(&buf, "  stop errors:\n%v\n", indent(d.StopErr.Error(), "    "))
+	}
+	if len(d.History) > 0 {
+		buf.WriteString("  history:\n")
+		for _, t := range d.History {
+			fmt.Fprintf(&buf, "    %v\n", t)
+		}
+	}
+	if len(d.Extensions) > 0 {
+		buf.WriteString("  extensions:\n")
+		for _, e := range d.Extensions {
+			fmt.Fprintf(&buf, "    %v: requested %v, granted %v at %v\n", e.Reason, e.Requested, e.Granted, e.Time.Format(time.RFC3339Nano))
+		}
+	}
+	for t, stack := range d.TriggerStacks {
+		if stack != nil {
+			fmt.Fprintf(&buf, "  %v triggered by:\n%v\n", Tier(t), indent(string(stack), "    "))
+		}
+	}
+	for _, sec := range d.Sections {
+		fmt.Fprintf(&buf, "  %v:\n%v\n", sec.Name, indent(sec.Report, "    "))
+	}
+
+	n, err := w.Write(buf.Bytes())
+	return int64(n), err
+}
+
+// lifecycleStateName returns a short description of the furthest tier that a
+// signaller has reached.
+func lifecycleStateName(s *Signaller) string {
+	switch {
+	case s.IsHasStoppedSignalled():
+		return "stopped"
+	case s.IsHardStopSignalled():
+		return "hard stopping"
+	case s.IsSoftStopSignalled():
+		return "soft stopping"
+	}
+	return "running"
+}
+
+func indent(s, prefix string) string {
+	return prefix + strings.ReplaceAll(strings.TrimRight(s, "\n"), "\n", "\n"+prefix)
+}
+
+// WithDiagnosticsDump causes the signaller to listen for the provided OS
+// signals, or SIGUSR1 when none are provided on platforms that have it, where
+// receiving one stops nothing and instead calls fn with the current
+// Diagnostics of the signaller. When fn is nil the diagnostics are written to
+// stderr. This is invaluable for finding out why a process is stuck
+// terminating. Listening ends once the signaller has stopped.
+func WithDiagnosticsDump(fn func(d Diagnostics), sigs ...os.Signal) Option {
+	return func(o *options) {
+		if len(sigs) == 0 {
+			sigs = diagnosticsSignals
+		}
+		if fn == nil {
+			fn = func(d Diagnostics) {
+				_, _ = d.WriteTo(os.Stderr)
+			}
+		}
+		o.diagnosticsSignals = sigs
+		o.onDiagnostics = fn
+	}
+}
+
+// listenDiagnostics dumps diagnostics on OS signals until the signaller has
+// stopped.
+func (s *Signaller) listenDiagnostics(o *options) {
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, o.diagnosticsSignals...)
+
+	stopped := s.HasStoppedChan()
+	go func() {
+		defer signal.Stop(c)
+		for {
+			select {
+			case <-c:
+				o.onDiagnostics(s.Diagnostics())
+			case <-stopped:
+				return
+			}
+		}
+	}()
+}
+
+func splitLines(s string) []string {
+	return strings.Split(strings.TrimRight(s, "\n"), "\n")
+}
@@ -0,0 +1,61 @@
+package shutdown
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiagnostics(t *testing.T) {
+	clock := newManualClock()
+	s := NewSignaller(WithName("api"), WithClock(clock), WithHardStopGrace(time.Minute))
+
+	remove := s.AddDiagnostics("queue", func() string {
+		return "depth: 3\nworkers: 2"
+	})
+	s.AddDiagnostics("cache", func() string {
+		return "dirty: 0"
+	})
+
+	s.TriggerSoftStop()
+	s.RecordStopErr(errors.New("flush failed"))
+
+	d := s.Diagnostics()
+	assert.Equal(t, "api", d.Name)
+	assert.True(t, d.SoftStop)
+	assert.False(t, d.HardStop)
+	assert.Equal(t, clock.Now().Add(time.Minute), d.HardStopDeadline)
+	assert.Equal(t, []DiagnosticsSection{
+		{Name: "queue", Report: "depth: 3\nworkers: 2"},
+		{Name: "cache", Report: "dirty: 0"},
+	}, d.Sections)
+
+	var buf bytes.Buffer
+	_, err := d.WriteTo(&buf)
+	require.NoError(t, err)
+	assert.Contains(t, buf.String(), "signaller api at ")
+	assert.Contains(t, buf.String(), "  soft stop: true\n  hard stop: false\n  has stopped: false\n")
+	assert.Contains(t, buf.String(), "  hard stop deadline: ")
+	assert.Contains(t, buf.String(), "  stop errors:\n    flush failed\n")
+	assert.Contains(t, buf.String(), "  queue:\n    depth: 3\n    workers: 2\n  cache:\n    dirty: 0\n")
+
+	remove()
+	assert.Equal(t, []DiagnosticsSection{{Name: "cache", Report: "dirty: 0"}}, s.Diagnostics().Sections)
+}
+
+func TestDiagnosticsNil(t *testing.T) {
+	var s *Signaller
+	assert.Equal(t, Diagnostics{}, s.Diagnostics())
+}
+
+func TestDiagnosticsDrainers(t *testing.T) {
+	s := NewSignaller()
+	d := NewDrainers(s)
+	d.Register("http", 0, newFakeDrainer())
+
+	assert.Equal(t, []DiagnosticsSection{{Name: "drainers", Report: "http: pending\n"}}, s.Diagnostics().Sections)
+}
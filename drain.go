@@ -0,0 +1,212 @@
+package shutdown
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Drainer is a component that drains gracefully, such as a server finishing its
+// requests or a consumer finishing its messages, and that can be aborted if it
+// takes too long.
+type Drainer interface {
+	// BeginDrain starts draining, and should return once the drain has begun
+	// rather than once it has finished. The context is cancelled once a hard
+	// stop has been signalled.
+	BeginDrain(ctx context.Context) error
+
+	// Drained returns a channel that is closed once the drain has finished.
+	Drained() <-chan struct{}
+
+	// Abort abandons the drain, which should stop the component immediately.
+	Abort()
+}
+
+// DrainState describes the progress of a Drainer registered with Drainers.
+type DrainState int
+
+// The states of a drainer.
+const (
+	DrainPending DrainState = iota
+	DrainDraining
+	DrainDrained
+	DrainFailed
+	DrainAborted
+)
+
+// String returns a human readable name of the state.
+func (d DrainState) String() string {
+	switch d {
+	case DrainPending:
+		return "pending"
+	case DrainDraining:
+		return "draining"
+	case DrainDrained:
+		return "drained"
+	case DrainFailed:
+		return "failed"
+	case DrainAborted:
+		return "aborted"
+	}
+	return "unknown"
+}
+
+// DrainProgress describes the progress of a Drainer registered with Drainers.
+type DrainProgress struct {
+	Name  string
+	State DrainState
+
+	// The error returned by BeginDrain when the state is DrainFailed.
+	Err error
+}
+
+// DrainAbortedError is recorded against a signaller when drainers are aborted,
+// and names them.
+type DrainAbortedError struct {
+	Drainers []string
+}
+
+// Error returns a description of the aborted drainers.
+func (e *DrainAbortedError) Error() string {
+	return fmt.Sprintf("drains aborted: %v", e.Drainers)
+}
+
+// Drainers orchestrates the drains of a set of registered drainers between the
+// soft and hard stop of a signaller. Once a soft stop is signalled Run begins
+// each drain concurrently, and aborts those that have not finished once their
+// timeout elapses or a hard stop is signalled.
+type Drainers struct {
+	s *Signaller
+
+	mut      sync.Mutex
+	drainers []*registeredDrainer
+}
+
+type registeredDrainer struct {
+	name    string
+	timeout time.Duration
+	d       Drainer
+	state   DrainState
+	err     error
+}
+
+// NewDrainers creates an orchestrator of drainers that begin draining once the
+// provided signaller is signalled to soft stop.
+// The progress of each drainer is reported in a "drainers" section of the
+// diagnostics of the signaller.
+func NewDrainers(s *Signaller) *Drainers {
+	d := &Drainers{s: s}
+	s.AddDiagnostics("drainers", d.report)
+	return d
+}
+
+func (d *Drainers) report() string {
+	var b strings.Builder
+	for _, p := range d.Progress() {
+		fmt.Fprintf(&b, "%v: %v\n", p.Name, p.State)
+	}
+	return b.String()
+}
+
+// Register adds a named drainer, which is aborted if it has not drained once
+// the timeout elapses, when the timeout is greater than zero, or once a hard
+// stop is signalled.
+func (d *Drainers) Register(name string, timeout time.Duration, drainer Drainer) {
+	d.mut.Lock()
+	d.drainers = append(d.drainers, &registeredDrainer{name: name, timeout: timeout, d: drainer})
+	d.mut.Unlock()
+}
+
+// Progress returns the progress of each drainer, in the order that they were
+// registered.
+func (d *Drainers) Progress() []DrainProgress {
+	d.mut.Lock()
+	defer d.mut.Unlock()
+
+	progress := make([]DrainProgress, len(d.drainers))
+	for i, r := range d.drainers {
+		progress[i] = DrainProgress{Name: r.name, State: r.state, Err: r.err}
+	}
+	return progress
+}
+
+func (d *Drainers) setState(r *registeredDrainer, state DrainState, err error) {
+	d.mut.Lock()
+	r.state, r.err = state, err
+	d.mut.Unlock()
+}
+
+func (d *Drainers) drain(ctx context.Context, r *registeredDrainer) {
+	d.setState(r, DrainDraining, nil)
+	if err := r.d.BeginDrain(ctx); err != nil {
+		d.setState(r, DrainFailed, err)
+		return
+	}
+
+	var timeout <-chan time.Time
+	if r.timeout > 0 {
+		t := time.NewTimer(r.timeout)
+		defer t.Stop()
+		timeout = t.C
+	}
+	select {
+	case <-r.d.Drained():
+		d.setState(r, DrainDrained, nil)
+		return
+	case <-timeout:
+	case <-ctx.Done():
+	}
+	r.d.Abort()
+	d.setState(r, DrainAborted, nil)
+}
+
+// Run blocks until a soft stop is signalled and then drains each registered
+// drainer, triggering the signaller as having stopped once every drain has
+// finished, failed or been aborted. The errors of drains that failed to begin,
+// prefixed with the names of their drainers, and a *DrainAbortedError naming
+// the drainers that were aborted, are recorded against the signaller and
+// returned.
+func (d *Drainers) Run() error {
+	defer d.s.TriggerHasStopped()
+
+	<-d.s.SoftStopChan()
+
+	ctx, done := d.s.HardStopCtx(context.Background())
+	defer done()
+
+	d.mut.Lock()
+	drainers := d.drainers
+	d.mut.Unlock()
+
+	var wg sync.WaitGroup
+	for _, r := range drainers {
+		wg.Add(1)
+		go func(r *registeredDrainer) {
+			defer wg.Done()
+			d.drain(ctx, r)
+		}(r)
+	}
+	wg.Wait()
+
+	var (
+		errs    []error
+		aborted []string
+	)
+	for _, p := range d.Progress() {
+		switch p.State {
+		case DrainFailed:
+			errs = append(errs, fmt.Errorf("%v: %w", p.Name, p.Err))
+		case DrainAborted:
+			aborted = append(aborted, p.Name)
+		}
+	}
+	if len(aborted) > 0 {
+		errs = append(errs, &DrainAbortedError{Drainers: aborted})
+	}
+	err := errors.Join(errs...)
+	d.s.RecordStopErr(err)
+	return err
+}
@@ -0,0 +1,108 @@
+package shutdown
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeDrainer struct {
+	beginErr error
+	drained  chan struct{}
+	once     sync.Once
+	aborted  chan struct{}
+}
+
+func newFakeDrainer() *fakeDrainer {
+	return &fakeDrainer{drained: make(chan struct{}), aborted: make(chan struct{})}
+}
+
+func (d *fakeDrainer) BeginDrain(ctx context.Context) error {
+	return d.beginErr
+}
+
+func (d *fakeDrainer) Drained() <-chan struct{} {
+	return d.drained
+}
+
+func (d *fakeDrainer) finish() {
+	d.once.Do(func() { close(d.drained) })
+}
+
+func (d *fakeDrainer) Abort() {
+	close(d.aborted)
+}
+
+func runDrainers(d *Drainers) <-chan error {
+	errC := make(chan error, 1)
+	go func() {
+		errC <- d.Run()
+	}()
+	return errC
+}
+
+func TestDrainers(t *testing.T) {
+	s := NewSignaller()
+	d := NewDrainers(s)
+
+	a, b := newFakeDrainer(), newFakeDrainer()
+	d.Register("a", 0, a)
+	d.Register("b", time.Minute, b)
+	assert.Equal(t, []DrainProgress{
+		{Name: "a", State: DrainPending},
+		{Name: "b", State: DrainPending},
+	}, d.Progress())
+
+	errC := runDrainers(d)
+	s.TriggerSoftStop()
+
+	a.finish()
+	assert.Eventually(t, func() bool {
+		p := d.Progress()
+		return p[0].State == DrainDrained && p[1].State == DrainDraining
+	}, time.Second, time.Millisecond)
+	assertOpen(t, s.HasStoppedChan())
+
+	b.finish()
+	require.NoError(t, <-errC)
+	assertOpen(t, a.aborted)
+	assertClosed(t, s.HasStoppedChan())
+}
+
+func TestDrainersAborted(t *testing.T) {
+	s := NewSignaller()
+	d := NewDrainers(s)
+
+	errBegin := errors.New("cannot drain")
+	failing, slow, stuck := newFakeDrainer(), newFakeDrainer(), newFakeDrainer()
+	failing.beginErr = errBegin
+	d.Register("failing", 0, failing)
+	d.Register("slow", time.Millisecond, slow)
+	d.Register("stuck", 0, stuck)
+
+	errC := runDrainers(d)
+	s.TriggerSoftStop()
+
+	assert.Eventually(t, func() bool {
+		return d.Progress()[1].State == DrainAborted
+	}, time.Second, time.Millisecond)
+	assertClosed(t, slow.aborted)
+	assertOpen(t, stuck.aborted)
+
+	s.TriggerHardStop()
+	err := <-errC
+	assert.ErrorIs(t, err, errBegin)
+
+	var aborted *DrainAbortedError
+	require.ErrorAs(t, err, &aborted)
+	assert.Equal(t, []string{"slow", "stuck"}, aborted.Drainers)
+	assert.EqualError(t, err, "failing: cannot drain\ndrains aborted: [slow stuck]")
+	assert.ErrorIs(t, s.StopErr(), errBegin)
+	assertClosed(t, stuck.aborted)
+	assertClosed(t, s.HasStoppedChan())
+}
@@ -0,0 +1,71 @@
+package shutdown
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// The environment variables read by FromEnv.
+const (
+	// A duration after a soft stop at which a hard stop is triggered, see
+	// WithHardStopGrace.
+	EnvGracePeriod = "SHUTDOWN_GRACE_PERIOD"
+
+	// A duration that Close waits for the component to stop after a hard stop,
+	// see WithCloseTimeout.
+	EnvHardTimeout = "SHUTDOWN_HARD_TIMEOUT"
+
+	// A comma separated list of OS signals to listen for, see WithSignals.
+	EnvSignals = "SHUTDOWN_SIGNALS"
+)
+
+// FromEnv returns options configured from the environment variables
+// SHUTDOWN_GRACE_PERIOD, SHUTDOWN_HARD_TIMEOUT and SHUTDOWN_SIGNALS, allowing
+// the same binary to tune its shutdown behaviour per environment. Durations are
+// parsed with time.ParseDuration and signals with ParseSignal, for example:
+//
+//	SHUTDOWN_GRACE_PERIOD=20s
+//	SHUTDOWN_HARD_TIMEOUT=5s
+//	SHUTDOWN_SIGNALS=SIGINT,SIGTERM
+//
+// Variables that are unset or empty produce no options, and an error is
+// returned if any variable cannot be parsed.
+func FromEnv() ([]Option, error) {
+	var opts []Option
+
+	if v := os.Getenv(EnvGracePeriod); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return nil, fmt.Errorf("parsing %v: %w", EnvGracePeriod, err)
+		}
+		opts = append(opts, WithHardStopGrace(d))
+	}
+
+	if v := os.Getenv(EnvHardTimeout); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return nil, fmt.Errorf("parsing %v: %w", EnvHardTimeout, err)
+		}
+		opts = append(opts, WithCloseTimeout(d))
+	}
+
+	if v := os.Getenv(EnvSignals); v != "" {
+		var sigs []os.Signal
+		for _, name := range strings.Split(v, ",") {
+			if strings.TrimSpace(name) == "" {
+				continue
+			}
+			sig, err := ParseSignal(name)
+			if err != nil {
+				return nil, fmt.Errorf("parsing %v: %w", EnvSignals, err)
+			}
+			sigs = append(sigs, sig)
+		}
+		if len(sigs) > 0 {
+			opts = append(opts, WithSignals(sigs...))
+		}
+	}
+	return opts, nil
+}
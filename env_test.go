@@ -0,0 +1,61 @@
+package shutdown
+
+import (
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFromEnv(t *testing.T) {
+	t.Setenv(EnvGracePeriod, "20s")
+	t.Setenv(EnvHardTimeout, "5s")
+	t.Setenv(EnvSignals, "")
+
+	opts, err := FromEnv()
+	require.NoError(t, err)
+
+	cfg := NewSignaller(opts...).Config()
+	assert.Equal(t, time.Second*20, cfg.HardStopGrace)
+	assert.Equal(t, time.Second*5, cfg.CloseTimeout)
+	assert.Empty(t, cfg.Signals)
+}
+
+func TestFromEnvSignals(t *testing.T) {
+	t.Setenv(EnvGracePeriod, "")
+	t.Setenv(EnvHardTimeout, "")
+	t.Setenv(EnvSignals, "SIGINT, term,")
+
+	opts, err := FromEnv()
+	require.NoError(t, err)
+
+	s := NewSignaller(opts...)
+	defer func() {
+		s.TriggerHardStop()
+		s.TriggerHasStopped()
+	}()
+	assert.Equal(t, []os.Signal{syscall.SIGINT, syscall.SIGTERM}, s.Config().Signals)
+}
+
+func TestFromEnvErrors(t *testing.T) {
+	for _, test := range []struct {
+		key, value, err string
+	}{
+		{EnvGracePeriod, "soon", `parsing SHUTDOWN_GRACE_PERIOD: time: invalid duration "soon"`},
+		{EnvHardTimeout, "10", `parsing SHUTDOWN_HARD_TIMEOUT: time: missing unit in duration "10"`},
+		{EnvSignals, "SIGINT,SIGNOPE", `parsing SHUTDOWN_SIGNALS: unrecognised signal: "SIGNOPE"`},
+	} {
+		t.Run(test.key, func(t *testing.T) {
+			t.Setenv(EnvGracePeriod, "")
+			t.Setenv(EnvHardTimeout, "")
+			t.Setenv(EnvSignals, "")
+			t.Setenv(test.key, test.value)
+
+			_, err := FromEnv()
+			assert.EqualError(t, err, test.err)
+		})
+	}
+}
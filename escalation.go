@@ -0,0 +1,231 @@
+package shutdown
+
+import (
+	"context"
+	"os"
+	"sort"
+	"time"
+)
+
+// EscalationAction is the action taken by a step of an EscalationPolicy.
+type EscalationAction int
+
+// The actions of escalation steps.
+const (
+	// EscalateNone takes no action, and marks a milestone of the policy that
+	// components can observe with StepDeadline, such as the point at which a
+	// component should stop accepting new work.
+	EscalateNone EscalationAction = iota
+
+	// EscalateHardStop triggers a hard stop.
+	EscalateHardStop
+
+	// EscalateExit terminates the process with os.Exit, for components that
+	// have failed to stop even after a hard stop.
+	EscalateExit
+)
+
+// EscalationStep is a step of an EscalationPolicy.
+type EscalationStep struct {
+	// A name that identifies the step to StepDeadline.
+	Name string
+
+	// The duration after the soft stop at which the step is taken.
+	After time.Duration
+
+	Action EscalationAction
+}
+
+// EscalationPolicy is an ordered schedule of steps that are taken once a soft
+// stop has been signalled, for example:
+//
+//	shutdown.EscalationPolicy{
+//		Steps: []shutdown.EscalationStep{
+//			{Name: "drain", After: 10 * time.Second},
+//			{Name: "hard_stop", After: 25 * time.Second, Action: shutdown.EscalateHardStop},
+//			{Name: "exit", After: 30 * time.Second, Action: shutdown.EscalateExit},
+//		},
+//	}
+//
+// Steps that would escalate to a hard stop are cancelled once the hard stop is
+// signalled by other means, and all steps are cancelled once the component
+// has stopped or the soft stop is aborted.
+type EscalationPolicy struct {
+	Steps []EscalationStep
+
+	// The code passed to os.Exit by EscalateExit steps, which is 1 when zero.
+	ExitCode int
+}
+
+// osExit is replaced in tests.
+var osExit = os.Exit
+
+// sorted returns a copy of the policy with its steps in order.
+func (p EscalationPolicy) sorted() *EscalationPolicy {
+	p.Steps = append([]EscalationStep(nil), p.Steps...)
+	sort.SliceStable(p.Steps, func(i, j int) bool {
+		return p.Steps[i].After < p.Steps[j].After
+	})
+	return &p
+}
+
+// escalationSteps returns the steps configured for the signaller, either by
+// WithEscalationPolicy or WithHardStopGrace.
+func (o *options) escalationSteps() []EscalationStep {
+	if o.escalation != nil {
+		return o.escalation.Steps
+	}
+	if o.hardStopGrace > 0 {
+		return []EscalationStep{{Name: "hard_stop", After: o.hardStopGrace, Action: EscalateHardStop}}
+	}
+	return nil
+}
+
+type pendingStep struct {
+	action EscalationAction
+	at     time.Time
+	fn     func()
+	timer  Timer
+}
+
+// armEscalation schedules the steps of the escalation policy of the signaller,
+// and is called when a soft stop is signalled.
+func (s *Signaller) armEscalation() {
+	x := s.ext.Load()
+	if x == nil {
+		return
+	}
+	steps := x.escalationSteps()
+	if len(steps) == 0 {
+		return
+	}
+
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	if s.state.Load()&(TierHardStop.bit()|TierHasStopped.bit()) != 0 || x.softAt.Load() != 0 {
+		return
+	}
+
+	clock := s.clock()
+	now := clock.Now()
+	x.softAt.Store(now.UnixNano())
+
+	for _, step := range steps {
+		var fn func()
+		switch step.Action {
+		case EscalateHardStop:
+			if x.escalateAt.Load() == 0 {
+				x.escalateAt.Store(now.Add(step.After).UnixNano())
+			}
+			c := cause{source: SourceWatchdog, reason: "escalation " + step.Name}
+			fn = func() { s.triggerHardStop(c) }
+		case EscalateExit:
+			code := 1
+			if x.escalation != nil && x.escalation.ExitCode != 0 {
+				code = x.escalation.ExitCode
+			}
+			fn = func() { osExit(code) }
+		default:
+			continue
+		}
+		x.escalations = append(x.escalations, pendingStep{
+			action: step.Action,
+			at:     now.Add(step.After),
+			fn:     fn,
+			timer:  clock.AfterFunc(step.After, fn),
+		})
+	}
+}
+
+// disarmEscalation cancels any scheduled steps with an action up to and
+// including the provided action, where EscalateHardStop is used once a hard
+// stop has been signalled and EscalateExit cancels every step.
+func (s *Signaller) disarmEscalation(upTo EscalationAction) {
+	x := s.ext.Load()
+	if x == nil || len(x.escalationSteps()) == 0 {
+		return
+	}
+
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	pending := x.escalations[:0]
+	for _, p := range x.escalations {
+		if p.action <= upTo {
+			p.timer.Stop()
+		} else {
+			pending = append(pending, p)
+		}
+	}
+	x.escalations = pending
+	x.escalateAt.Store(0)
+	if upTo == EscalateExit {
+		x.softAt.Store(0)
+		x.extended.Store(0)
+	}
+}
+
+// StepDeadline returns the time at which the named step of the escalation
+// policy of the signaller is, or was, scheduled during the current soft stop.
+// Returns false if there is no step of that name or if no soft stop is
+// underway.
+func (s *Signaller) StepDeadline(name string) (time.Time, bool) {
+	if s == nil {
+		return time.Time{}, false
+	}
+	x := s.ext.Load()
+	if x == nil {
+		return time.Time{}, false
+	}
+	at := x.softAt.Load()
+	if at == 0 {
+		return time.Time{}, false
+	}
+	for _, step := range x.escalationSteps() {
+		if step.Name == name {
+			deadline := time.Unix(0, at).Add(step.After)
+			if step.Action != EscalateNone {
+				deadline = deadline.Add(time.Duration(x.extended.Load()))
+			}
+			return deadline, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// HardStopDeadline returns the time at which a hard stop is scheduled to be
+// triggered, which is only the case when the signaller was constructed with
+// WithHardStopGrace or an escalation policy and a soft stop is underway.
+// Returns false if no hard stop is scheduled.
+func (s *Signaller) HardStopDeadline() (time.Time, bool) {
+	if s == nil {
+		return time.Time{}, false
+	}
+	x := s.ext.Load()
+	if x == nil {
+		return time.Time{}, false
+	}
+	at := x.escalateAt.Load()
+	if at == 0 {
+		return time.Time{}, false
+	}
+	return time.Unix(0, at), true
+}
+
+// escalationDeadline returns the scheduled hard stop time when it applies to
+// contexts derived from a tier, which is the case for the soft and hard stop
+// tiers only.
+func (s *Signaller) escalationDeadline(t Tier) (time.Time, bool) {
+	if t != TierSoftStop && t != TierHardStop {
+		return time.Time{}, false
+	}
+	return s.HardStopDeadline()
+}
+
+// beforeDeadline returns true if the deadline is earlier than that of the
+// context, or if the context has no deadline.
+func beforeDeadline(ctx context.Context, deadline time.Time) bool {
+	cur, ok := ctx.Deadline()
+	return !ok || deadline.Before(cur)
+}
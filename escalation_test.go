@@ -0,0 +1,223 @@
+package shutdown
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHardStopGraceEscalates(t *testing.T) {
+	s := NewSignaller(WithHardStopGrace(time.Millisecond * 20))
+
+	_, ok := s.HardStopDeadline()
+	assert.False(t, ok)
+
+	s.TriggerSoftStop()
+	at, ok := s.HardStopDeadline()
+	require.True(t, ok)
+	assert.WithinDuration(t, time.Now().Add(time.Millisecond*20), at, time.Millisecond*20)
+
+	assertClosed(t, s.HardStopChan())
+
+	_, ok = s.HardStopDeadline()
+	assert.False(t, ok)
+}
+
+func TestHardStopGraceStopped(t *testing.T) {
+	s := NewSignaller(WithHardStopGrace(time.Millisecond * 10))
+	s.TriggerSoftStop()
+	s.TriggerHasStopped()
+
+	_, ok := s.HardStopDeadline()
+	assert.False(t, ok)
+
+	<-time.After(time.Millisecond * 30)
+	assert.False(t, s.IsHardStopSignalled())
+}
+
+func TestHardStopGraceAborted(t *testing.T) {
+	s := NewSignaller(WithHardStopGrace(time.Millisecond*10), WithReversibleSoftStop())
+	s.TriggerSoftStop()
+	require.True(t, s.AbortSoftStop())
+
+	<-time.After(time.Millisecond * 30)
+	assert.False(t, s.IsHardStopSignalled())
+}
+
+func TestHardStopGraceDeadline(t *testing.T) {
+	s := NewSignaller(WithHardStopGrace(time.Hour))
+
+	// Contexts derived before the soft stop have no deadline
+	hardCtx, hardDone := s.HardStopCtx(context.Background())
+	defer hardDone()
+
+	s.TriggerSoftStop()
+	at, ok := s.HardStopDeadline()
+	require.True(t, ok)
+
+	_, ok = hardCtx.Deadline()
+	assert.False(t, ok)
+
+	// Contexts derived during the drain report the hard stop
+	drainCtx, drainDone := s.HardStopCtx(context.Background())
+	defer drainDone()
+
+	deadline, ok := drainCtx.Deadline()
+	assert.True(t, ok)
+	assert.Equal(t, at, deadline)
+
+	softCtx, softDone := s.SoftStopCtx(context.Background())
+	defer softDone()
+
+	deadline, ok = softCtx.Deadline()
+	assert.True(t, ok)
+	assert.Equal(t, at, deadline)
+
+	pooledCtx := s.AcquireHardStopCtx(context.Background())
+	deadline, ok = pooledCtx.Deadline()
+	assert.True(t, ok)
+	assert.Equal(t, at, deadline)
+	pooledCtx.Release()
+
+	// An earlier parent deadline takes precedence
+	inCtx, inDone := context.WithTimeout(context.Background(), time.Minute)
+	defer inDone()
+
+	parentCtx, parentDone := s.HardStopCtx(inCtx)
+	defer parentDone()
+
+	parentDeadline, _ := inCtx.Deadline()
+	deadline, _ = parentCtx.Deadline()
+	assert.Equal(t, parentDeadline, deadline)
+
+	// Has stopped contexts are not bounded by the hard stop
+	stoppedCtx, stoppedDone := s.HasStoppedCtx(context.Background())
+	defer stoppedDone()
+
+	_, ok = stoppedCtx.Deadline()
+	assert.False(t, ok)
+}
+
+func TestHardStopGraceDeadlineErr(t *testing.T) {
+	clock := newManualClock()
+	s := NewSignaller(WithClock(clock), WithHardStopGrace(time.Second*10), WithExtensionLimit(time.Minute))
+	s.TriggerSoftStop()
+	at, ok := s.HardStopDeadline()
+	require.True(t, ok)
+
+	hardCtx, hardDone := s.HardStopCtx(context.Background())
+	defer hardDone()
+	pooledCtx := s.AcquireHardStopCtx(context.Background())
+	defer pooledCtx.Release()
+
+	// Deadlines are a snapshot taken at derivation, and so they are not
+	// moved by an extension, and contexts are cancelled after them.
+	s.RequestExtension("flush", time.Second*5)
+	for _, ctx := range []context.Context{hardCtx, pooledCtx} {
+		deadline, ok := ctx.Deadline()
+		require.True(t, ok)
+		assert.Equal(t, at, deadline)
+	}
+
+	clock.Advance(time.Second * 15)
+	require.True(t, s.IsHardStopSignalled())
+	assert.ErrorIs(t, hardCtx.Err(), context.DeadlineExceeded)
+	assert.ErrorIs(t, pooledCtx.Err(), context.DeadlineExceeded)
+
+	// Contexts cancelled by a hard stop before their deadline are cancelled
+	// rather than exceeded.
+	s = NewSignaller(WithClock(clock), WithHardStopGrace(time.Second*10))
+	s.TriggerSoftStop()
+	hardCtx, hardDone = s.HardStopCtx(context.Background())
+	defer hardDone()
+
+	s.TriggerHardStop()
+	assert.ErrorIs(t, hardCtx.Err(), context.Canceled)
+}
+
+func testEscalationPolicy() EscalationPolicy {
+	return EscalationPolicy{
+		Steps: []EscalationStep{
+			{Name: "exit", After: time.Second * 30, Action: EscalateExit},
+			{Name: "drain", After: time.Second * 10},
+			{Name: "hard_stop", After: time.Second * 25, Action: EscalateHardStop},
+		},
+		ExitCode: 3,
+	}
+}
+
+func TestEscalationPolicy(t *testing.T) {
+	var exitCodes []int
+	osExit = func(code int) { exitCodes = append(exitCodes, code) }
+	t.Cleanup(func() { osExit = os.Exit })
+
+	clock := newManualClock()
+	s := NewSignaller(WithClock(clock), WithEscalationPolicy(testEscalationPolicy()))
+
+	_, ok := s.StepDeadline("drain")
+	assert.False(t, ok)
+
+	start := clock.Now()
+	s.TriggerSoftStop()
+
+	at, ok := s.StepDeadline("drain")
+	require.True(t, ok)
+	assert.Equal(t, start.Add(time.Second*10), at)
+
+	at, ok = s.HardStopDeadline()
+	require.True(t, ok)
+	assert.Equal(t, start.Add(time.Second*25), at)
+
+	_, ok = s.StepDeadline("nope")
+	assert.False(t, ok)
+
+	clock.Advance(time.Second * 24)
+	assert.False(t, s.IsHardStopSignalled())
+
+	clock.Advance(time.Second)
+	assert.True(t, s.IsHardStopSignalled())
+	assert.Empty(t, exitCodes)
+
+	_, ok = s.HardStopDeadline()
+	assert.False(t, ok)
+
+	at, ok = s.StepDeadline("exit")
+	require.True(t, ok)
+	assert.Equal(t, start.Add(time.Second*30), at)
+
+	clock.Advance(time.Second * 5)
+	assert.Equal(t, []int{3}, exitCodes)
+
+	policy := s.Config().EscalationPolicy
+	require.NotNil(t, policy)
+	assert.Equal(t, "drain", policy.Steps[0].Name)
+}
+
+func TestEscalationPolicyStopped(t *testing.T) {
+	var exitCodes []int
+	osExit = func(code int) { exitCodes = append(exitCodes, code) }
+	t.Cleanup(func() { osExit = os.Exit })
+
+	clock := newManualClock()
+	s := NewSignaller(WithClock(clock), WithEscalationPolicy(testEscalationPolicy()))
+
+	// A manual hard stop cancels the hard stop step but not the exit
+	s.TriggerHardStop()
+	clock.Advance(time.Second * 30)
+	assert.Equal(t, []int{3}, exitCodes)
+
+	// Stopping cancels every step
+	s = NewSignaller(WithClock(clock), WithEscalationPolicy(testEscalationPolicy()))
+	s.TriggerSoftStop()
+	s.TriggerHasStopped()
+	clock.Advance(time.Minute)
+	assert.Equal(t, []int{3}, exitCodes)
+	assert.False(t, s.IsHardStopSignalled())
+
+	_, ok := s.StepDeadline("drain")
+	assert.False(t, ok)
+}
@@ -0,0 +1,313 @@
+package shutdown
+
+import (
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// EventKind describes the lifecycle transition of an Event.
+type EventKind int
+
+// The lifecycle transitions of a Signaller.
+const (
+	EventSoftStop EventKind = iota
+	EventHardStop
+	EventHasStopped
+	EventSoftStopAborted
+	EventReload
+)
+
+// String returns a human readable name of the event kind.
+func (k EventKind) String() string {
+	switch k {
+	case EventSoftStop:
+		return "soft stop"
+	case EventHardStop:
+		return "hard stop"
+	case EventHasStopped:
+		return "has stopped"
+	case EventSoftStopAborted:
+		return "soft stop aborted"
+	case EventReload:
+		return "reload"
+	}
+	return "unknown"
+}
+
+func tierEventKind(t Tier) EventKind {
+	switch t {
+	case TierHardStop:
+		return EventHardStop
+	case TierHasStopped:
+		return EventHasStopped
+	}
+	return EventSoftStop
+}
+
+// eventBit returns the bit of the tier that an event kind is a transition of,
+// or reloadBit for reloads, which are not a tier.
+func eventBit(k EventKind) uint32 {
+	switch k {
+	case EventHardStop:
+		return TierHardStop.bit()
+	case EventHasStopped:
+		return TierHasStopped.bit()
+	case EventReload:
+		return reloadBit
+	}
+	return TierSoftStop.bit()
+}
+
+// Event describes a lifecycle transition of a Signaller.
+type Event struct {
+	Kind EventKind
+	Time time.Time
+
+	// The attributes attached to the signaller at the time of the event, see
+	// TriggerSoftStopWith.
+	Attrs []slog.Attr
+
+	// The labels of the signaller, see WithLabels.
+	Labels map[string]string
+
+	// The source of the trigger that caused the transition.
+	TriggeredBy TriggerSource
+}
+
+// eventsBuffer is the default capacity of subscription channels.
+const eventsBuffer = 16
+
+// Delivery describes how events are delivered to a subscriber that is not
+// ready to receive them.
+type Delivery int
+
+// The delivery modes of subscriptions.
+const (
+	// DeliverDropNewest buffers events and drops new events while the buffer
+	// is full. This is the default.
+	DeliverDropNewest Delivery = iota
+
+	// DeliverDropOldest buffers events and drops the oldest buffered event to
+	// make room for a new one while the buffer is full.
+	DeliverDropOldest
+
+	// DeliverBlocking delivers every event, where events that the subscriber
+	// is not ready to receive are queued for a goroutine of the subscription
+	// that waits for the subscriber to receive them. Triggers never wait for
+	// the subscriber, and so a slow subscriber can stall neither a soft stop
+	// nor a hard stop, including those made by OS signals.
+	DeliverBlocking
+
+	// DeliverLatest coalesces events so that the subscriber only ever
+	// receives the most recent, which suits subscribers that track the
+	// current state of the signaller rather than each transition. The buffer
+	// size is ignored.
+	DeliverLatest
+)
+
+// SubscribeOption configures a subscription.
+type SubscribeOption func(sub *subscriber)
+
+// WithDelivery sets the delivery mode of a subscription.
+func WithDelivery(d Delivery) SubscribeOption {
+	return func(sub *subscriber) {
+		sub.delivery = d
+	}
+}
+
+// WithBuffer sets the capacity of the channel of a subscription, which is 16
+// by default.
+func WithBuffer(n int) SubscribeOption {
+	return func(sub *subscriber) {
+		sub.buffer = n
+	}
+}
+
+type subscriber struct {
+	delivery Delivery
+	buffer   int
+
+	mut    sync.Mutex
+	ch     chan Event
+	done   chan struct{}
+	closed bool
+
+	// Events queued for delivery by the goroutine of a DeliverBlocking
+	// subscription, which is woken by wake and closes pumped once it exits.
+	queue  []Event
+	wake   chan struct{}
+	pumped chan struct{}
+
+	// Set for subscribers registered with Notify, which deliver events
+	// for the tiers within the mask to a channel owned by the caller.
+	out   chan<- Event
+	tiers atomic.Uint32
+}
+
+func (sub *subscriber) deliver(e Event) {
+	sub.mut.Lock()
+	defer sub.mut.Unlock()
+
+	if sub.closed {
+		return
+	}
+	if sub.out != nil {
+		if sub.tiers.Load()&eventBit(e.Kind) != 0 {
+			select {
+			case sub.out <- e:
+			default:
+			}
+		}
+		return
+	}
+	if sub.delivery == DeliverBlocking {
+		// Events are always queued, even when the channel has room, so that
+		// they are received in order.
+		sub.queue = append(sub.queue, e)
+		select {
+		case sub.wake <- struct{}{}:
+		default:
+		}
+		return
+	}
+	select {
+	case sub.ch <- e:
+		return
+	default:
+	}
+
+	switch sub.delivery {
+	case DeliverDropOldest, DeliverLatest:
+		// The subscriber may concurrently receive, in which case the event
+		// need not replace anything.
+		select {
+		case <-sub.ch:
+		default:
+		}
+		select {
+		case sub.ch <- e:
+		default:
+		}
+	}
+}
+
+// pump delivers the queued events of a DeliverBlocking subscription until it
+// is cancelled.
+func (sub *subscriber) pump() {
+	defer close(sub.pumped)
+	for {
+		sub.mut.Lock()
+		if len(sub.queue) == 0 {
+			sub.mut.Unlock()
+			select {
+			case <-sub.wake:
+				continue
+			case <-sub.done:
+				return
+			}
+		}
+		e := sub.queue[0]
+		sub.queue = sub.queue[1:]
+		sub.mut.Unlock()
+
+		select {
+		case sub.ch <- e:
+		case <-sub.done:
+			return
+		}
+	}
+}
+
+// subscribers is a copy-on-write list of event subscribers.
+type subscribers struct {
+	list atomic.Pointer[[]*subscriber]
+}
+
+func (l *subscribers) update(fn func(subs []*subscriber) []*subscriber) {
+	for {
+		loaded := l.list.Load()
+		var subs []*subscriber
+		if loaded != nil {
+			subs = *loaded
+		}
+		next := fn(subs)
+		if l.list.CompareAndSwap(loaded, &next) {
+			return
+		}
+	}
+}
+
+func (s *Signaller) emit(kind EventKind, src TriggerSource) {
+	x := s.ext.Load()
+	if x == nil {
+		return
+	}
+	subs := x.subs.list.Load()
+	if subs == nil || len(*subs) == 0 {
+		return
+	}
+	e := Event{Kind: kind, Time: s.clock().Now(), Attrs: s.StopAttrs(), Labels: x.labels, TriggeredBy: src}
+	for _, sub := range *subs {
+		sub.deliver(e)
+	}
+}
+
+// Subscribe returns a channel that receives an Event for each lifecycle
+// transition of the signaller made after the call, and a function that ends the
+// subscription and closes the channel.
+//
+// By default events are delivered with non-blocking sends to a buffered
+// channel, and therefore a subscriber that falls behind misses events rather
+// than stalling the signaller. This can be changed with WithDelivery and
+// WithBuffer.
+func (s *Signaller) Subscribe(opts ...SubscribeOption) (events <-chan Event, cancel func()) {
+	if s == nil {
+		return nil, func() {}
+	}
+	sub := &subscriber{buffer: eventsBuffer, done: make(chan struct{})}
+	for _, o := range opts {
+		o(sub)
+	}
+	if sub.delivery == DeliverLatest || sub.buffer < 0 {
+		sub.buffer = 1
+	}
+	sub.ch = make(chan Event, sub.buffer)
+	if sub.delivery == DeliverBlocking {
+		sub.wake, sub.pumped = make(chan struct{}, 1), make(chan struct{})
+		go sub.pump()
+	}
+
+	x := s.extra()
+	x.subs.update(func(subs []*subscriber) []*subscriber {
+		next := make([]*subscriber, len(subs), len(subs)+1)
+		copy(next, subs)
+		return append(next, sub)
+	})
+
+	var once sync.Once
+	return sub.ch, func() {
+		once.Do(func() {
+			x.subs.update(func(subs []*subscriber) []*subscriber {
+				next := make([]*subscriber, 0, len(subs))
+				for _, e := range subs {
+					if e != sub {
+						next = append(next, e)
+					}
+				}
+				return next
+			})
+
+			// Stop any blocking delivery before closing the channel.
+			close(sub.done)
+			if sub.pumped != nil {
+				<-sub.pumped
+			}
+			sub.mut.Lock()
+			sub.closed = true
+			close(sub.ch)
+			sub.mut.Unlock()
+		})
+	}
+}
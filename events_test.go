@@ -0,0 +1,167 @@
+package shutdown
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func readEvents(t *testing.T, events <-chan Event) (kinds []EventKind) {
+	t.Helper()
+	for {
+		select {
+		case e, open := <-events:
+			if !open {
+				return
+			}
+			assert.False(t, e.Time.IsZero())
+			kinds = append(kinds, e.Kind)
+		default:
+			return
+		}
+	}
+}
+
+func TestSubscribe(t *testing.T) {
+	s := NewSignaller()
+	events, cancel := s.Subscribe()
+
+	s.TriggerHardStop()
+	s.TriggerHasStopped()
+	assert.Equal(t, []EventKind{EventSoftStop, EventHardStop, EventHasStopped}, readEvents(t, events))
+
+	cancel()
+	cancel()
+	_, open := <-events
+	assert.False(t, open)
+}
+
+func TestSubscribeSlowConsumer(t *testing.T) {
+	s := NewSignaller(WithReversibleSoftStop())
+	events, cancel := s.Subscribe()
+	defer cancel()
+
+	for i := 0; i < eventsBuffer; i++ {
+		s.TriggerSoftStop()
+		require.True(t, s.AbortSoftStop())
+	}
+	assert.Len(t, readEvents(t, events), eventsBuffer)
+}
+
+func TestAbortSoftStop(t *testing.T) {
+	s := NewSignaller(WithReversibleSoftStop())
+	events, cancel := s.Subscribe()
+	defer cancel()
+
+	assert.False(t, s.AbortSoftStop())
+
+	var hookCalls int
+	s.OnSoftStop(func() { hookCalls++ })
+
+	oldChan := s.SoftStopChan()
+	s.TriggerSoftStop()
+	assert.True(t, s.IsSoftStopSignalled())
+	assert.Equal(t, 1, hookCalls)
+
+	assert.True(t, s.AbortSoftStop())
+	assert.False(t, s.IsSoftStopSignalled())
+	assertClosed(t, oldChan)
+	assertOpen(t, s.SoftStopChan())
+
+	s.OnSoftStop(func() { hookCalls++ })
+	s.TriggerHardStop()
+	assert.True(t, s.IsSoftStopSignalled())
+	assertClosed(t, s.SoftStopChan())
+	assert.Equal(t, 2, hookCalls)
+
+	assert.False(t, s.AbortSoftStop())
+	assert.Equal(t, []EventKind{
+		EventSoftStop, EventSoftStopAborted, EventSoftStop, EventHardStop,
+	}, readEvents(t, events))
+}
+
+func TestAbortSoftStopDisabled(t *testing.T) {
+	s := NewSignaller()
+	s.TriggerSoftStop()
+	assert.False(t, s.AbortSoftStop())
+	assert.True(t, s.IsSoftStopSignalled())
+}
+
+func TestSubscribeDropOldest(t *testing.T) {
+	s := NewSignaller()
+	events, cancel := s.Subscribe(WithDelivery(DeliverDropOldest), WithBuffer(2))
+	defer cancel()
+
+	s.TriggerHardStop()
+	s.TriggerHasStopped()
+	assert.Equal(t, []EventKind{EventHardStop, EventHasStopped}, readEvents(t, events))
+}
+
+func TestSubscribeLatest(t *testing.T) {
+	s := NewSignaller()
+	events, cancel := s.Subscribe(WithDelivery(DeliverLatest), WithBuffer(10))
+	defer cancel()
+
+	s.TriggerHardStop()
+	assert.Equal(t, []EventKind{EventHardStop}, readEvents(t, events))
+
+	s.TriggerHasStopped()
+	assert.Equal(t, []EventKind{EventHasStopped}, readEvents(t, events))
+}
+
+func TestSubscribeBlocking(t *testing.T) {
+	s := NewSignaller(WithReversibleSoftStop())
+	events, cancel := s.Subscribe(WithDelivery(DeliverBlocking), WithBuffer(0))
+	defer cancel()
+
+	// Triggers never wait for the subscriber, and no event is dropped.
+	for i := 0; i < eventsBuffer*2; i++ {
+		s.TriggerSoftStop()
+		require.True(t, s.AbortSoftStop())
+	}
+	s.TriggerHardStop()
+	s.TriggerHasStopped()
+
+	for i := 0; i < eventsBuffer*2; i++ {
+		assert.Equal(t, EventSoftStop, (<-events).Kind)
+		assert.Equal(t, EventSoftStopAborted, (<-events).Kind)
+	}
+	assert.Equal(t, EventSoftStop, (<-events).Kind)
+	assert.Equal(t, EventHardStop, (<-events).Kind)
+	assert.Equal(t, EventHasStopped, (<-events).Kind)
+}
+
+func TestSubscribeBlockingNeverStalls(t *testing.T) {
+	s := NewSignaller()
+	_, cancel := s.Subscribe(WithDelivery(DeliverBlocking), WithBuffer(0))
+	defer cancel()
+
+	stopped := make(chan struct{})
+	go func() {
+		s.TriggerSoftStop()
+		s.TriggerHardStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(time.Second * 5):
+		t.Fatal("triggers waited for a blocking subscriber")
+	}
+}
+
+func TestSubscribeBlockingCancel(t *testing.T) {
+	s := NewSignaller()
+	events, cancel := s.Subscribe(WithDelivery(DeliverBlocking), WithBuffer(0))
+
+	// Cancelling abandons queued events that were never received.
+	s.TriggerHardStop()
+	time.Sleep(time.Millisecond * 10)
+	cancel()
+
+	for range events {
+	}
+	_, open := <-events
+	assert.False(t, open)
+}
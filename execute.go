@@ -0,0 +1,82 @@
+package shutdown
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ExecuteError is returned by Execute when a signaller does not report having
+// stopped, and describes how far the shutdown got.
+type ExecuteError struct {
+	// The last tier that was triggered, which is TierSoftStop or TierHardStop.
+	Tier Tier
+
+	// Wraps ErrStopTimeout when the grace of the tier elapsed, or otherwise the
+	// error of the context provided to Execute.
+	Err error
+}
+
+// Error returns a description of how far the shutdown got.
+func (e *ExecuteError) Error() string {
+	return fmt.Sprintf("shutdown stalled after %v: %v", e.Tier, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *ExecuteError) Unwrap() error {
+	return e.Err
+}
+
+// Execute performs an orchestrated shutdown of a signaller. A soft stop is
+// triggered and, if the signaller has not reported having stopped once
+// softGrace elapses, a hard stop is triggered. Execute then waits for up to
+// hardGrace for the signaller to stop and returns the result of StopErr.
+//
+// A softGrace of zero or less triggers the hard stop immediately, and a
+// hardGrace of zero or less waits for the signaller to stop indefinitely. The
+// grace periods are measured with the clock of the signaller.
+//
+// If the signaller does not stop in time, or the context is cancelled, an
+// *ExecuteError is returned describing the last tier that was triggered.
+func Execute(ctx context.Context, s *Signaller, softGrace, hardGrace time.Duration) error {
+	if softGrace > 0 {
+		s.TriggerSoftStop()
+		err := executeWait(ctx, s, softGrace)
+		if err == nil {
+			return s.StopErr()
+		}
+		if !errors.Is(err, ErrStopTimeout) {
+			return &ExecuteError{Tier: TierSoftStop, Err: err}
+		}
+	}
+
+	s.TriggerHardStop()
+	if err := executeWait(ctx, s, hardGrace); err != nil {
+		return &ExecuteError{Tier: TierHardStop, Err: err}
+	}
+	return s.StopErr()
+}
+
+// executeWait blocks until the signaller has stopped, returning an error
+// wrapping ErrStopTimeout if the grace elapses first, or the error of the
+// context if it is cancelled first.
+func executeWait(ctx context.Context, s *Signaller, grace time.Duration) error {
+	var elapsed chan struct{}
+	if grace > 0 {
+		elapsed = make(chan struct{})
+		t := s.clock().AfterFunc(grace, func() {
+			close(elapsed)
+		})
+		defer t.Stop()
+	}
+
+	select {
+	case <-s.HasStoppedChan():
+		return nil
+	case <-elapsed:
+		return fmt.Errorf("%w: grace of %v elapsed", ErrStopTimeout, grace)
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,72 @@
+package shutdown
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecuteSoftStop(t *testing.T) {
+	s := NewSignaller()
+	s.OnSoftStop(func() {
+		go func() {
+			s.RecordStopErr(errors.New("flush failed"))
+			s.TriggerHasStopped()
+		}()
+	})
+
+	err := Execute(context.Background(), s, time.Minute, time.Minute)
+	assert.EqualError(t, err, "flush failed")
+	assert.False(t, s.IsHardStopSignalled())
+}
+
+func TestExecuteEscalates(t *testing.T) {
+	s := NewSignaller()
+	s.OnHardStop(func() {
+		go s.TriggerHasStopped()
+	})
+
+	require.NoError(t, Execute(context.Background(), s, time.Millisecond, time.Minute))
+	assert.True(t, s.IsHardStopSignalled())
+}
+
+func TestExecuteNoSoftGrace(t *testing.T) {
+	s := NewSignaller()
+	var softFirst bool
+	s.OnHardStop(func() {
+		softFirst = s.IsSoftStopSignalled()
+		go s.TriggerHasStopped()
+	})
+
+	require.NoError(t, Execute(context.Background(), s, 0, 0))
+	assert.True(t, softFirst)
+}
+
+func TestExecuteStalled(t *testing.T) {
+	s := NewSignaller()
+
+	err := Execute(context.Background(), s, time.Millisecond, time.Millisecond)
+	var execErr *ExecuteError
+	require.ErrorAs(t, err, &execErr)
+	assert.Equal(t, TierHardStop, execErr.Tier)
+	assert.ErrorIs(t, err, ErrStopTimeout)
+	assert.EqualError(t, err, "shutdown stalled after hard stop: timed out waiting to stop: grace of 1ms elapsed")
+}
+
+func TestExecuteCancelled(t *testing.T) {
+	s := NewSignaller()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.OnSoftStop(cancel)
+
+	err := Execute(ctx, s, time.Minute, time.Minute)
+	var execErr *ExecuteError
+	require.ErrorAs(t, err, &execErr)
+	assert.Equal(t, TierSoftStop, execErr.Tier)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.False(t, s.IsHardStopSignalled())
+}
@@ -0,0 +1,77 @@
+package shutdown
+
+import "os"
+
+// StopOutcome describes how a program stopped, as determined by Run.
+type StopOutcome struct {
+	// The first OS signal received, or nil if the stop was not caused by one.
+	Signal os.Signal
+
+	// Whether a hard stop was signalled.
+	HardStop bool
+
+	// Whether the program failed to stop within the close timeout after a
+	// hard stop.
+	TimedOut bool
+
+	// The errors of the program, including those recorded against the
+	// signaller.
+	Err error
+}
+
+// ExitCodes maps the outcome of a program to the exit code returned by Run,
+// which allows orchestrators to choose restart policies by how the program
+// stopped.
+type ExitCodes struct {
+	// The program stopped without errors of its own accord or by a soft stop
+	// that was not caused by an OS signal.
+	Clean int
+
+	// The program stopped without errors after receiving an OS signal. When
+	// negative the code is 128 plus the number of the signal, following the
+	// convention of shells.
+	Signalled int
+
+	// The program stopped without errors after a hard stop.
+	HardStopped int
+
+	// The program returned errors, or errors were recorded against its
+	// signaller.
+	Failed int
+
+	// The program did not stop within the close timeout after a hard stop.
+	TimedOut int
+}
+
+// DefaultExitCodes are the exit codes used by Run unless configured otherwise
+// with WithExitCodes, which distinguish only between success and failure.
+var DefaultExitCodes = ExitCodes{Failed: 1, TimedOut: 1}
+
+// Code returns the exit code of an outcome, where a timeout takes precedence
+// over failure, failure over a hard stop and a hard stop over a signal.
+func (c ExitCodes) Code(o StopOutcome) int {
+	switch {
+	case o.TimedOut:
+		return c.TimedOut
+	case o.Err != nil:
+		return c.Failed
+	case o.HardStop:
+		return c.HardStopped
+	case o.Signal != nil:
+		if c.Signalled < 0 {
+			if n, ok := signalNumber(o.Signal); ok {
+				return 128 + n
+			}
+			return 1
+		}
+		return c.Signalled
+	}
+	return c.Clean
+}
+
+// WithExitCodes configures the exit codes returned by Run.
+func WithExitCodes(c ExitCodes) Option {
+	return func(o *options) {
+		o.exitCodes = &c
+	}
+}
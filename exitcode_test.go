@@ -0,0 +1,41 @@
+package shutdown
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExitCodes(t *testing.T) {
+	codes := ExitCodes{Clean: 0, Signalled: 3, HardStopped: 4, Failed: 5, TimedOut: 6}
+	errFailed := errors.New("failed")
+
+	for _, test := range []struct {
+		name    string
+		outcome StopOutcome
+		code    int
+	}{
+		{"clean", StopOutcome{}, 0},
+		{"signalled", StopOutcome{Signal: os.Interrupt}, 3},
+		{"hard stopped", StopOutcome{Signal: os.Interrupt, HardStop: true}, 4},
+		{"failed", StopOutcome{HardStop: true, Err: errFailed}, 5},
+		{"timed out", StopOutcome{HardStop: true, TimedOut: true, Err: errFailed}, 6},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.code, codes.Code(test.outcome))
+		})
+	}
+
+	codes.Signalled = -1
+	if n, ok := signalNumber(os.Interrupt); ok {
+		assert.Equal(t, 128+n, codes.Code(StopOutcome{Signal: os.Interrupt}))
+	}
+}
+
+func TestDefaultExitCodes(t *testing.T) {
+	assert.Equal(t, 0, DefaultExitCodes.Code(StopOutcome{Signal: os.Interrupt, HardStop: true}))
+	assert.Equal(t, 1, DefaultExitCodes.Code(StopOutcome{Err: errors.New("failed")}))
+	assert.Equal(t, 1, DefaultExitCodes.Code(StopOutcome{TimedOut: true}))
+}
@@ -0,0 +1,92 @@
+package shutdown
+
+import (
+	"log/slog"
+	"time"
+)
+
+// WithExtensionLimit allows components to postpone the escalation to a hard
+// stop with RequestExtension, by up to the provided total duration during each
+// soft stop. Without this option extension requests are refused.
+func WithExtensionLimit(limit time.Duration) Option {
+	return func(o *options) {
+		o.extensionLimit = limit
+	}
+}
+
+// Extension is a record of a call to RequestExtension.
+type Extension struct {
+	Reason string
+	Time   time.Time
+
+	// The duration requested, and the duration by which escalation was
+	// actually postponed, which is zero when the request was refused.
+	Requested time.Duration
+	Granted   time.Duration
+}
+
+// RequestExtension asks for the escalation of a soft stop to be postponed by
+// the provided duration, for components that are draining and need more time
+// to finish, such as a large flush. The hard stop, and any later steps of an
+// escalation policy, are rescheduled by the duration granted, which is capped
+// by the remainder of the limit configured with WithExtensionLimit. Returns the
+// duration granted, which is zero if no escalation is scheduled or the limit
+// has been exhausted.
+//
+// Each request is recorded along with the provided reason, and is listed by
+// Extensions and within the diagnostics of the signaller.
+func (s *Signaller) RequestExtension(reason string, d time.Duration) time.Duration {
+	if s == nil || d <= 0 {
+		return 0
+	}
+	x := s.ext.Load()
+	if x == nil {
+		return 0
+	}
+	clock := s.clock()
+	now := clock.Now()
+
+	s.mut.Lock()
+	granted := min(d, x.extensionLimit-time.Duration(x.extended.Load()))
+	if x.escalateAt.Load() == 0 || s.state.Load()&(TierHardStop.bit()|TierHasStopped.bit()) != 0 {
+		granted = 0
+	}
+	if granted > 0 {
+		for i, p := range x.escalations {
+			// Steps that have already been taken are left alone.
+			if !p.timer.Stop() {
+				continue
+			}
+			p.at = p.at.Add(granted)
+			p.timer = clock.AfterFunc(p.at.Sub(now), p.fn)
+			x.escalations[i] = p
+		}
+		x.escalateAt.Add(int64(granted))
+		x.extended.Add(int64(granted))
+	}
+	x.extensions = append(x.extensions, Extension{
+		Reason:    reason,
+		Time:      now,
+		Requested: d,
+		Granted:   granted,
+	})
+	s.mut.Unlock()
+
+	x.log(slog.LevelInfo, "shutdown extension requested", "reason", reason, "requested", d, "granted", granted)
+	return granted
+}
+
+// Extensions returns a record of each call to RequestExtension, in the order
+// they were made.
+func (s *Signaller) Extensions() []Extension {
+	if s == nil {
+		return nil
+	}
+	x := s.ext.Load()
+	if x == nil {
+		return nil
+	}
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	return append([]Extension(nil), x.extensions...)
+}
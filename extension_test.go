@@ -0,0 +1,99 @@
+package shutdown
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestExtension(t *testing.T) {
+	clock := newManualClock()
+	s := NewSignaller(WithClock(clock), WithHardStopGrace(time.Second*10), WithExtensionLimit(time.Second*15))
+
+	assert.Zero(t, s.RequestExtension("too early", time.Second))
+
+	start := clock.Now()
+	s.TriggerSoftStop()
+
+	assert.Equal(t, time.Second*10, s.RequestExtension("large flush", time.Second*10))
+	at, ok := s.HardStopDeadline()
+	require.True(t, ok)
+	assert.Equal(t, start.Add(time.Second*20), at)
+
+	at, ok = s.StepDeadline("hard_stop")
+	require.True(t, ok)
+	assert.Equal(t, start.Add(time.Second*20), at)
+
+	// Only the remainder of the limit is granted.
+	assert.Equal(t, time.Second*5, s.RequestExtension("another flush", time.Second*10))
+	assert.Zero(t, s.RequestExtension("one more", time.Second))
+
+	clock.Advance(time.Second * 24)
+	assert.False(t, s.IsHardStopSignalled())
+
+	clock.Advance(time.Second)
+	assert.True(t, s.IsHardStopSignalled())
+
+	assert.Zero(t, s.RequestExtension("too late", time.Second))
+
+	exts := s.Extensions()
+	require.Len(t, exts, 5)
+	assert.Equal(t, Extension{Reason: "large flush", Time: start, Requested: time.Second * 10, Granted: time.Second * 10}, exts[1])
+	assert.Equal(t, time.Second*5, exts[2].Granted)
+	assert.Zero(t, exts[3].Granted)
+}
+
+func TestRequestExtensionNoLimit(t *testing.T) {
+	clock := newManualClock()
+	s := NewSignaller(WithClock(clock), WithHardStopGrace(time.Second))
+	s.TriggerSoftStop()
+
+	assert.Zero(t, s.RequestExtension("flush", time.Second))
+	clock.Advance(time.Second)
+	assert.True(t, s.IsHardStopSignalled())
+
+	var nilS *Signaller
+	assert.Zero(t, nilS.RequestExtension("flush", time.Second))
+	assert.Empty(t, nilS.Extensions())
+}
+
+func TestRequestExtensionPolicy(t *testing.T) {
+	clock := newManualClock()
+	s := NewSignaller(WithClock(clock), WithEscalationPolicy(testEscalationPolicy()), WithExtensionLimit(time.Minute))
+
+	start := clock.Now()
+	s.TriggerSoftStop()
+	assert.Equal(t, time.Second*10, s.RequestExtension("flush", time.Second*10))
+
+	// Milestones are not postponed, but the steps that act are.
+	at, ok := s.StepDeadline("drain")
+	require.True(t, ok)
+	assert.Equal(t, start.Add(time.Second*10), at)
+
+	at, ok = s.StepDeadline("exit")
+	require.True(t, ok)
+	assert.Equal(t, start.Add(time.Second*40), at)
+
+	clock.Advance(time.Second * 34)
+	assert.False(t, s.IsHardStopSignalled())
+	clock.Advance(time.Second)
+	assert.True(t, s.IsHardStopSignalled())
+}
+
+func TestRequestExtensionDiagnostics(t *testing.T) {
+	clock := newManualClock()
+	s := NewSignaller(WithName("foo"), WithClock(clock), WithHardStopGrace(time.Second), WithExtensionLimit(time.Second))
+	s.TriggerSoftStop()
+	s.RequestExtension("flush", time.Second*2)
+
+	d := s.Diagnostics()
+	require.Len(t, d.Extensions, 1)
+
+	var buf bytes.Buffer
+	_, err := d.WriteTo(&buf)
+	require.NoError(t, err)
+	assert.Contains(t, buf.String(), "  extensions:\n    flush: requested 2s, granted 1s at ")
+}
@@ -0,0 +1,78 @@
+package shutdown
+
+import (
+	"flag"
+	"time"
+)
+
+// The names of the flags registered by RegisterFlags.
+const (
+	// A duration after a soft stop at which a hard stop is triggered, see
+	// WithHardStopGrace.
+	FlagGrace = "shutdown-grace"
+
+	// A duration that Close waits for the component to stop after a hard stop,
+	// see WithCloseTimeout.
+	FlagHardTimeout = "shutdown-hard-timeout"
+)
+
+// durationFlag is a flag.Value holding a duration that records whether it was
+// set.
+type durationFlag struct {
+	d   time.Duration
+	set bool
+}
+
+func (f *durationFlag) String() string {
+	if f == nil || !f.set {
+		return ""
+	}
+	return f.d.String()
+}
+
+func (f *durationFlag) Set(v string) error {
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		return err
+	}
+	f.d, f.set = d, true
+	return nil
+}
+
+// Flags holds the values of the shutdown flags of a program, as registered with
+// RegisterFlags.
+type Flags struct {
+	grace       durationFlag
+	hardTimeout durationFlag
+}
+
+// RegisterFlags registers the flags --shutdown-grace and --shutdown-hard-timeout
+// with a flag set, or with flag.CommandLine when the flag set is nil, so that
+// programs expose consistent shutdown knobs:
+//
+//	shutdownFlags := shutdown.RegisterFlags(nil)
+//	flag.Parse()
+//	s := shutdown.NewSignaller(shutdownFlags.Options()...)
+func RegisterFlags(fs *flag.FlagSet) *Flags {
+	if fs == nil {
+		fs = flag.CommandLine
+	}
+	f := &Flags{}
+	fs.Var(&f.grace, FlagGrace, "the duration after a soft stop at which a hard stop is triggered, e.g. 20s")
+	fs.Var(&f.hardTimeout, FlagHardTimeout, "the duration to wait for components to stop after a hard stop, e.g. 5s")
+	return f
+}
+
+// Options returns the options configured by the flags that were set, which
+// must be called once the flag set has been parsed. Flags that were not set
+// produce no options, leaving the defaults of the program in place.
+func (f *Flags) Options() []Option {
+	var opts []Option
+	if f.grace.set {
+		opts = append(opts, WithHardStopGrace(f.grace.d))
+	}
+	if f.hardTimeout.set {
+		opts = append(opts, WithCloseTimeout(f.hardTimeout.d))
+	}
+	return opts
+}
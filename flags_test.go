@@ -0,0 +1,35 @@
+package shutdown
+
+import (
+	"flag"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegisterFlags(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	f := RegisterFlags(fs)
+	require.NoError(t, fs.Parse([]string{"--shutdown-grace=20s", "--shutdown-hard-timeout", "0s"}))
+
+	cfg := NewSignaller(f.Options()...).Config()
+	assert.Equal(t, time.Second*20, cfg.HardStopGrace)
+	assert.Equal(t, time.Duration(0), cfg.CloseTimeout)
+}
+
+func TestRegisterFlagsUnset(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	f := RegisterFlags(fs)
+	require.NoError(t, fs.Parse(nil))
+	assert.Empty(t, f.Options())
+}
+
+func TestRegisterFlagsInvalid(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	RegisterFlags(fs)
+	assert.ErrorContains(t, fs.Parse([]string{"--shutdown-grace=soon"}), "shutdown-grace")
+}
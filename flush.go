@@ -0,0 +1,87 @@
+package shutdown
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Flushers is a registry of functions that flush buffered state, such as log
+// buffers, metric aggregators and write-behind caches, which are called once a
+// soft stop has been signalled and before the signaller is triggered as having
+// stopped, so that the last moments of a program are not lost.
+type Flushers struct {
+	s *Signaller
+
+	mut      sync.Mutex
+	flushers []flusher
+}
+
+type flusher struct {
+	name    string
+	timeout time.Duration
+	fn      func(ctx context.Context) error
+}
+
+// NewFlushers creates a registry of flushers that are called once the provided
+// signaller is signalled to soft stop.
+func NewFlushers(s *Signaller) *Flushers {
+	return &Flushers{s: s}
+}
+
+// Register adds a named flush function to the registry. The function is called
+// with a context that is cancelled once a hard stop has been signalled, or once
+// the timeout elapses when it is greater than zero.
+func (f *Flushers) Register(name string, timeout time.Duration, fn func(ctx context.Context) error) {
+	f.mut.Lock()
+	f.flushers = append(f.flushers, flusher{name: name, timeout: timeout, fn: fn})
+	f.mut.Unlock()
+}
+
+// Flush calls each registered flush function concurrently and blocks until
+// they have all returned. The errors of the functions are prefixed with their
+// names and joined in the order that the functions were registered, and are
+// also recorded against the signaller.
+func (f *Flushers) Flush(ctx context.Context) error {
+	f.mut.Lock()
+	flushers := f.flushers
+	f.mut.Unlock()
+
+	ctx, done := f.s.HardStopCtx(ctx)
+	defer done()
+
+	errs := make([]error, len(flushers))
+	var wg sync.WaitGroup
+	for i, fl := range flushers {
+		wg.Add(1)
+		go func(i int, fl flusher) {
+			defer wg.Done()
+
+			fctx := ctx
+			if fl.timeout > 0 {
+				var cancel context.CancelFunc
+				fctx, cancel = context.WithTimeout(ctx, fl.timeout)
+				defer cancel()
+			}
+			if err := fl.fn(fctx); err != nil {
+				errs[i] = fmt.Errorf("%v: %w", fl.name, err)
+			}
+		}(i, fl)
+	}
+	wg.Wait()
+
+	err := errors.Join(errs...)
+	f.s.RecordStopErr(err)
+	return err
+}
+
+// Run blocks until a soft stop is signalled, calls Flush and then triggers the
+// signaller as having stopped.
+func (f *Flushers) Run() error {
+	defer f.s.TriggerHasStopped()
+
+	<-f.s.SoftStopChan()
+	return f.Flush(context.Background())
+}
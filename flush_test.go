@@ -0,0 +1,89 @@
+package shutdown
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFlushers(t *testing.T) {
+	s := NewSignaller()
+	f := NewFlushers(s)
+
+	var flushed []string
+	flushedC := make(chan string, 2)
+	f.Register("logs", 0, func(ctx context.Context) error {
+		flushedC <- "logs"
+		return nil
+	})
+	f.Register("metrics", time.Second, func(ctx context.Context) error {
+		flushedC <- "metrics"
+		return nil
+	})
+
+	errC := make(chan error, 1)
+	go func() {
+		errC <- f.Run()
+	}()
+	assertOpen(t, s.HasStoppedChan())
+
+	s.TriggerSoftStop()
+	require.NoError(t, <-errC)
+	close(flushedC)
+	for name := range flushedC {
+		flushed = append(flushed, name)
+	}
+	assert.ElementsMatch(t, []string{"logs", "metrics"}, flushed)
+	assertClosed(t, s.HasStoppedChan())
+}
+
+func TestFlushersErrors(t *testing.T) {
+	s := NewSignaller()
+	f := NewFlushers(s)
+
+	errCache := errors.New("cache unavailable")
+	f.Register("slow", time.Millisecond, func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	f.Register("cache", 0, func(ctx context.Context) error {
+		return errCache
+	})
+	f.Register("ok", 0, func(ctx context.Context) error {
+		return nil
+	})
+
+	err := f.Flush(context.Background())
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.ErrorIs(t, err, errCache)
+	assert.EqualError(t, err, "slow: context deadline exceeded\ncache: cache unavailable")
+	assert.ErrorIs(t, s.StopErr(), errCache)
+}
+
+func TestFlushersHardStop(t *testing.T) {
+	s := NewSignaller()
+	f := NewFlushers(s)
+
+	f.Register("stuck", 0, func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	errC := make(chan error, 1)
+	go func() {
+		errC <- f.Run()
+	}()
+
+	s.TriggerHardStop()
+	select {
+	case err := <-errC:
+		assert.ErrorIs(t, err, context.Canceled)
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for flush")
+	}
+	assertClosed(t, s.HasStoppedChan())
+}
@@ -0,0 +1,120 @@
+package shutdown
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+// ErrGateClosed is returned by Gate.Enter once the signaller of the gate has
+// been signalled to soft stop, and wraps ErrSoftStop.
+var ErrGateClosed = fmt.Errorf("gate closed: %w", ErrSoftStop)
+
+// Gate admits units of work, such as requests or commands, while a signaller is
+// running and tracks those in flight, allowing a component to reject new work
+// once a soft stop has been signalled and to wait for the work it has already
+// admitted to finish.
+type Gate struct {
+	s *Signaller
+
+	// The number of units of work in flight, and the number of calls to Wait
+	// that are blocked, which Exit checks before taking the mutex.
+	n       atomic.Int64
+	waiting atomic.Int64
+
+	mut  sync.Mutex
+	idle chan struct{} // Created lazily by Wait
+}
+
+// NewGate creates a gate that closes once the provided signaller is signalled
+// to soft stop.
+func NewGate(s *Signaller) *Gate {
+	return &Gate{s: s}
+}
+
+// Signaller returns the signaller of the gate.
+func (g *Gate) Signaller() *Signaller {
+	return g.s
+}
+
+// Enter admits a unit of work, which must be followed by a call to Exit once
+// the work has finished. Returns ErrGateClosed, and admits nothing, if a soft
+// stop has been signalled.
+func (g *Gate) Enter() error {
+	// The work is counted before checking for a soft stop, so that Wait after
+	// a soft stop either observes the work or the work observes the stop. The
+	// state bit of the stop is stored before its channel is closed, and so
+	// this holds for a Wait that follows a receive on SoftStopChan.
+	g.n.Add(1)
+	if g.s.IsSoftStopSignalled() {
+		g.Exit()
+		return ErrGateClosed
+	}
+	return nil
+}
+
+// Exit marks a unit of work admitted by Enter as finished.
+func (g *Gate) Exit() {
+	if g.n.Add(-1) != 0 || g.waiting.Load() == 0 {
+		return
+	}
+	g.mut.Lock()
+	if g.idle != nil && g.n.Load() == 0 {
+		close(g.idle)
+		g.idle = nil
+	}
+	g.mut.Unlock()
+}
+
+// Do admits a unit of work and calls fn with a context derived from ctx that is
+// also cancelled once a hard stop has been signalled, marking the work as
+// finished once fn returns. Returns ErrGateClosed without calling fn if a soft
+// stop has been signalled.
+func (g *Gate) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	if err := g.Enter(); err != nil {
+		return err
+	}
+	defer g.Exit()
+
+	ctx, done := g.s.HardStopCtx(ctx)
+	defer done()
+	return fn(ctx)
+}
+
+// InFlight returns the number of units of work that have been admitted and not
+// yet finished.
+func (g *Gate) InFlight() int {
+	return int(g.n.Load())
+}
+
+// Wait blocks until there is no work in flight, or until the context is
+// cancelled, in which case the error of the context is returned. Once a soft
+// stop has been signalled no further work is admitted, and so a nil error from
+// Wait after a soft stop means that all work has finished.
+func (g *Gate) Wait(ctx context.Context) error {
+	if g.n.Load() == 0 {
+		return nil
+	}
+
+	g.mut.Lock()
+	if g.idle == nil {
+		g.idle = make(chan struct{})
+	}
+	idle := g.idle
+	g.waiting.Add(1)
+	g.mut.Unlock()
+	defer g.waiting.Add(-1)
+
+	// The last unit of work may have exited before observing the waiter.
+	if g.n.Load() == 0 {
+		return nil
+	}
+
+	select {
+	case <-idle:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,104 @@
+package shutdown
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGate(t *testing.T) {
+	s := NewSignaller()
+	g := NewGate(s)
+	assert.Same(t, s, g.Signaller())
+
+	require.NoError(t, g.Enter())
+	require.NoError(t, g.Enter())
+	assert.Equal(t, 2, g.InFlight())
+
+	s.TriggerSoftStop()
+	assert.ErrorIs(t, g.Enter(), ErrGateClosed)
+	assert.ErrorIs(t, g.Enter(), ErrSoftStop)
+	assert.Equal(t, 2, g.InFlight())
+
+	waitErr := make(chan error, 1)
+	go func() {
+		waitErr <- g.Wait(context.Background())
+	}()
+
+	g.Exit()
+	select {
+	case <-waitErr:
+		t.Fatal("wait returned with work in flight")
+	case <-time.After(time.Millisecond * 10):
+	}
+
+	g.Exit()
+	require.NoError(t, <-waitErr)
+	assert.Equal(t, 0, g.InFlight())
+	require.NoError(t, g.Wait(context.Background()))
+}
+
+func TestGateWaitCancelled(t *testing.T) {
+	g := NewGate(NewSignaller())
+	require.NoError(t, g.Enter())
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, g.Wait(ctx), context.DeadlineExceeded)
+}
+
+func TestGateDo(t *testing.T) {
+	s := NewSignaller()
+	g := NewGate(s)
+
+	require.NoError(t, g.Do(context.Background(), func(ctx context.Context) error {
+		assert.Equal(t, 1, g.InFlight())
+		s.TriggerHardStop()
+		assert.Error(t, ctx.Err())
+		return nil
+	}))
+	assert.Equal(t, 0, g.InFlight())
+
+	assert.ErrorIs(t, g.Do(context.Background(), func(ctx context.Context) error {
+		t.Error("work admitted after soft stop")
+		return nil
+	}), ErrGateClosed)
+}
+
+func TestGateConcurrentWait(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		s := NewSignaller()
+		g := NewGate(s)
+
+		var wg sync.WaitGroup
+		for j := 0; j < 4; j++ {
+			require.NoError(t, g.Enter())
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				g.Exit()
+			}()
+		}
+		s.TriggerSoftStop()
+		require.NoError(t, g.Wait(context.Background()))
+		assert.Equal(t, 0, g.InFlight())
+		wg.Wait()
+	}
+}
+
+func BenchmarkGateEnterExit(b *testing.B) {
+	g := NewGate(NewSignaller())
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			if err := g.Enter(); err != nil {
+				b.Fatal(err)
+			}
+			g.Exit()
+		}
+	})
+}
@@ -0,0 +1,71 @@
+package shutdown
+
+import (
+	"context"
+)
+
+// WithGoroutineGate causes TriggerHasStopped to be deferred while goroutines
+// started with Go are still running, in which case the signaller is instead
+// triggered as having stopped once the last of them returns. This allows the
+// signaller to act as the root of the goroutines of a component, which is then
+// only reported as stopped once all of them have finished.
+func WithGoroutineGate() Option {
+	return func(o *options) {
+		o.goroutineGate = true
+	}
+}
+
+// Go runs fn in a new goroutine with a context that is cancelled once a soft or
+// hard stop has been signalled, and counts it as running until fn returns. The
+// number of goroutines running is reported by Goroutines.
+func (s *Signaller) Go(fn func(ctx context.Context)) {
+	if s == nil {
+		go fn(context.Background())
+		return
+	}
+	x := s.extra()
+	x.goroutines.Add(1)
+
+	ctx, done := s.SoftStopCtx(context.Background())
+	go func() {
+		defer func() {
+			done()
+			if x.goroutines.Add(-1) == 0 && x.stopDeferred.Load() {
+				s.TriggerHasStopped()
+			}
+		}()
+		fn(ctx)
+	}()
+}
+
+// Goroutines returns the number of goroutines started with Go that are still
+// running.
+func (s *Signaller) Goroutines() int {
+	if s == nil {
+		return 0
+	}
+	if x := s.ext.Load(); x != nil {
+		return int(x.goroutines.Load())
+	}
+	return 0
+}
+
+// deferStop returns true if TriggerHasStopped should be deferred until the
+// goroutines started with Go have returned and the barriers of the signaller
+// are confirmed.
+func (s *Signaller) deferStop() bool {
+	x := s.ext.Load()
+	if x == nil || !x.gated() {
+		return false
+	}
+	x.stopDeferred.Store(true)
+
+	// The last gate may have been released before observing the deferral.
+	return x.gated()
+}
+
+// gated returns true if either goroutines started with Go or barriers are
+// holding back the has stopped tier.
+func (x *extra) gated() bool {
+	return (x.goroutineGate && x.goroutines.Load() != 0) || x.barriers.Load() != 0
+}
@@ -0,0 +1,73 @@
+package shutdown
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGo(t *testing.T) {
+	s := NewSignaller()
+
+	started := make(chan struct{})
+	finished := make(chan struct{})
+	s.Go(func(ctx context.Context) {
+		close(started)
+		<-ctx.Done()
+		close(finished)
+	})
+	<-started
+	assert.Equal(t, 1, s.Goroutines())
+
+	var buf bytes.Buffer
+	_, err := s.Diagnostics().WriteTo(&buf)
+	require.NoError(t, err)
+	assert.Contains(t, buf.String(), "  goroutines: 1\n")
+
+	s.TriggerSoftStop()
+	<-finished
+	require.Eventually(t, func() bool { return s.Goroutines() == 0 }, time.Second, time.Millisecond)
+	assert.Zero(t, s.OutstandingContexts())
+}
+
+func TestGoroutineGate(t *testing.T) {
+	s := NewSignaller(WithGoroutineGate())
+	assert.True(t, s.Config().GoroutineGate)
+
+	release := make(chan struct{})
+	for i := 0; i < 3; i++ {
+		s.Go(func(ctx context.Context) {
+			<-release
+		})
+	}
+
+	s.TriggerSoftStop()
+	s.TriggerHasStopped()
+	assertOpen(t, s.HasStoppedChan())
+	assert.Equal(t, 3, s.Goroutines())
+
+	close(release)
+	assertClosed(t, s.HasStoppedChan())
+	assert.Zero(t, s.Goroutines())
+}
+
+func TestGoroutineGateIdle(t *testing.T) {
+	s := NewSignaller(WithGoroutineGate())
+	s.TriggerSoftStop()
+	s.TriggerHasStopped()
+	assert.True(t, s.IsHasStoppedSignalled())
+}
+
+func TestGoNil(t *testing.T) {
+	var s *Signaller
+	done := make(chan struct{})
+	s.Go(func(ctx context.Context) {
+		close(done)
+	})
+	<-done
+	assert.Zero(t, s.Goroutines())
+}
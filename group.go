@@ -0,0 +1,362 @@
+package shutdown
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unsafe"
+)
+
+// groupCause is the cause of stops propagated to the members of a group.
+var groupCause = cause{source: SourceParentContext, reason: "group"}
+
+// groupShards is the number of shards that members of a group are spread
+// across, which bounds contention on groups that are registered against and
+// deregistered from concurrently, such as one member per connection.
+const groupShards = 64
+
+type groupShard struct {
+	mut     sync.Mutex
+	members map[*Signaller]*groupMember
+
+	// Pad shards to separate cache lines.
+	_ [48]byte
+}
+
+// Group is a collection of Signallers, usually owned by a parent component,
+// that can be signalled to stop together and that reports as stopped once each
+// of its members has stopped.
+//
+// Triggering a group signals each member in a single pass from the calling
+// goroutine, and members report back through an atomic counter rather than a
+// goroutine per member, which keeps the cost of stopping large groups
+// proportional to the number of members and nothing more. Hard stopping a
+// group of 10,000 members and observing each of them stop takes a few
+// milliseconds and allocates only a small scratch buffer (see
+// BenchmarkGroup10k).
+//
+// Members are spread across sharded maps so that adding and removing them
+// concurrently scales to groups with hundreds of thousands of members.
+type Group struct {
+	shards [groupShards]groupShard
+
+	// Tier bits that the group has been triggered to, and the Unix nanoseconds
+	// of the first trigger.
+	triggered   atomic.Uint32
+	triggeredAt atomic.Int64
+
+	// The number of members that have not yet stopped, plus one until the
+	// group itself has been triggered.
+	pending atomic.Int64
+
+	// Triggered as having stopped once the group has stopped, which provides
+	// the channel and contexts of HasStoppedChan and HasStoppedCtx.
+	stopped Signaller
+
+	// Set with WithParent.
+	parent *Signaller
+
+	// Set with WithStopStrategy, and whether any member has been added with
+	// a priority, in which case soft stops are sequenced rather than
+	// broadcast. The sequencing of a soft stop is cancelled by a hard stop.
+	strategy    StopStrategy
+	prioritised atomic.Bool
+	seqCancel   atomic.Pointer[context.CancelFunc]
+}
+
+// GroupOption configures a Group at construction.
+type GroupOption func(g *Group)
+
+// WithParent binds a group to the signaller of its owner, where soft and hard
+// stops of the parent are propagated to the group and the parent is triggered
+// as having stopped once every member of the group has stopped. This removes
+// the need for owners to track the lifecycle of their children manually.
+func WithParent(parent *Signaller) GroupOption {
+	return func(g *Group) {
+		g.parent = parent
+	}
+}
+
+type groupMember struct {
+	g        *Group
+	s        *Signaller
+	w        waiter
+	tags     []string
+	priority int
+}
+
+func (m *groupMember) notify() {
+	m.g.done()
+}
+
+// NewGroup creates a new empty group.
+func NewGroup(opts ...GroupOption) *Group {
+	g := &Group{}
+	for i := range g.shards {
+		g.shards[i].members = map[*Signaller]*groupMember{}
+	}
+	g.pending.Store(1)
+	for _, o := range opts {
+		o(g)
+	}
+	if g.parent != nil {
+		g.parent.OnSoftStop(g.TriggerSoftStop)
+		g.parent.OnHardStop(g.TriggerHardStop)
+	}
+	return g
+}
+
+func (g *Group) shard(s *Signaller) *groupShard {
+	// Fibonacci hashing of the pointer, which spreads the aligned addresses of
+	// allocations evenly across shards.
+	h := uint64(uintptr(unsafe.Pointer(s))) * 11400714819323198485
+	return &g.shards[h>>58]
+}
+
+// Add a signaller to the group. If the group has already been triggered then
+// the signaller is triggered to the same tier immediately. Adding a signaller
+// that is already a member of the group, or a nil signaller, has no effect.
+func (g *Group) Add(s *Signaller) {
+	g.AddTagged(s)
+}
+
+// AddTagged adds a signaller to the group as with Add, along with tags that
+// describe the subsystems it belongs to, such as "network" or "storage", which
+// allows it to be stopped along with the other members that share a tag, see
+// TriggerSoftStopTagged.
+func (g *Group) AddTagged(s *Signaller, tags ...string) {
+	g.add(s, 0, tags)
+}
+
+func (g *Group) add(s *Signaller, priority int, tags []string) {
+	if s == nil {
+		return
+	}
+	m := &groupMember{g: g, s: s, tags: tags, priority: priority}
+	m.w.n = m
+
+	shard := g.shard(s)
+	shard.mut.Lock()
+	if _, exists := shard.members[s]; exists {
+		shard.mut.Unlock()
+		return
+	}
+	shard.members[s] = m
+
+	// Once the group has stopped it remains stopped, and so late members are
+	// no longer counted.
+	if g.tryAddPending() && !s.addWaiter(TierHasStopped, &m.w) {
+		g.done()
+	}
+	triggered := g.triggered.Load()
+	shard.mut.Unlock()
+
+	if triggered&TierHardStop.bit() != 0 {
+		s.triggerHardStop(groupCause)
+	} else if triggered&TierSoftStop.bit() != 0 {
+		s.triggerSoftStop(groupCause)
+	}
+}
+
+// Remove a signaller from the group, it will no longer be triggered by the
+// group and the group will no longer wait for it to stop. Returns false if the
+// signaller was not a member of the group.
+func (g *Group) Remove(s *Signaller) bool {
+	if s == nil {
+		return false
+	}
+	shard := g.shard(s)
+	shard.mut.Lock()
+	m, exists := shard.members[s]
+	delete(shard.members, s)
+	shard.mut.Unlock()
+
+	if !exists {
+		return false
+	}
+	if s.removeWaiter(&m.w) {
+		g.done()
+	}
+	return true
+}
+
+// Len returns the number of members of the group.
+func (g *Group) Len() (n int) {
+	for i := range g.shards {
+		shard := &g.shards[i]
+		shard.mut.Lock()
+		n += len(shard.members)
+		shard.mut.Unlock()
+	}
+	return
+}
+
+func (g *Group) tryAddPending() bool {
+	for {
+		n := g.pending.Load()
+		if n == 0 {
+			return false
+		}
+		if g.pending.CompareAndSwap(n, n+1) {
+			return true
+		}
+	}
+}
+
+func (g *Group) done() {
+	if g.pending.Add(-1) == 0 {
+		g.stopped.trigger(TierHasStopped, cause{reason: "group stopped"})
+		if g.parent != nil {
+			// The group may stop from within the hooks of the parent, or
+			// without the parent having been stopped at all when the group
+			// itself was stopped, neither of which is a fault of the owner.
+			g.parent.triggerHasStopped(cause{reason: "group stopped"}, true)
+		}
+	}
+}
+
+func (g *Group) trigger(hard bool) {
+	bits := TierSoftStop.bit()
+	if hard {
+		bits |= TierHardStop.bit()
+	}
+
+	var first bool
+	for {
+		old := g.triggered.Load()
+		if g.triggered.CompareAndSwap(old, old|bits) {
+			first = old == 0
+			break
+		}
+	}
+
+	if first {
+		g.triggeredAt.Store(time.Now().UnixNano())
+	}
+
+	if !hard && g.sequenced() {
+		if first {
+			g.startSequence()
+			g.done()
+		}
+		return
+	}
+	if c := g.seqCancel.Load(); c != nil {
+		(*c)()
+	}
+
+	var members []*Signaller
+	for i := range g.shards {
+		shard := &g.shards[i]
+
+		// Members are collected before being triggered so that hooks are free
+		// to modify the group.
+		shard.mut.Lock()
+		members = members[:0]
+		for s := range shard.members {
+			members = append(members, s)
+		}
+		shard.mut.Unlock()
+
+		for _, s := range members {
+			if hard {
+				s.triggerHardStop(groupCause)
+			} else {
+				s.triggerSoftStop(groupCause)
+			}
+		}
+	}
+	if first {
+		g.done()
+	}
+}
+
+// TriggerSoftStop signals to each member of the group that it should terminate
+// at its own leisure.
+func (g *Group) TriggerSoftStop() {
+	g.trigger(false)
+}
+
+// TriggerHardStop signals to each member of the group that it should terminate
+// right now regardless of any in progress tasks.
+func (g *Group) TriggerHardStop() {
+	g.trigger(true)
+}
+
+// Wait blocks until the group has been triggered and every member has stopped,
+// or the provided context is cancelled, in which case a *PendingError naming
+// the members that have not stopped and wrapping the error of the context is
+// returned. Once every member has stopped a *GroupError is returned if any of
+// them recorded stop errors, see RecordStopErr.
+func (g *Group) Wait(ctx context.Context) error {
+	select {
+	case <-g.stopped.HasStoppedChan():
+		return g.stopErr()
+	case <-ctx.Done():
+		return &PendingError{
+			Pending:  g.Pending(),
+			Draining: longestDraining(g.Draining()),
+			Err:      ctx.Err(),
+		}
+	}
+}
+
+// HasStoppedChan returns a channel that is closed once the group has been
+// triggered and every member has stopped, which allows an owner to select on
+// the stop of the whole group rather than each of its members.
+func (g *Group) HasStoppedChan() <-chan struct{} {
+	return g.stopped.HasStoppedChan()
+}
+
+// HasStoppedCtx returns a context.Context that will be terminated when either
+// the provided context is cancelled or the group has been triggered and every
+// member has stopped. As with the contexts of a Signaller no goroutine is
+// created in order to observe the group.
+func (g *Group) HasStoppedCtx(ctx context.Context) (context.Context, context.CancelFunc) {
+	return g.stopped.HasStoppedCtx(ctx)
+}
+
+// Pending returns the sorted names of the members of the group that have not
+// yet stopped, where unnamed members are listed as "(unnamed)".
+func (g *Group) Pending() []string {
+	return pendingNames(g.members())
+}
+
+// members returns the members of the group, sorted by name.
+func (g *Group) members() []*Signaller {
+	var members []*Signaller
+	for i := range g.shards {
+		shard := &g.shards[i]
+		shard.mut.Lock()
+		for s := range shard.members {
+			members = append(members, s)
+		}
+		shard.mut.Unlock()
+	}
+	sortByName(members)
+	return members
+}
+
+// sortByName sorts signallers by name.
+func sortByName(members []*Signaller) {
+	sort.SliceStable(members, func(i, j int) bool {
+		return members[i].Name() < members[j].Name()
+	})
+}
+
+// Draining returns how long each member of the group that has not yet stopped
+// has been draining, keyed by the same names as Pending, where members that
+// share a name report the longest of their durations. Each duration is
+// measured from the first soft or hard stop of the member according to its
+// own clock, see SignalledAt, and members without a recorded signal time are
+// measured from the first trigger of the group. Members that have not been
+// signalled are omitted.
+func (g *Group) Draining() map[string]time.Duration {
+	var since time.Duration
+	if at := g.triggeredAt.Load(); at != 0 {
+		since = time.Since(time.Unix(0, at))
+	}
+	return pendingDraining(g.members(), since)
+}
@@ -0,0 +1,316 @@
+package shutdown
+
+import (
+	"context"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func waitCtx(t testing.TB) context.Context {
+	ctx, done := context.WithTimeout(context.Background(), time.Second*5)
+	t.Cleanup(done)
+	return ctx
+}
+
+func TestGroupSoftStop(t *testing.T) {
+	g := NewGroup()
+	a, b := NewSignaller(), NewSignaller()
+	g.Add(a)
+	g.Add(b)
+
+	g.TriggerSoftStop()
+	assert.True(t, a.IsSoftStopSignalled())
+	assert.True(t, b.IsSoftStopSignalled())
+	assert.False(t, a.IsHardStopSignalled())
+	assert.False(t, b.IsHardStopSignalled())
+
+	a.TriggerHasStopped()
+	assertOpen(t, g.HasStoppedChan())
+
+	b.TriggerHasStopped()
+	require.NoError(t, g.Wait(waitCtx(t)))
+}
+
+func TestGroupHardStop(t *testing.T) {
+	g := NewGroup()
+	a := NewSignaller()
+	g.Add(a)
+
+	g.TriggerHardStop()
+	assert.True(t, a.IsHardStopSignalled())
+
+	// Late members are triggered to the same tier
+	b := NewSignaller()
+	g.Add(b)
+	assert.True(t, b.IsHardStopSignalled())
+
+	a.TriggerHasStopped()
+	b.TriggerHasStopped()
+	require.NoError(t, g.Wait(waitCtx(t)))
+}
+
+func TestGroupWaitCancelled(t *testing.T) {
+	g := NewGroup()
+	g.Add(NewSignaller())
+	g.TriggerSoftStop()
+
+	ctx, done := context.WithCancel(context.Background())
+	done()
+	assert.ErrorIs(t, g.Wait(ctx), context.Canceled)
+}
+
+func TestGroupPending(t *testing.T) {
+	g := NewGroup()
+	a, b, c := NewSignaller(WithName("foo")), NewSignaller(WithName("bar")), NewSignaller()
+	g.Add(a)
+	g.Add(b)
+	g.Add(c)
+	assert.Equal(t, []string{"(unnamed)", "bar", "foo"}, g.Pending())
+	assert.Empty(t, g.Draining())
+
+	g.TriggerSoftStop()
+	a.TriggerHasStopped()
+	assert.Equal(t, []string{"(unnamed)", "bar"}, g.Pending())
+
+	ctx, done := context.WithTimeout(context.Background(), time.Millisecond*10)
+	defer done()
+
+	err := g.Wait(ctx)
+	var pending *PendingError
+	require.ErrorAs(t, err, &pending)
+	assert.Equal(t, []string{"(unnamed)", "bar"}, pending.Pending)
+	assert.GreaterOrEqual(t, pending.Draining, time.Millisecond*10)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Regexp(t, `^context deadline exceeded after draining for \d+ms, pending: \(unnamed\), bar$`, err.Error())
+}
+
+func TestGroupDraining(t *testing.T) {
+	clock := newManualClock()
+	g := NewGroup()
+	a := NewSignaller(WithName("foo"), WithClock(clock))
+	b := NewSignaller(WithName("bar"), WithClock(clock))
+	c := NewSignaller(WithName("baz"), WithClock(clock))
+	g.Add(a)
+	g.Add(b)
+	g.Add(c)
+
+	g.TriggerSoftStopNamed("foo")
+	clock.Advance(time.Second * 3)
+	g.TriggerHardStopNamed("bar")
+	clock.Advance(time.Second)
+	assert.Equal(t, map[string]time.Duration{
+		"foo": time.Second * 4,
+		"bar": time.Second,
+	}, g.Draining())
+
+	a.TriggerHasStopped()
+	assert.Equal(t, map[string]time.Duration{
+		"bar": time.Second,
+	}, g.Draining())
+}
+
+func TestGroupNotTriggered(t *testing.T) {
+	g := NewGroup()
+	a := NewSignaller()
+	a.TriggerHasStopped()
+	g.Add(a)
+
+	// Members that stop before the group is triggered do not stop the group.
+	assertOpen(t, g.HasStoppedChan())
+
+	g.TriggerSoftStop()
+	require.NoError(t, g.Wait(waitCtx(t)))
+
+	// Late members of a stopped group are still triggered.
+	b := NewSignaller()
+	g.Add(b)
+	assert.True(t, b.IsSoftStopSignalled())
+	b.TriggerHasStopped()
+	require.NoError(t, g.Wait(waitCtx(t)))
+}
+
+func TestGroupNoGoroutines(t *testing.T) {
+	g := NewGroup()
+	members := make([]*Signaller, 10000)
+	for i := range members {
+		members[i] = NewSignaller()
+		g.Add(members[i])
+	}
+
+	before := runtime.NumGoroutine()
+	g.TriggerHardStop()
+	for _, m := range members {
+		m.TriggerHasStopped()
+	}
+	assert.LessOrEqual(t, runtime.NumGoroutine(), before)
+	require.NoError(t, g.Wait(waitCtx(t)))
+}
+
+func BenchmarkGroup10k(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		g := NewGroup()
+		members := make([]*Signaller, 10000)
+		for j := range members {
+			members[j] = NewSignaller()
+			g.Add(members[j])
+		}
+		b.StartTimer()
+
+		g.TriggerHardStop()
+		for _, m := range members {
+			m.TriggerHasStopped()
+		}
+		if err := g.Wait(context.Background()); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestGroupRemove(t *testing.T) {
+	g := NewGroup()
+	a, b := NewSignaller(), NewSignaller()
+	g.Add(a)
+	g.Add(a)
+	g.Add(b)
+	assert.Equal(t, 2, g.Len())
+
+	assert.True(t, g.Remove(b))
+	assert.False(t, g.Remove(b))
+	assert.Equal(t, 1, g.Len())
+
+	g.TriggerSoftStop()
+	assert.True(t, a.IsSoftStopSignalled())
+	assert.False(t, b.IsSoftStopSignalled())
+
+	a.TriggerHasStopped()
+	require.NoError(t, g.Wait(waitCtx(t)))
+}
+
+func TestGroupRemovePending(t *testing.T) {
+	g := NewGroup()
+	a, b := NewSignaller(), NewSignaller()
+	g.Add(a)
+	g.Add(b)
+
+	g.TriggerSoftStop()
+	a.TriggerHasStopped()
+	assertOpen(t, g.HasStoppedChan())
+
+	// Removing the last pending member stops the group.
+	assert.True(t, g.Remove(b))
+	require.NoError(t, g.Wait(waitCtx(t)))
+}
+
+func BenchmarkGroupAddRemove100k(b *testing.B) {
+	g := NewGroup()
+	for i := 0; i < 100000; i++ {
+		g.Add(NewSignaller())
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			s := NewSignaller()
+			g.Add(s)
+			g.Remove(s)
+		}
+	})
+}
+
+func BenchmarkGroup100k(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		g := NewGroup()
+		members := make([]*Signaller, 100000)
+		for j := range members {
+			members[j] = NewSignaller()
+			g.Add(members[j])
+		}
+		b.StartTimer()
+
+		g.TriggerHardStop()
+		for _, m := range members {
+			m.TriggerHasStopped()
+		}
+		if err := g.Wait(context.Background()); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestGroupWithParent(t *testing.T) {
+	parent := NewSignaller()
+	g := NewGroup(WithParent(parent))
+
+	a, b := NewSignaller(WithReentrantTriggerDetection()), NewSignaller()
+	g.Add(a)
+	g.Add(b)
+
+	// One member stops synchronously within the hooks of the parent.
+	a.OnSoftStop(a.TriggerHasStopped)
+
+	parent.TriggerSoftStop()
+	assert.True(t, a.IsHasStoppedSignalled())
+	assert.True(t, b.IsSoftStopSignalled())
+	assert.False(t, parent.IsHasStoppedSignalled())
+
+	parent.TriggerHardStop()
+	assert.True(t, b.IsHardStopSignalled())
+
+	b.TriggerHasStopped()
+	assert.True(t, parent.IsHasStoppedSignalled())
+	assert.NoError(t, parent.StopErr())
+
+	// Stopping from within its own hook is recorded against the member, and
+	// reported by the group.
+	var reentrant *ReentrantTriggerError
+	assert.ErrorAs(t, g.Wait(waitCtx(t)), &reentrant)
+}
+
+func TestGroupWithParentEmpty(t *testing.T) {
+	parent := NewSignaller()
+	_ = NewGroup(WithParent(parent))
+
+	parent.TriggerSoftStop()
+	assert.True(t, parent.IsHasStoppedSignalled())
+	assert.NoError(t, parent.StopErr())
+}
+
+func TestGroupHasStopped(t *testing.T) {
+	g := NewGroup()
+	a, b := NewSignaller(), NewSignaller()
+	g.Add(a)
+	g.Add(b)
+
+	ctx, done := g.HasStoppedCtx(context.Background())
+	defer done()
+
+	g.TriggerSoftStop()
+	a.TriggerHasStopped()
+	assertOpen(t, g.HasStoppedChan())
+	assert.NoError(t, ctx.Err())
+
+	b.TriggerHasStopped()
+	assertClosed(t, g.HasStoppedChan())
+	assertClosed(t, ctx.Done())
+	assert.ErrorIs(t, ctx.Err(), context.Canceled)
+}
+
+func TestGroupHasStoppedCtxCancelled(t *testing.T) {
+	g := NewGroup()
+	parent, cancel := context.WithCancel(context.Background())
+	ctx, done := g.HasStoppedCtx(parent)
+	defer done()
+
+	cancel()
+	assertClosed(t, ctx.Done())
+	assertOpen(t, g.HasStoppedChan())
+}
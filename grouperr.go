@@ -0,0 +1,70 @@
+package shutdown
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MemberError is the stop error of a single member of a group, as reported by
+// its StopErr.
+type MemberError struct {
+	// The name of the member, or "(unnamed)".
+	Name string
+	Err  error
+}
+
+// Error returns the stop error prefixed with the name of the member.
+func (e *MemberError) Error() string {
+	return fmt.Sprintf("%v: %v", e.Name, e.Err)
+}
+
+// Unwrap returns the stop error of the member.
+func (e *MemberError) Unwrap() error {
+	return e.Err
+}
+
+// GroupError is returned by Group.Wait when members of the group recorded stop
+// errors, and holds a *MemberError for each of them, sorted by name. It can be
+// inspected with errors.Is and errors.As as with an error from errors.Join.
+type GroupError struct {
+	Members []*MemberError
+}
+
+// Error returns the errors of each member on a line of their own.
+func (e *GroupError) Error() string {
+	lines := make([]string, len(e.Members))
+	for i, m := range e.Members {
+		lines[i] = m.Error()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Unwrap returns the errors of each member.
+func (e *GroupError) Unwrap() []error {
+	errs := make([]error, len(e.Members))
+	for i, m := range e.Members {
+		errs[i] = m
+	}
+	return errs
+}
+
+// stopErr returns a *GroupError of the stop errors of the members of the
+// group, or nil if there are none.
+func (g *Group) stopErr() error {
+	var errs []*MemberError
+	for _, m := range g.members() {
+		err := m.StopErr()
+		if err == nil {
+			continue
+		}
+		name := m.Name()
+		if name == "" {
+			name = "(unnamed)"
+		}
+		errs = append(errs, &MemberError{Name: name, Err: err})
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return &GroupError{Members: errs}
+}
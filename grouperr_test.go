@@ -0,0 +1,51 @@
+package shutdown
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGroupWaitErrors(t *testing.T) {
+	errFlush, errClose := errors.New("flush failed"), errors.New("close failed")
+
+	g := NewGroup()
+	db, cache, api := NewSignaller(WithName("db")), NewSignaller(WithName("cache")), NewSignaller()
+	for _, s := range []*Signaller{db, cache, api} {
+		g.Add(s)
+	}
+
+	g.TriggerSoftStop()
+	db.RecordStopErr(errFlush)
+	api.RecordStopErr(errClose)
+	for _, s := range []*Signaller{db, cache, api} {
+		s.TriggerHasStopped()
+	}
+
+	err := g.Wait(waitCtx(t))
+	require.Error(t, err)
+	assert.EqualError(t, err, "(unnamed): close failed\ndb: flush failed")
+	assert.ErrorIs(t, err, errFlush)
+	assert.ErrorIs(t, err, errClose)
+
+	var groupErr *GroupError
+	require.ErrorAs(t, err, &groupErr)
+	require.Len(t, groupErr.Members, 2)
+	assert.Equal(t, "db", groupErr.Members[1].Name)
+
+	var memberErr *MemberError
+	require.ErrorAs(t, err, &memberErr)
+	assert.Equal(t, "(unnamed)", memberErr.Name)
+}
+
+func TestGroupWaitNoErrors(t *testing.T) {
+	g := NewGroup()
+	s := NewSignaller()
+	g.Add(s)
+
+	g.TriggerHardStop()
+	s.TriggerHasStopped()
+	assert.NoError(t, g.Wait(waitCtx(t)))
+}
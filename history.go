@@ -0,0 +1,137 @@
+package shutdown
+
+import (
+	"fmt"
+	"log/slog"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// WithHistory causes the signaller to keep a record of its most recent
+// lifecycle transitions, up to the provided number, which is returned by
+// History and included in its diagnostics. This allows post-mortems to show
+// the exact sequence of events that led to a stop, rather than only the final
+// state.
+//
+// Recording a transition captures the stack of the goroutine that made it in
+// order to identify its source, and so it costs a few microseconds per
+// transition.
+func WithHistory(n int) Option {
+	return func(o *options) {
+		o.historyLimit = n
+	}
+}
+
+// Transition is a lifecycle transition recorded in the history of a signaller.
+type Transition struct {
+	Kind EventKind
+	Time time.Time
+
+	// What caused the transition when it was not triggered directly, such as
+	// "signal interrupt", "escalation hard_stop", "hard stop" for the soft
+	// stop implied by a hard stop, or "group" for a stop propagated by a
+	// Group. Empty when triggered directly.
+	Cause string
+
+	// The function and location of the code outside of this package that
+	// made the transition, or empty if it was made by this package alone,
+	// such as by a timer.
+	Source string
+
+	// The attributes attached to the signaller at the time of the
+	// transition, see TriggerSoftStopWith.
+	Attrs []slog.Attr
+
+	// The source of the trigger that caused the transition.
+	TriggeredBy TriggerSource
+}
+
+// String returns a human readable description of the transition.
+func (t Transition) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%v %v", t.Time.Format(time.RFC3339Nano), t.Kind)
+	if t.Cause != "" {
+		fmt.Fprintf(&b, " (%v)", t.Cause)
+	}
+	if t.TriggeredBy != SourceProgrammatic {
+		fmt.Fprintf(&b, " by %v", t.TriggeredBy)
+	}
+	if t.Source != "" {
+		fmt.Fprintf(&b, " from %v", t.Source)
+	}
+	for _, a := range t.Attrs {
+		fmt.Fprintf(&b, " %v", a)
+	}
+	return b.String()
+}
+
+// History returns the most recent lifecycle transitions of the signaller,
+// oldest first, as recorded when constructed with WithHistory.
+func (s *Signaller) History() []Transition {
+	if s == nil {
+		return nil
+	}
+	x := s.ext.Load()
+	if x == nil || x.historyLimit <= 0 {
+		return nil
+	}
+
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	if x.historyCount <= len(x.history) {
+		return append([]Transition(nil), x.history...)
+	}
+	i := x.historyCount % len(x.history)
+	return append(append([]Transition(nil), x.history[i:]...), x.history[:i]...)
+}
+
+// recordTransition adds a transition to the history of the signaller, if
+// configured.
+func (s *Signaller) recordTransition(kind EventKind, c cause) {
+	x := s.ext.Load()
+	if x == nil || x.historyLimit <= 0 {
+		return
+	}
+	t := Transition{
+		Kind:   kind,
+		Time:   s.clock().Now(),
+		Cause:  c.reason,
+		Source: callerSource(),
+		Attrs:  s.StopAttrs(),
+
+		TriggeredBy: c.source,
+	}
+
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	if len(x.history) < x.historyLimit {
+		x.history = append(x.history, t)
+	} else {
+		x.history[x.historyCount%x.historyLimit] = t
+	}
+	x.historyCount++
+}
+
+const pkgPrefix = "github.com/Jeffail/shutdown."
+
+// callerSource returns the first caller outside of this package, excluding
+// its tests, formatted as the function along with its file and line.
+func callerSource() string {
+	var pcs [32]uintptr
+	n := runtime.Callers(3, pcs[:])
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		f, more := frames.Next()
+		if !strings.HasPrefix(f.Function, pkgPrefix) || strings.HasSuffix(f.File, "_test.go") {
+			// Goroutines started by timers have no source of interest.
+			if strings.HasPrefix(f.Function, "runtime.") || strings.HasPrefix(f.Function, "time.") {
+				return ""
+			}
+			return fmt.Sprintf("%v %v:%v", f.Function, f.File, f.Line)
+		}
+		if !more {
+			return ""
+		}
+	}
+}
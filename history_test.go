@@ -0,0 +1,90 @@
+package shutdown
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHistory(t *testing.T) {
+	clock := newManualClock()
+	s := NewSignaller(WithClock(clock), WithHistory(10), WithHardStopGrace(time.Second))
+	start := clock.Now()
+
+	s.TriggerSoftStop()
+	clock.Advance(time.Second)
+	s.TriggerHasStopped()
+
+	h := s.History()
+	require.Len(t, h, 3)
+
+	assert.Equal(t, EventSoftStop, h[0].Kind)
+	assert.Equal(t, start, h[0].Time)
+	assert.Empty(t, h[0].Cause)
+	assert.Contains(t, h[0].Source, "shutdown.TestHistory ")
+	assert.Contains(t, h[0].Source, "history_test.go:")
+
+	assert.Equal(t, EventHardStop, h[1].Kind)
+	assert.Equal(t, "escalation hard_stop", h[1].Cause)
+	assert.Equal(t, start.Add(time.Second), h[1].Time)
+
+	assert.Equal(t, EventHasStopped, h[2].Kind)
+}
+
+func TestHistoryTimerSource(t *testing.T) {
+	s := NewSignaller(WithHistory(4), WithHardStopGrace(time.Millisecond))
+	s.TriggerSoftStop()
+	<-s.HardStopChan()
+
+	require.Eventually(t, func() bool { return len(s.History()) == 2 }, time.Second, time.Millisecond)
+	assert.Empty(t, s.History()[1].Source)
+}
+
+func TestHistoryBounded(t *testing.T) {
+	s := NewSignaller(WithHistory(2), WithReversibleSoftStop())
+
+	s.TriggerSoftStop()
+	s.AbortSoftStop()
+	s.TriggerHardStop()
+
+	h := s.History()
+	require.Len(t, h, 2)
+	assert.Equal(t, EventSoftStop, h[0].Kind)
+	assert.Equal(t, "hard stop", h[0].Cause)
+	assert.Equal(t, EventHardStop, h[1].Kind)
+}
+
+func TestHistoryGroup(t *testing.T) {
+	s := NewSignaller(WithHistory(4))
+	g := NewGroup()
+	g.Add(s)
+	g.TriggerSoftStop()
+
+	h := s.History()
+	require.Len(t, h, 1)
+	assert.Equal(t, "group", h[0].Cause)
+	assert.Contains(t, h[0].Source, "shutdown.TestHistoryGroup ")
+}
+
+func TestHistoryDisabled(t *testing.T) {
+	s := NewSignaller()
+	s.TriggerHardStop()
+	assert.Nil(t, s.History())
+
+	var nilS *Signaller
+	assert.Nil(t, nilS.History())
+}
+
+func TestHistoryDiagnostics(t *testing.T) {
+	clock := newManualClock()
+	s := NewSignaller(WithClock(clock), WithHistory(4))
+	s.TriggerHardStop()
+
+	var buf bytes.Buffer
+	_, err := s.Diagnostics().WriteTo(&buf)
+	require.NoError(t, err)
+	assert.Contains(t, buf.String(), "  history:\n    1970-01-01T00:16:40Z soft stop (hard stop) from github.com/Jeffail/shutdown.TestHistoryDiagnostics ")
+}
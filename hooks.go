@@ -0,0 +1,399 @@
+package shutdown
+
+import (
+	"bytes"
+	"fmt"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"sync"
+	"sync/atomic"
+)
+
+// HookPanicError is recorded against a Signaller when a hook panics, and
+// attributes the panic to the hook by the stack of its registration.
+type HookPanicError struct {
+	// The name of the signaller, if it has one.
+	Signaller string
+
+	// The tier that the hook was registered against.
+	Tier Tier
+
+	// The value recovered from the panic.
+	Value any
+
+	// The stack of the goroutine that registered the hook.
+	RegisteredAt []byte
+
+	// The stack of the goroutine at the point of the panic.
+	Stack []byte
+}
+
+// Error returns a description of the panic.
+func (e *HookPanicError) Error() string {
+	if e.Signaller != "" {
+		return fmt.Sprintf("signaller %v: %v hook panicked: %v", e.Signaller, e.Tier, e.Value)
+	}
+	return fmt.Sprintf("%v hook panicked: %v", e.Tier, e.Value)
+}
+
+// Unwrap returns the recovered value if it is an error.
+func (e *HookPanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
+
+// ReentrantTriggerError is recorded against a Signaller constructed with
+// WithReentrantTriggerDetection when a hook calls one of the Trigger methods of
+// the same signaller, either directly or transitively, from the goroutine
+// calling the hooks.
+type ReentrantTriggerError struct {
+	// The name of the signaller, if it has one.
+	Signaller string
+
+	// The tier of the hooks that were being called.
+	HookTier Tier
+
+	// The tier that was triggered from within a hook.
+	Triggered Tier
+
+	// The stack of the goroutine when it began calling the hooks.
+	HookStack []byte
+
+	// The stack of the goroutine at the point of the re-entrant trigger.
+	TriggerStack []byte
+}
+
+// Error returns a description of the re-entrant trigger.
+func (e *ReentrantTriggerError) Error() string {
+	if e.Signaller != "" {
+		return fmt.Sprintf("signaller %v: %v triggered from within a %v hook", e.Signaller, e.Triggered, e.HookTier)
+	}
+	return fmt.Sprintf("%v triggered from within a %v hook", e.Triggered, e.HookTier)
+}
+
+// hookFiring records a goroutine that is calling the hooks of a tier, along
+// with its stack, which is only symbolized for a re-entrant trigger.
+type hookFiring struct {
+	goid  uint64
+	tier  Tier
+	stack callers
+}
+
+// goroutineID returns the ID of the calling goroutine, as parsed from the
+// header of its stack trace.
+func goroutineID() uint64 {
+	var buf [64]byte
+	b := bytes.TrimPrefix(buf[:runtime.Stack(buf[:], false)], []byte("goroutine "))
+	if i := bytes.IndexByte(b, ' '); i > 0 {
+		b = b[:i]
+	}
+	id, _ := strconv.ParseUint(string(b), 10, 64)
+	return id
+}
+
+// beginFiring records the calling goroutine as calling the hooks of a tier when
+// the signaller was constructed with WithReentrantTriggerDetection, and
+// otherwise returns nil.
+func (s *Signaller) beginFiring(t Tier) *hookFiring {
+	if !s.config().reentrantTriggers {
+		return nil
+	}
+	f := &hookFiring{goid: goroutineID(), tier: t, stack: captureCallers(1)}
+	x := s.extra()
+	s.mut.Lock()
+	x.firing = append(x.firing, f)
+	x.firingCount.Add(1)
+	s.mut.Unlock()
+	return f
+}
+
+func (s *Signaller) endFiring(f *hookFiring) {
+	if f == nil {
+		return
+	}
+	x := s.extra()
+	s.mut.Lock()
+	for i, e := range x.firing {
+		if e == f {
+			x.firing = append(x.firing[:i], x.firing[i+1:]...)
+			break
+		}
+	}
+	x.firingCount.Add(-1)
+	s.mut.Unlock()
+}
+
+// checkReentrant records a *ReentrantTriggerError if the calling goroutine is
+// currently calling the hooks of the signaller. This is cheap unless hooks are
+// being called by a signaller constructed with WithReentrantTriggerDetection.
+func (s *Signaller) checkReentrant(t Tier) {
+	x := s.ext.Load()
+	if x == nil || x.firingCount.Load() == 0 {
+		return
+	}
+
+	goid := goroutineID()
+	var outer *hookFiring
+	s.mut.Lock()
+	for i := len(x.firing) - 1; i >= 0; i-- {
+		if x.firing[i].goid == goid {
+			outer = x.firing[i]
+			break
+		}
+	}
+	s.mut.Unlock()
+
+	if outer != nil {
+		s.RecordStopErr(&ReentrantTriggerError{
+			Signaller:    x.currentName(),
+			HookTier:     outer.tier,
+			Triggered:    t,
+			HookStack:    outer.stack.format(),
+			TriggerStack: debug.Stack(),
+		})
+	}
+}
+
+// hook is a function registered to be called once a tier has been signalled.
+type hook struct {
+	fn           func()
+	tier         Tier
+	registeredAt callers
+	called       atomic.Bool
+}
+
+// call runs the hook unless it has already been called or stopped. A panic
+// within the hook is recovered and returned as an error.
+func (h *hook) call() *HookPanicError {
+	if !h.called.CompareAndSwap(false, true) {
+		return nil
+	}
+	return h.run()
+}
+
+// run runs the hook regardless of whether it has been called, recovering a
+// panic as an error.
+func (h *hook) run() (err *HookPanicError) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = &HookPanicError{
+				Tier:         h.tier,
+				Value:        r,
+				RegisteredAt: h.registeredAt.format(),
+				Stack:        debug.Stack(),
+			}
+		}
+	}()
+	h.fn()
+	return nil
+}
+
+// hookSet holds the hooks registered against each tier, followed by those of
+// reloads.
+type hookSet [tierReload + 1][]*hook
+
+// hookList is a copy-on-write set of hooks. Registering and deregistering a
+// hook swaps the entire set atomically, as does firing the hooks of a tier,
+// which means that none of these operations take a lock. Each hook is guarded
+// by a flag of its own, ensuring that it is called at most once.
+type hookList struct {
+	set atomic.Pointer[hookSet]
+}
+
+// update applies a modification to a copy of the hook set and attempts to swap
+// it in, repeating until the swap succeeds.
+func (l *hookList) update(fn func(next *hookSet)) {
+	for {
+		loaded := l.set.Load()
+		next := &hookSet{}
+		if loaded != nil {
+			*next = *loaded
+		}
+		fn(next)
+		if l.set.CompareAndSwap(loaded, next) {
+			return
+		}
+	}
+}
+
+func (l *hookList) add(t Tier, h *hook) {
+	l.update(func(next *hookSet) {
+		hooks := make([]*hook, len(next[t]), len(next[t])+1)
+		copy(hooks, next[t])
+		next[t] = append(hooks, h)
+	})
+}
+
+func (l *hookList) remove(t Tier, h *hook) {
+	l.update(func(next *hookSet) {
+		hooks := make([]*hook, 0, len(next[t]))
+		for _, e := range next[t] {
+			if e != h {
+				hooks = append(hooks, e)
+			}
+		}
+		next[t] = hooks
+	})
+}
+
+// list returns the hooks of a tier without removing them.
+func (l *hookList) list(t Tier) []*hook {
+	if set := l.set.Load(); set != nil {
+		return set[t]
+	}
+	return nil
+}
+
+// take removes and returns the hooks of a tier.
+func (l *hookList) take(t Tier) (hooks []*hook) {
+	if set := l.set.Load(); set == nil || len(set[t]) == 0 {
+		return nil
+	}
+	l.update(func(next *hookSet) {
+		hooks = next[t]
+		next[t] = nil
+	})
+	return
+}
+
+// callHook calls a hook and records any panic as a stop error, returns true if
+// the hook panicked.
+func (s *Signaller) callHook(h *hook) bool {
+	return s.recordHookPanic(h.call())
+}
+
+// recordHookPanic records the panic of a hook, if any, as a stop error, returns
+// true if there was one.
+func (s *Signaller) recordHookPanic(err *HookPanicError) bool {
+	if err == nil {
+		return false
+	}
+	err.Signaller = s.Name()
+	s.observeHookPanic(err)
+	s.RecordStopErr(err)
+	return true
+}
+
+// fireHooks calls each hook of a tier in the order they were added, this must
+// only be called after the state bit of the tier has been set. A panicking hook
+// does not prevent the remaining hooks from being called. When the signaller is
+// configured with WithHookConcurrency the hooks are started in order but may
+// run concurrently.
+func (s *Signaller) fireHooks(t Tier) {
+	hooks := s.hooks.take(t)
+	if len(hooks) == 0 {
+		return
+	}
+
+	var panicked bool
+	if n := s.config().hookConcurrency; n > 1 && len(hooks) > 1 {
+		panicked = s.callHooksConcurrently(t, hooks, n)
+	} else {
+		f := s.beginFiring(t)
+		for _, h := range hooks {
+			if s.callHook(h) {
+				panicked = true
+			}
+		}
+		s.endFiring(f)
+	}
+
+	if panicked && s.config().escalateHookPanics {
+		s.triggerHardStop(cause{reason: "hook panic"})
+	}
+}
+
+// callHooksConcurrently calls hooks from a pool of at most n goroutines, which
+// take hooks in the order they were added, and blocks until every hook has
+// returned. Returns true if any hook panicked.
+func (s *Signaller) callHooksConcurrently(t Tier, hooks []*hook, n int) bool {
+	if n > len(hooks) {
+		n = len(hooks)
+	}
+
+	var (
+		wg       sync.WaitGroup
+		next     atomic.Int64
+		panicked atomic.Bool
+	)
+	wg.Add(n)
+	for i := 0; i < n; i++ {
+		go func() {
+			defer wg.Done()
+			f := s.beginFiring(t)
+			defer s.endFiring(f)
+			for {
+				i := int(next.Add(1) - 1)
+				if i >= len(hooks) {
+					return
+				}
+				if s.callHook(hooks[i]) {
+					panicked.Store(true)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	return panicked.Load()
+}
+
+func (s *Signaller) onTier(t Tier, fn func()) (stop func() bool) {
+	if s == nil {
+		return func() bool { return true }
+	}
+	h := &hook{fn: fn, tier: t, registeredAt: captureCallers(1)}
+	added := s.state.Load()&t.bit() == 0
+	if added {
+		s.hooks.add(t, h)
+	}
+
+	// If the tier was signalled before it could observe our hook then we call
+	// it ourselves.
+	if s.state.Load()&t.bit() != 0 {
+		if added {
+			s.hooks.remove(t, h)
+		}
+		if s.callHook(h) && s.config().escalateHookPanics {
+			s.triggerHardStop(cause{reason: "hook panic"})
+		}
+	}
+	return func() bool {
+		if !h.called.CompareAndSwap(false, true) {
+			return false
+		}
+		s.hooks.remove(t, h)
+		return true
+	}
+}
+
+// OnSoftStop registers a function to be called once the signal to soft or hard
+// stop has been made. The function is called from the goroutine that made the
+// signal, or immediately if the signal has already been made. Calling the
+// returned stop function deregisters the hook, and returns true if it did so
+// before the hook was called.
+//
+// Hooks are held in a lock-free list and therefore registering them, and
+// firing them, never blocks readers of the signaller.
+//
+// A panic within a hook is recovered and recorded as a *HookPanicError, which
+// is reported by StopErr and does not prevent any other hooks from being
+// called. Hooks must not call the Trigger methods of the same signaller, and
+// when the signaller was constructed with WithReentrantTriggerDetection, doing
+// so records a *ReentrantTriggerError.
+func (s *Signaller) OnSoftStop(fn func()) (stop func() bool) {
+	return s.onTier(TierSoftStop, fn)
+}
+
+// OnHardStop registers a function to be called once the signal to hard stop has
+// been made. The semantics are otherwise the same as OnSoftStop.
+func (s *Signaller) OnHardStop(fn func()) (stop func() bool) {
+	return s.onTier(TierHardStop, fn)
+}
+
+// OnHasStopped registers a function to be called once the signal that the
+// component has stopped has been made. The semantics are otherwise the same as
+// OnSoftStop.
+func (s *Signaller) OnHasStopped(fn func()) (stop func() bool) {
+	return s.onTier(TierHasStopped, fn)
+}
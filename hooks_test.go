@@ -0,0 +1,262 @@
+package shutdown
+
+import (
+	"errors"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHooksOrder(t *testing.T) {
+	s := NewSignaller()
+
+	var calls []string
+	s.OnHasStopped(func() { calls = append(calls, "stopped") })
+	s.OnHardStop(func() { calls = append(calls, "hard a") })
+	s.OnSoftStop(func() { calls = append(calls, "soft a") })
+	s.OnHardStop(func() { calls = append(calls, "hard b") })
+	s.OnSoftStop(func() { calls = append(calls, "soft b") })
+
+	s.TriggerHardStop()
+	s.TriggerHardStop()
+	assert.Equal(t, []string{"soft a", "soft b", "hard a", "hard b"}, calls)
+
+	s.TriggerHasStopped()
+	assert.Equal(t, []string{"soft a", "soft b", "hard a", "hard b", "stopped"}, calls)
+}
+
+func TestHooksAlreadySignalled(t *testing.T) {
+	s := NewSignaller()
+	s.TriggerSoftStop()
+
+	var called bool
+	stop := s.OnSoftStop(func() { called = true })
+	assert.True(t, called)
+	assert.False(t, stop())
+}
+
+func TestHooksStop(t *testing.T) {
+	s := NewSignaller()
+
+	var a, b, c bool
+	stopA := s.OnSoftStop(func() { a = true })
+	stopB := s.OnSoftStop(func() { b = true })
+	stopC := s.OnSoftStop(func() { c = true })
+
+	assert.True(t, stopB())
+	assert.False(t, stopB())
+
+	s.TriggerSoftStop()
+	assert.True(t, a)
+	assert.False(t, b)
+	assert.True(t, c)
+
+	assert.False(t, stopA())
+	assert.False(t, stopC())
+}
+
+func TestHooksConcurrent(t *testing.T) {
+	s := NewSignaller()
+
+	var mut sync.Mutex
+	calls := 0
+
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.OnSoftStop(func() {
+				mut.Lock()
+				calls++
+				mut.Unlock()
+			})
+		}()
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		s.TriggerSoftStop()
+	}()
+	wg.Wait()
+
+	mut.Lock()
+	assert.Equal(t, 100, calls)
+	mut.Unlock()
+}
+
+func registerPanickingHook(s *Signaller) {
+	s.OnSoftStop(func() {
+		panic("oh no")
+	})
+}
+
+func TestHooksPanic(t *testing.T) {
+	s := NewSignaller()
+
+	var calls []string
+	s.OnSoftStop(func() { calls = append(calls, "a") })
+	registerPanickingHook(s)
+	s.OnSoftStop(func() { calls = append(calls, "b") })
+
+	assert.NotPanics(t, s.TriggerSoftStop)
+	assert.Equal(t, []string{"a", "b"}, calls)
+	assert.False(t, s.IsHardStopSignalled())
+
+	var pErr *HookPanicError
+	require.ErrorAs(t, s.StopErr(), &pErr)
+	assert.Equal(t, TierSoftStop, pErr.Tier)
+	assert.Equal(t, "oh no", pErr.Value)
+	assert.Contains(t, string(pErr.RegisteredAt), "registerPanickingHook")
+	assert.EqualError(t, pErr, "soft stop hook panicked: oh no")
+}
+
+func TestHooksErrorsNamed(t *testing.T) {
+	s := NewSignaller(WithName("foo"), WithReentrantTriggerDetection())
+	registerPanickingHook(s)
+	s.OnHardStop(s.TriggerSoftStop)
+	s.TriggerHardStop()
+
+	var pErr *HookPanicError
+	require.ErrorAs(t, s.StopErr(), &pErr)
+	assert.Equal(t, "foo", pErr.Signaller)
+	assert.EqualError(t, pErr, "signaller foo: soft stop hook panicked: oh no")
+
+	var rErr *ReentrantTriggerError
+	require.ErrorAs(t, s.StopErr(), &rErr)
+	assert.Equal(t, "foo", rErr.Signaller)
+	assert.EqualError(t, rErr, "signaller foo: soft stop triggered from within a hard stop hook")
+}
+
+func TestHooksPanicError(t *testing.T) {
+	s := NewSignaller()
+	s.TriggerHardStop()
+
+	errOops := errors.New("oops")
+	s.OnHardStop(func() { panic(errOops) })
+	assert.ErrorIs(t, s.StopErr(), errOops)
+}
+
+func TestHooksPanicEscalation(t *testing.T) {
+	s := NewSignaller(WithHookPanicEscalation())
+
+	var calls []string
+	registerPanickingHook(s)
+	s.OnSoftStop(func() { calls = append(calls, "soft") })
+	s.OnHardStop(func() { calls = append(calls, "hard") })
+
+	s.TriggerSoftStop()
+	assert.True(t, s.IsHardStopSignalled())
+	assert.Equal(t, []string{"soft", "hard"}, calls)
+}
+
+func TestHooksReentrant(t *testing.T) {
+	s := NewSignaller(WithReentrantTriggerDetection())
+
+	var calls []string
+	s.OnSoftStop(func() {
+		calls = append(calls, "soft")
+		s.TriggerSoftStop()
+	})
+	s.OnHardStop(func() { calls = append(calls, "hard") })
+
+	s.TriggerSoftStop()
+	assert.Equal(t, []string{"soft"}, calls)
+
+	var rErr *ReentrantTriggerError
+	require.ErrorAs(t, s.StopErr(), &rErr)
+	assert.Equal(t, TierSoftStop, rErr.HookTier)
+	assert.Equal(t, TierSoftStop, rErr.Triggered)
+	assert.Contains(t, string(rErr.HookStack), "TestHooksReentrant")
+	assert.Contains(t, string(rErr.TriggerStack), "TestHooksReentrant.func1")
+	assert.EqualError(t, rErr, "soft stop triggered from within a soft stop hook")
+}
+
+func TestHooksReentrantUndetected(t *testing.T) {
+	s := NewSignaller()
+	s.OnSoftStop(s.TriggerHardStop)
+
+	s.TriggerSoftStop()
+	assert.True(t, s.IsHardStopSignalled())
+	assert.NoError(t, s.StopErr())
+}
+
+func TestHooksReentrantTransitive(t *testing.T) {
+	s := NewSignaller(WithReentrantTriggerDetection())
+
+	trigger := func() {
+		s.TriggerHardStop()
+	}
+	s.OnSoftStop(trigger)
+
+	s.TriggerSoftStop()
+	assert.True(t, s.IsHardStopSignalled())
+
+	var rErr *ReentrantTriggerError
+	require.ErrorAs(t, s.StopErr(), &rErr)
+	assert.Equal(t, TierSoftStop, rErr.HookTier)
+	assert.Equal(t, TierHardStop, rErr.Triggered)
+}
+
+func TestHooksConcurrentTriggerNotReentrant(t *testing.T) {
+	s := NewSignaller(WithReentrantTriggerDetection())
+
+	release := make(chan struct{})
+	s.OnSoftStop(func() { <-release })
+
+	go s.TriggerSoftStop()
+	for s.ext.Load() == nil || s.ext.Load().firingCount.Load() == 0 {
+		runtime.Gosched()
+	}
+
+	// Triggers from other goroutines are not re-entrant
+	s.TriggerSoftStop()
+	s.TriggerHasStopped()
+	close(release)
+
+	assert.NoError(t, s.StopErr())
+}
+
+func TestHooksConcurrency(t *testing.T) {
+	s := NewSignaller(WithHookConcurrency(4))
+
+	var inFlight, maxInFlight, calls atomic.Int32
+	for i := 0; i < 50; i++ {
+		s.OnSoftStop(func() {
+			n := inFlight.Add(1)
+			for {
+				m := maxInFlight.Load()
+				if n <= m || maxInFlight.CompareAndSwap(m, n) {
+					break
+				}
+			}
+			time.Sleep(time.Millisecond)
+			inFlight.Add(-1)
+			calls.Add(1)
+		})
+	}
+	registerPanickingHook(s)
+
+	s.TriggerSoftStop()
+	assert.Equal(t, int32(50), calls.Load())
+	assert.LessOrEqual(t, maxInFlight.Load(), int32(4))
+	assert.Greater(t, maxInFlight.Load(), int32(1))
+
+	var pErr *HookPanicError
+	assert.ErrorAs(t, s.StopErr(), &pErr)
+}
+
+func TestHooksConcurrencyReentrant(t *testing.T) {
+	s := NewSignaller(WithHookConcurrency(2), WithReentrantTriggerDetection())
+	s.OnSoftStop(func() {})
+	s.OnSoftStop(s.TriggerHardStop)
+
+	s.TriggerSoftStop()
+	var rErr *ReentrantTriggerError
+	assert.ErrorAs(t, s.StopErr(), &rErr)
+}
@@ -0,0 +1,101 @@
+package shutdown
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+)
+
+// ServeHTTP runs an HTTP server on the provided listener, or on the address of
+// the server when the listener is nil, for the lifetime of a signaller. Once a
+// soft stop is signalled the server is shut down gracefully, allowing active
+// requests to complete, and if a hard stop is signalled before they do then
+// the server is closed immediately. The signaller is triggered as having
+// stopped once the server has stopped.
+//
+// Keep-alives are disabled the moment the soft stop is signalled, from the
+// goroutine that signals it, so that clients with idle persistent connections
+// are encouraged to reconnect, and therefore re-resolve to other instances,
+// rather than the drain waiting out long keep-alive timeouts.
+//
+// The error of the server is returned if it fails for any reason other than
+// being shut down, in which case a soft stop is also triggered so that the
+// owner of the signaller observes the failure.
+func ServeHTTP(s *Signaller, srv *http.Server, ln net.Listener) error {
+	defer s.TriggerHasStopped()
+
+	stopKeepAlives := s.OnSoftStop(func() {
+		srv.SetKeepAlivesEnabled(false)
+	})
+	defer stopKeepAlives()
+
+	errC := make(chan error, 1)
+	go func() {
+		if ln == nil {
+			errC <- srv.ListenAndServe()
+		} else {
+			errC <- srv.Serve(ln)
+		}
+	}()
+
+	select {
+	case err := <-errC:
+		s.TriggerSoftStop()
+		if errors.Is(err, http.ErrServerClosed) {
+			return nil
+		}
+		return err
+	case <-s.SoftStopChan():
+	}
+
+	ctx, done := s.HardStopCtx(context.Background())
+	defer done()
+
+	if err := srv.Shutdown(ctx); err != nil {
+		// Requests were still active when the hard stop was signalled.
+		_ = srv.Close()
+	}
+	<-errC
+	return nil
+}
+
+type signallerCtxKey struct{}
+
+// ContextWithSignaller returns a copy of the context that carries the
+// signaller, which can be retrieved with SignallerFromContext.
+func ContextWithSignaller(ctx context.Context, s *Signaller) context.Context {
+	return context.WithValue(ctx, signallerCtxKey{}, s)
+}
+
+// SignallerFromContext returns the signaller carried by the context, or nil,
+// which is a valid signaller that is never signalled, if it has none.
+func SignallerFromContext(ctx context.Context) *Signaller {
+	s, _ := ctx.Value(signallerCtxKey{}).(*Signaller)
+	return s
+}
+
+// HTTPMiddleware returns middleware that admits each request through the gate,
+// which tracks the requests in flight, and rejects requests that arrive after
+// a soft stop with a 503 Service Unavailable response and a Connection: close
+// header, which directs clients to retry elsewhere. The signaller of the gate
+// is carried by the context of each admitted request, see
+// SignallerFromContext.
+//
+// The middleware is compatible with any framework that accepts net/http
+// middleware. Equivalent middleware for gin, echo and fiber is provided by the
+// shutdowngin, shutdownecho and shutdownfiber modules, which are versioned
+// separately so that this module does not depend on the frameworks.
+func HTTPMiddleware(gate *Gate) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if err := gate.Enter(); err != nil {
+				w.Header().Set("Connection", "close")
+				http.Error(w, "server shutting down", http.StatusServiceUnavailable)
+				return
+			}
+			defer gate.Exit()
+			next.ServeHTTP(w, r.WithContext(ContextWithSignaller(r.Context(), gate.s)))
+		})
+	}
+}
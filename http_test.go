@@ -0,0 +1,167 @@
+package shutdown
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func listenLocal(t *testing.T) net.Listener {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	return ln
+}
+
+func TestServeHTTPGraceful(t *testing.T) {
+	ln := listenLocal(t)
+	started, release := make(chan struct{}), make(chan struct{})
+	srv := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		_, _ = w.Write([]byte("hello"))
+	})}
+
+	s := NewSignaller()
+	errC := make(chan error, 1)
+	go func() {
+		errC <- ServeHTTP(s, srv, ln)
+	}()
+
+	resC := make(chan string, 1)
+	go func() {
+		res, err := http.Get("http://" + ln.Addr().String())
+		if err != nil {
+			resC <- err.Error()
+			return
+		}
+		defer res.Body.Close()
+		b, _ := io.ReadAll(res.Body)
+		resC <- string(b)
+	}()
+
+	<-started
+	s.TriggerSoftStop()
+	assertOpen(t, s.HasStoppedChan())
+
+	close(release)
+	assert.Equal(t, "hello", <-resC)
+	require.NoError(t, <-errC)
+	assert.True(t, s.IsHasStoppedSignalled())
+}
+
+func TestServeHTTPDisablesKeepAlives(t *testing.T) {
+	ln := listenLocal(t)
+	started, release := make(chan struct{}), make(chan struct{})
+	srv := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+	})}
+
+	s := NewSignaller()
+	errC := make(chan error, 1)
+	go func() {
+		errC <- ServeHTTP(s, srv, ln)
+	}()
+
+	resC := make(chan *http.Response, 1)
+	go func() {
+		res, err := http.Get("http://" + ln.Addr().String())
+		assert.NoError(t, err)
+		resC <- res
+	}()
+
+	<-started
+	s.TriggerSoftStop()
+	close(release)
+
+	res := <-resC
+	require.NotNil(t, res)
+	res.Body.Close()
+	assert.True(t, res.Close, "expected the connection to be closed after the response")
+	require.NoError(t, <-errC)
+}
+
+func TestServeHTTPHardStop(t *testing.T) {
+	ln := listenLocal(t)
+	started := make(chan struct{})
+	srv := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-r.Context().Done()
+	})}
+
+	s := NewSignaller()
+	errC := make(chan error, 1)
+	go func() {
+		errC <- ServeHTTP(s, srv, ln)
+	}()
+
+	go func() {
+		res, err := http.Get("http://" + ln.Addr().String())
+		if err == nil {
+			res.Body.Close()
+		}
+	}()
+
+	<-started
+	s.TriggerSoftStop()
+	s.TriggerHardStop()
+
+	select {
+	case err := <-errC:
+		require.NoError(t, err)
+	case <-time.After(time.Second * 5):
+		t.Fatal("timed out waiting for server to close")
+	}
+	assert.True(t, s.IsHasStoppedSignalled())
+}
+
+func TestServeHTTPFailure(t *testing.T) {
+	ln := listenLocal(t)
+	require.NoError(t, ln.Close())
+
+	s := NewSignaller()
+	err := ServeHTTP(s, &http.Server{}, ln)
+	assert.True(t, errors.Is(err, net.ErrClosed))
+	assert.True(t, s.IsSoftStopSignalled())
+	assert.True(t, s.IsHasStoppedSignalled())
+}
+
+func TestHTTPMiddleware(t *testing.T) {
+	s := NewSignaller()
+	gate := NewGate(s)
+
+	var inFlight int
+	h := HTTPMiddleware(gate)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		inFlight = gate.InFlight()
+		assert.Equal(t, s, SignallerFromContext(r.Context()))
+		w.WriteHeader(http.StatusTeapot)
+	}))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, http.StatusTeapot, rec.Code)
+	assert.Equal(t, 1, inFlight)
+	assert.Equal(t, 0, gate.InFlight())
+
+	s.TriggerSoftStop()
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.Equal(t, "close", rec.Header().Get("Connection"))
+}
+
+func TestSignallerFromContext(t *testing.T) {
+	assert.Nil(t, SignallerFromContext(context.Background()))
+
+	s := NewSignaller()
+	assert.Equal(t, s, SignallerFromContext(ContextWithSignaller(context.Background(), s)))
+}
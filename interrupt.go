@@ -0,0 +1,66 @@
+package shutdown
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+// Messages suitable for WithInterruptMessages.
+const (
+	DefaultInterruptMessage = "Shutting down gracefully, press Ctrl+C again to force"
+	DefaultStoppedMessage   = "Stopped"
+)
+
+type interruptMessages struct {
+	w       io.Writer
+	first   string
+	stopped string
+}
+
+// print writes the first message, or the stopped message, if there is one.
+func (m *interruptMessages) print(stopped bool) {
+	if m == nil {
+		return
+	}
+	msg := m.first
+	if stopped {
+		msg = m.stopped
+	}
+	if msg != "" {
+		fmt.Fprintln(m.w, msg)
+	}
+}
+
+// WithInterruptMessages causes the OS signal listener, as configured with
+// WithSignals, to print a message to w once the first signal begins a soft
+// stop, which explains that a further signal forces a hard stop, and another
+// once the signaller has stopped, giving command line tools the same polish as
+// docker or kubectl. Empty messages are not printed, and
+// DefaultInterruptMessage and DefaultStoppedMessage are suitable for most
+// programs:
+//
+//	shutdown.WithInterruptMessages(nil, shutdown.DefaultInterruptMessage, shutdown.DefaultStoppedMessage)
+//
+// When w is nil the messages are written to stderr. Messages written to a file
+// are only printed when the file is a terminal, and so they do not pollute the
+// logs of programs that are not run interactively.
+func WithInterruptMessages(w io.Writer, first, stopped string) Option {
+	return func(o *options) {
+		if w == nil {
+			w = os.Stderr
+		}
+		if f, ok := w.(*os.File); ok && !isTerminal(f) {
+			o.interruptMessages = nil
+			return
+		}
+		o.interruptMessages = &interruptMessages{w: w, first: first, stopped: stopped}
+	}
+}
+
+// isTerminal reports whether a file is a character device, which is true of
+// terminals and consoles.
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,77 @@
+package shutdown
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// MetricsWithLabels is an optional extension of Metrics for implementations
+// that support labels, such as metric vectors. When a signaller is constructed
+// with both WithMetrics and WithLabels, WithLabels is called once with the
+// labels of the signaller, and the Metrics returned are used in place of the
+// original for the lifetime of the signaller.
+type MetricsWithLabels interface {
+	Metrics
+
+	WithLabels(labels map[string]string) Metrics
+}
+
+// WithLabels attaches string key/value labels to the signaller that describe
+// it, such as the type of component, a tenant or a shard. Labels are included
+// in the log records, events, diagnostics and snapshots of the signaller, and
+// are provided to metrics that implement MetricsWithLabels, so that downstream
+// observability is consistently tagged. Labels provided by repeated options are
+// merged.
+func WithLabels(labels map[string]string) Option {
+	return func(o *options) {
+		if o.labels == nil {
+			o.labels = make(map[string]string, len(labels))
+		}
+		for k, v := range labels {
+			o.labels[k] = v
+		}
+	}
+}
+
+// Labels returns the labels of the signaller, as configured with WithLabels.
+// The returned map must not be modified.
+func (s *Signaller) Labels() map[string]string {
+	return s.config().labels
+}
+
+// applyLabels prepares the labels of the signaller for use by its logs and
+// metrics, and is called once at construction.
+func (x *extra) applyLabels() {
+	if len(x.labels) == 0 {
+		return
+	}
+	for _, k := range sortedKeys(x.labels) {
+		x.labelArgs = append(x.labelArgs, k, x.labels[k])
+	}
+	if m, ok := x.metrics.(MetricsWithLabels); ok {
+		x.metrics = m.WithLabels(x.labels)
+	}
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// formatLabels returns labels as space separated key=value pairs sorted by
+// key.
+func formatLabels(labels map[string]string) string {
+	var b strings.Builder
+	for i, k := range sortedKeys(labels) {
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		fmt.Fprintf(&b, "%v=%v", k, labels[k])
+	}
+	return b.String()
+}
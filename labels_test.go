@@ -0,0 +1,74 @@
+package shutdown
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type labelledMetrics struct {
+	recordingMetrics
+
+	labels map[string]string
+}
+
+func (m *labelledMetrics) WithLabels(labels map[string]string) Metrics {
+	return &labelledMetrics{labels: labels}
+}
+
+func TestWithLabels(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}))
+
+	labels := map[string]string{"tenant": "acme", "component": "ingest"}
+	m := &labelledMetrics{}
+	s := NewSignaller(
+		WithName("foo"),
+		WithLogger(logger),
+		WithMetrics(m),
+		WithLabels(map[string]string{"tenant": "acme"}),
+		WithLabels(map[string]string{"component": "ingest"}),
+	)
+	assert.Equal(t, labels, s.Labels())
+	assert.Equal(t, labels, s.Config().Labels)
+
+	lm, ok := s.Config().Metrics.(*labelledMetrics)
+	require.True(t, ok)
+	assert.Equal(t, labels, lm.labels)
+
+	events, cancel := s.Subscribe()
+	defer cancel()
+
+	s.TriggerSoftStop()
+	assert.Equal(t, "level=INFO msg=\"shutdown signalled\" tier=\"soft stop\" signaller=foo component=ingest tenant=acme\n", buf.String())
+	assert.Equal(t, labels, (<-events).Labels)
+	assert.Equal(t, []tierObservation{{"foo", TierSoftStop}}, lm.signals)
+	assert.Empty(t, m.signals)
+
+	var report bytes.Buffer
+	_, err := s.Diagnostics().WriteTo(&report)
+	require.NoError(t, err)
+	assert.Contains(t, report.String(), "\n  labels: component=ingest tenant=acme\n")
+
+	b, err := json.Marshal(s.Snapshot())
+	require.NoError(t, err)
+	assert.Contains(t, string(b), `"labels":{"component":"ingest","tenant":"acme"}`)
+}
+
+func TestWithoutLabels(t *testing.T) {
+	assert.Nil(t, NewSignaller().Labels())
+
+	var s *Signaller
+	assert.Nil(t, s.Labels())
+}
@@ -0,0 +1,73 @@
+package shutdown
+
+// Notify causes the signaller to relay lifecycle events to the channel, in the
+// style of signal.Notify, for the provided tiers or for all tiers when none are
+// provided, in which case reloads are also relayed, see TriggerReload. An
+// abandoned soft stop, see AbortSoftStop, is relayed along with the soft stop
+// tier.
+//
+// Events are sent without blocking, and so the caller must ensure that the
+// channel has sufficient buffer space to keep up with the events it expects.
+// Calling Notify again with the same channel adds to the tiers relayed to it.
+// The channel is never closed by the signaller.
+func (s *Signaller) Notify(ch chan<- Event, tiers ...Tier) {
+	if s == nil || ch == nil {
+		return
+	}
+	var mask uint32
+	for _, t := range tiers {
+		mask |= t.bit()
+	}
+	if len(tiers) == 0 {
+		mask = TierSoftStop.bit() | TierHardStop.bit() | TierHasStopped.bit() | reloadBit
+	}
+
+	x := s.extra()
+	x.subs.update(func(subs []*subscriber) []*subscriber {
+		for _, sub := range subs {
+			if sub.out == ch {
+				for old := sub.tiers.Load(); !sub.tiers.CompareAndSwap(old, old|mask); {
+					old = sub.tiers.Load()
+				}
+				return subs
+			}
+		}
+		sub := &subscriber{out: ch}
+		sub.tiers.Store(mask)
+		next := make([]*subscriber, len(subs), len(subs)+1)
+		copy(next, subs)
+		return append(next, sub)
+	})
+}
+
+// Stop causes the signaller to stop relaying events to the channel, undoing the
+// effect of prior calls to Notify with it. Once Stop returns no more events
+// are sent to the channel.
+func (s *Signaller) Stop(ch chan<- Event) {
+	if s == nil || ch == nil {
+		return
+	}
+	x := s.ext.Load()
+	if x == nil {
+		return
+	}
+	var removed *subscriber
+	x.subs.update(func(subs []*subscriber) []*subscriber {
+		removed = nil
+		next := make([]*subscriber, 0, len(subs))
+		for _, sub := range subs {
+			if sub.out == ch {
+				removed = sub
+				continue
+			}
+			next = append(next, sub)
+		}
+		return next
+	})
+	if removed != nil {
+		// Wait for any delivery in progress before returning.
+		removed.mut.Lock()
+		removed.closed = true
+		removed.mut.Unlock()
+	}
+}
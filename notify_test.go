@@ -0,0 +1,78 @@
+package shutdown
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func readNotified(ch chan Event) []EventKind {
+	var kinds []EventKind
+	for {
+		select {
+		case e := <-ch:
+			kinds = append(kinds, e.Kind)
+		default:
+			return kinds
+		}
+	}
+}
+
+func TestNotify(t *testing.T) {
+	s := NewSignaller(WithReversibleSoftStop())
+	ch := make(chan Event, 8)
+	s.Notify(ch)
+
+	s.TriggerSoftStop()
+	require.True(t, s.AbortSoftStop())
+	s.TriggerHardStop()
+	s.TriggerHasStopped()
+
+	assert.Equal(t, []EventKind{
+		EventSoftStop, EventSoftStopAborted, EventSoftStop, EventHardStop, EventHasStopped,
+	}, readNotified(ch))
+}
+
+func TestNotifyTiers(t *testing.T) {
+	s := NewSignaller()
+	ch := make(chan Event, 8)
+	s.Notify(ch, TierHardStop)
+	s.Notify(ch, TierHasStopped)
+
+	s.TriggerHardStop()
+	s.TriggerHasStopped()
+	assert.Equal(t, []EventKind{EventHardStop, EventHasStopped}, readNotified(ch))
+}
+
+func TestNotifyNonBlocking(t *testing.T) {
+	s := NewSignaller()
+	ch := make(chan Event, 1)
+	s.Notify(ch)
+
+	s.TriggerHardStop()
+	s.TriggerHasStopped()
+	assert.Equal(t, []EventKind{EventSoftStop}, readNotified(ch))
+}
+
+func TestNotifyStop(t *testing.T) {
+	s := NewSignaller()
+	a, b := make(chan Event, 4), make(chan Event, 4)
+	s.Notify(a)
+	s.Notify(b)
+
+	s.TriggerSoftStop()
+	s.Stop(a)
+	s.Stop(a)
+	s.TriggerHardStop()
+
+	assert.Equal(t, []EventKind{EventSoftStop}, readNotified(a))
+	assert.Equal(t, []EventKind{EventSoftStop, EventHardStop}, readNotified(b))
+
+	// The caller owns the channel, and so it remains open.
+	select {
+	case a <- Event{}:
+	default:
+		t.Error("channel not writable")
+	}
+}
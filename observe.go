@@ -0,0 +1,64 @@
+package shutdown
+
+import (
+	"context"
+	"log/slog"
+)
+
+// Metrics receives measurements of the lifecycle of a Signaller, allowing it to
+// be bridged to a metrics library of choice without this package depending on
+// one. Implementations must be safe for concurrent use, and should not block
+// as they are called from the goroutine making a signal.
+type Metrics interface {
+	// Signalled is called once each time a tier is signalled.
+	Signalled(name string, t Tier)
+
+	// HookPanicked is called each time a hook of a tier panics.
+	HookPanicked(name string, t Tier)
+}
+
+// log writes a record to the logger of the signaller, if configured,
+// attributed to the signaller by name when it has one.
+func (x *extra) log(level slog.Level, msg string, args ...any) {
+	if x.logger == nil {
+		return
+	}
+	if name := x.currentName(); name != "" {
+		args = append(args, "signaller", name)
+	}
+	args = append(args, x.labelArgs...)
+	x.logger.Log(context.Background(), level, msg, args...)
+}
+
+// observeSignal reports a tier being signalled to the logger and metrics of
+// the signaller.
+func (s *Signaller) observeSignal(t Tier) {
+	x := s.ext.Load()
+	if x == nil {
+		return
+	}
+	attrs := s.StopAttrs()
+	if m, ok := x.metrics.(MetricsWithAttrs); ok {
+		m.SignalledWithAttrs(x.currentName(), t, attrs)
+	} else if x.metrics != nil {
+		x.metrics.Signalled(x.currentName(), t)
+	}
+	args := append([]any{"tier", t.String()}, attrArgs(attrs)...)
+	if reasons := s.Reasons(); len(reasons) > 0 {
+		args = append(args, "reasons", reasons)
+	}
+	x.log(slog.LevelInfo, "shutdown signalled", args...)
+}
+
+// observeHookPanic reports a panicking hook to the logger and metrics of the
+// signaller.
+func (s *Signaller) observeHookPanic(err *HookPanicError) {
+	x := s.ext.Load()
+	if x == nil {
+		return
+	}
+	if x.metrics != nil {
+		x.metrics.HookPanicked(err.Signaller, err.Tier)
+	}
+	x.log(slog.LevelError, "shutdown hook panicked", "tier", err.Tier.String(), "panic", err.Value)
+}
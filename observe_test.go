@@ -0,0 +1,76 @@
+package shutdown
+
+import (
+	"bytes"
+	"log/slog"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type tierObservation struct {
+	name string
+	tier Tier
+}
+
+type recordingMetrics struct {
+	mut      sync.Mutex
+	signals  []tierObservation
+	panicked []tierObservation
+}
+
+func (m *recordingMetrics) Signalled(name string, t Tier) {
+	m.mut.Lock()
+	m.signals = append(m.signals, tierObservation{name, t})
+	m.mut.Unlock()
+}
+
+func (m *recordingMetrics) HookPanicked(name string, t Tier) {
+	m.mut.Lock()
+	m.panicked = append(m.panicked, tierObservation{name, t})
+	m.mut.Unlock()
+}
+
+func TestWithMetrics(t *testing.T) {
+	m := &recordingMetrics{}
+	s := NewSignaller(WithName("foo"), WithMetrics(m))
+
+	s.OnHardStop(func() {
+		panic("nope")
+	})
+	s.TriggerHardStop()
+	s.TriggerHardStop()
+	s.TriggerHasStopped()
+
+	assert.Equal(t, []tierObservation{
+		{"foo", TierSoftStop},
+		{"foo", TierHardStop},
+		{"foo", TierHasStopped},
+	}, m.signals)
+	assert.Equal(t, []tierObservation{
+		{"foo", TierHardStop},
+	}, m.panicked)
+}
+
+func TestWithLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}))
+
+	s := NewSignaller(WithName("foo"), WithLogger(logger))
+	s.OnSoftStop(func() {
+		panic("nope")
+	})
+	s.TriggerSoftStop()
+
+	assert.Equal(t, `level=INFO msg="shutdown signalled" tier="soft stop" signaller=foo
+level=ERROR msg="shutdown hook panicked" tier="soft stop" panic=nope signaller=foo
+`, buf.String())
+}
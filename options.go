@@ -0,0 +1,345 @@
+package shutdown
+
+import (
+	"context"
+	"errors"
+	"log"
+	"log/slog"
+	"os"
+	"runtime"
+	"runtime/debug"
+	"sync/atomic"
+	"time"
+)
+
+// Option configures a Signaller at construction.
+type Option func(o *options)
+
+// options holds the configuration of a Signaller. Signallers constructed
+// without options share a nil pointer, and so configuration costs nothing for
+// the majority of signallers that do not need it.
+type options struct {
+	strictOrdering func(err error)
+	onLeak         func(constructedAt []byte)
+
+	escalateHookPanics bool
+	reentrantTriggers  bool
+
+	closeTimeout time.Duration
+
+	independentTiers   bool
+	reversibleSoftStop bool
+
+	hardStopGrace  time.Duration
+	escalation     *EscalationPolicy
+	extensionLimit time.Duration
+
+	watchdogDeadline time.Duration
+	onWatchdog       func(s *Signaller)
+
+	historyLimit   int
+	triggerStacks  bool
+	recordCtxSites bool
+	goroutineGate  bool
+
+	labels    map[string]string
+	labelArgs []any
+
+	name    string
+	logger  *slog.Logger
+	metrics Metrics
+	clock   Clock
+
+	hookConcurrency int
+
+	signals     []os.Signal
+	signalTiers map[os.Signal]Tier
+	drainDelay  time.Duration
+	deregister  []func(ctx context.Context) error
+
+	abdications []abdication
+
+	diagnosticsSignals []os.Signal
+	onDiagnostics      func(d Diagnostics)
+
+	reloadSignals []os.Signal
+
+	exitCodes *ExitCodes
+
+	interruptMessages *interruptMessages
+}
+
+// ErrStoppedBeforeSignal is reported in strict ordering mode when a Signaller
+// is marked as having stopped before it was asked to stop.
+var ErrStoppedBeforeSignal = errors.New("signaller triggered has stopped before a soft or hard stop was signalled")
+
+// WithStrictOrdering enables a strict mode where out-of-order transitions, such
+// as TriggerHasStopped being called before either a soft or hard stop has been
+// signalled, are reported to the provided function. When the function is nil
+// out-of-order transitions cause a panic instead, which is useful in tests for
+// catching components that report having stopped while they are still running.
+//
+// If the provided function returns then the transition is still made.
+//
+// Signallers that this package triggers as having stopped once the work they
+// watch has completed may legitimately stop without a prior stop, and are not
+// reported. This applies to the signaller of Run and Builder.Run once the
+// program or every component has returned, and to the parent of a Group, see
+// WithParent, once its members have stopped after the group itself was
+// stopped rather than the parent. A component of a Builder that returns before
+// being stopped is still reported when the option is provided as one of the
+// options of the component.
+func WithStrictOrdering(onViolation func(err error)) Option {
+	return func(o *options) {
+		if onViolation == nil {
+			onViolation = func(err error) {
+				panic(err)
+			}
+		}
+		o.strictOrdering = onViolation
+	}
+}
+
+// WithLeakDetection is a debug option that records the stack of the caller of
+// NewSignaller and sets a finalizer on a sentinel owned by the signaller. If
+// the signaller is garbage collected without TriggerHasStopped ever having
+// been called then the provided function is called with the recorded stack,
+// which catches components that silently never complete their lifecycle. When
+// the function is nil the stack is logged with the standard logger instead.
+//
+// Capturing stacks and setting finalizers is expensive, and therefore this
+// option is intended for tests and debugging rather than production use.
+// Finalizers are not guaranteed to run, and so the absence of a report is not
+// proof that no leak occurred.
+func WithLeakDetection(onLeak func(constructedAt []byte)) Option {
+	return func(o *options) {
+		if onLeak == nil {
+			onLeak = func(constructedAt []byte) {
+				log.Printf("shutdown: signaller garbage collected without having stopped, constructed at:\n%s", constructedAt)
+			}
+		}
+		o.onLeak = onLeak
+	}
+}
+
+// leakSentinel carries the finalizer of a signaller constructed with
+// WithLeakDetection. Derived contexts form reference cycles with the signaller,
+// such as through its waiters, and finalizers are not run for objects that are
+// part of a cycle. The sentinel is therefore referenced only by the signaller
+// and references nothing that leads back to it.
+type leakSentinel struct {
+	stopped atomic.Bool
+}
+
+func setLeakFinalizer(x *extra, onLeak func(constructedAt []byte)) {
+	stack := debug.Stack()
+	x.leak = &leakSentinel{}
+	runtime.SetFinalizer(x.leak, func(l *leakSentinel) {
+		if !l.stopped.Load() {
+			onLeak(stack)
+		}
+	})
+}
+
+// WithHookPanicEscalation causes a hard stop to be triggered whenever a hook
+// panics, once the remaining hooks of the same tier have been called.
+func WithHookPanicEscalation() Option {
+	return func(o *options) {
+		o.escalateHookPanics = true
+	}
+}
+
+// WithReentrantTriggerDetection is a debug option that records a
+// *ReentrantTriggerError against the signaller whenever a hook calls one of
+// its Trigger methods, either directly or transitively, from the goroutine
+// calling the hooks.
+//
+// Detection identifies goroutines by parsing their stack traces, which adds a
+// mutex and a stack capture to the calling of hooks, and therefore this option
+// is intended for tests and debugging rather than production use.
+func WithReentrantTriggerDetection() Option {
+	return func(o *options) {
+		o.reentrantTriggers = true
+	}
+}
+
+// WithCloseTimeout sets the maximum duration that Close waits for the signaller
+// to report having stopped, which is 30 seconds by default. A zero or negative
+// duration causes Close to wait indefinitely.
+func WithCloseTimeout(timeout time.Duration) Option {
+	return func(o *options) {
+		o.closeTimeout = timeout
+	}
+}
+
+// WithIndependentTiers decouples the soft and hard stop tiers so that a hard
+// stop no longer implies a soft stop, for designs where the two tiers drive
+// different machinery. Each tier must then be triggered explicitly, and the
+// soft stop channel, context and hooks only observe calls to TriggerSoftStop.
+func WithIndependentTiers() Option {
+	return func(o *options) {
+		o.independentTiers = true
+	}
+}
+
+// WithReversibleSoftStop enables AbortSoftStop, which allows a soft stop to be
+// cancelled before a hard stop has been signalled or the component has
+// stopped. Components that support this mode should observe the events of the
+// signaller, as channels and contexts closed by the soft stop remain closed.
+func WithReversibleSoftStop() Option {
+	return func(o *options) {
+		o.reversibleSoftStop = true
+	}
+}
+
+// WithHardStopGrace configures an escalation timer, where once a soft stop has
+// been signalled a hard stop is triggered automatically after the grace period
+// elapses, unless the component stops first.
+//
+// While the escalation is pending the time of the hard stop is reported by
+// HardStopDeadline, and contexts derived from the soft and hard stop tiers
+// report it through their Deadline method, allowing libraries that budget
+// their work by context deadlines to do so correctly during a drain. Contexts
+// derived before the soft stop do not, as the deadline of a context cannot
+// change after it has been created.
+func WithHardStopGrace(grace time.Duration) Option {
+	return func(o *options) {
+		o.hardStopGrace = grace
+	}
+}
+
+// WithEscalationPolicy configures a schedule of steps that are taken once a
+// soft stop has been signalled, which replaces any grace period configured with
+// WithHardStopGrace. The hard stop time of the policy, where it has one, is
+// reported by HardStopDeadline and contexts in the same way as a grace period,
+// and the time of each step is reported by StepDeadline.
+func WithEscalationPolicy(p EscalationPolicy) Option {
+	return func(o *options) {
+		o.escalation = p.sorted()
+	}
+}
+
+// WithHookConcurrency allows up to n hooks of a tier to run concurrently when
+// the tier is signalled, rather than one at a time. The hooks are started in
+// the order they were registered, and the Trigger method that signalled the
+// tier still blocks until all of them have returned. This bounds the cost of
+// stopping components that register hundreds of hooks, such as one per
+// connection, without tearing them all down simultaneously. A value of one or
+// less selects sequential execution, which is the default.
+func WithHookConcurrency(n int) Option {
+	return func(o *options) {
+		o.hookConcurrency = n
+	}
+}
+
+// WithName assigns a name to the signaller, which identifies it within logs,
+// metrics and errors. The name can be changed later with SetName.
+func WithName(name string) Option {
+	return func(o *options) {
+		o.name = name
+	}
+}
+
+// WithLogger configures a logger to which the signaller writes a record of each
+// signal made and each hook that panics. Signallers do not log by default.
+func WithLogger(logger *slog.Logger) Option {
+	return func(o *options) {
+		o.logger = logger
+	}
+}
+
+// WithMetrics configures an implementation of Metrics that receives
+// measurements of the lifecycle of the signaller.
+func WithMetrics(m Metrics) Option {
+	return func(o *options) {
+		o.metrics = m
+	}
+}
+
+// WithClock configures the source of time of the signaller, which is the system
+// clock by default. The clock drives the hard stop grace period, the timeouts
+// of contexts derived with SoftStopCtxWithTimeout and HardStopCtxWithTimeout,
+// and the timestamps of events. The timeout of Close always uses the system
+// clock.
+func WithClock(c Clock) Option {
+	return func(o *options) {
+		o.clock = c
+	}
+}
+
+// Config describes the configuration of a Signaller, as returned by its Config
+// method, allowing adapters and tooling to discover how a signaller was
+// constructed rather than requiring the same configuration to be provided
+// twice.
+type Config struct {
+	Name          string
+	HardStopGrace time.Duration
+
+	// Nil unless configured with WithEscalationPolicy.
+	EscalationPolicy *EscalationPolicy
+
+	// The total duration by which RequestExtension can postpone escalation.
+	ExtensionLimit time.Duration
+
+	// The stop deadline configured with WithWatchdog.
+	WatchdogDeadline time.Duration
+
+	CloseTimeout        time.Duration
+	IndependentTiers    bool
+	ReversibleSoftStop  bool
+	HookPanicEscalation bool
+	ReentrantTriggers   bool
+	HookConcurrency     int
+	StrictOrdering      bool
+	LeakDetection       bool
+	TriggerStacks       bool
+	GoroutineGate       bool
+
+	// The OS signals listened for, as configured with WithSignals.
+	Signals []os.Signal
+
+	// The OS signals mapped to tiers, as configured with WithSignalTier.
+	SignalTiers map[os.Signal]Tier
+
+	// The delay of soft stops triggered by OS signals.
+	DrainDelay time.Duration
+
+	// Nil unless configured with WithLogger and WithMetrics respectively.
+	Logger  *slog.Logger
+	Metrics Metrics
+
+	// The labels configured with WithLabels.
+	Labels map[string]string
+
+	// The system clock unless configured with WithClock.
+	Clock Clock
+}
+
+// Config returns the configuration of the signaller.
+func (s *Signaller) Config() Config {
+	o := s.config()
+	return Config{
+		Name:                s.Name(),
+		HardStopGrace:       o.hardStopGrace,
+		EscalationPolicy:    o.escalation,
+		ExtensionLimit:      o.extensionLimit,
+		WatchdogDeadline:    o.watchdogDeadline,
+		CloseTimeout:        o.closeTimeout,
+		IndependentTiers:    o.independentTiers,
+		ReversibleSoftStop:  o.reversibleSoftStop,
+		HookPanicEscalation: o.escalateHookPanics,
+		ReentrantTriggers:   o.reentrantTriggers,
+		HookConcurrency:     o.hookConcurrency,
+		StrictOrdering:      o.strictOrdering != nil,
+		LeakDetection:       o.onLeak != nil,
+		TriggerStacks:       o.triggerStacks,
+		GoroutineGate:       o.goroutineGate,
+		Signals:             o.signals,
+		SignalTiers:         o.signalTiers,
+		DrainDelay:          o.drainDelay,
+		Logger:              o.logger,
+		Metrics:             o.metrics,
+		Labels:              o.labels,
+		Clock:               s.clock(),
+	}
+}
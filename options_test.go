@@ -0,0 +1,176 @@
+package shutdown
+
+import (
+	"context"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStrictOrderingPanics(t *testing.T) {
+	s := NewSignaller(WithStrictOrdering(nil))
+	assert.PanicsWithError(t, ErrStoppedBeforeSignal.Error(), s.TriggerHasStopped)
+	assert.False(t, s.IsHasStoppedSignalled())
+
+	s.TriggerSoftStop()
+	assert.NotPanics(t, s.TriggerHasStopped)
+	assert.True(t, s.IsHasStoppedSignalled())
+}
+
+func TestStrictOrderingCallback(t *testing.T) {
+	var errs []error
+	s := NewSignaller(WithStrictOrdering(func(err error) {
+		errs = append(errs, err)
+	}))
+
+	s.TriggerHasStopped()
+	assert.Equal(t, []error{ErrStoppedBeforeSignal}, errs)
+	assert.True(t, s.IsHasStoppedSignalled())
+}
+
+func TestStrictOrderingDisabled(t *testing.T) {
+	s := NewSignaller()
+	assert.NotPanics(t, s.TriggerHasStopped)
+	assert.True(t, s.IsHasStoppedSignalled())
+}
+
+func TestStrictOrderingCompletion(t *testing.T) {
+	var errs []error
+	strict := WithStrictOrdering(func(err error) {
+		errs = append(errs, err)
+	})
+
+	// Programs and groups that complete on their own are not violations.
+	require.NoError(t, RunContext(context.Background(), func(ctx context.Context, s *Signaller) error {
+		return nil
+	}, strict))
+
+	require.NoError(t, New().WithOptions(strict).WithComponent("foo", func(s *Signaller) error {
+		return nil
+	}).Run(context.Background()))
+
+	parent := NewSignaller(strict)
+	g := NewGroup(WithParent(parent))
+	m := NewSignaller()
+	g.Add(m)
+	g.TriggerSoftStop()
+	m.TriggerHasStopped()
+	assert.True(t, parent.IsHasStoppedSignalled())
+	assert.Empty(t, errs)
+
+	// Whereas components configured with strict ordering are.
+	require.NoError(t, New().WithComponent("foo", func(s *Signaller) error {
+		return nil
+	}, strict).Run(context.Background()))
+	assert.Equal(t, []error{ErrStoppedBeforeSignal}, errs)
+}
+
+func TestLeakDetection(t *testing.T) {
+	leaks := make(chan []byte, 2)
+	onLeak := func(constructedAt []byte) {
+		leaks <- constructedAt
+	}
+
+	func() {
+		_ = NewSignaller(WithLeakDetection(onLeak))
+
+		stopped := NewSignaller(WithLeakDetection(onLeak))
+		stopped.TriggerHardStop()
+		stopped.TriggerHasStopped()
+	}()
+
+	deadline := time.Now().Add(time.Second * 5)
+	var stack []byte
+	for stack == nil && time.Now().Before(deadline) {
+		runtime.GC()
+		select {
+		case stack = <-leaks:
+		case <-time.After(time.Millisecond * 10):
+		}
+	}
+	require.NotNil(t, stack)
+	assert.Contains(t, string(stack), "TestLeakDetection")
+
+	// Only the signaller that never stopped should be reported.
+	runtime.GC()
+	select {
+	case <-leaks:
+		t.Error("unexpected second leak")
+	case <-time.After(time.Millisecond * 50):
+	}
+}
+
+func TestLeakDetectionDerivedContext(t *testing.T) {
+	leaks := make(chan []byte, 1)
+	onLeak := func(constructedAt []byte) {
+		leaks <- constructedAt
+	}
+
+	func() {
+		s := NewSignaller(WithLeakDetection(onLeak))
+
+		// The unreleased context is referenced by the waiters of the
+		// signaller, and references the signaller in turn.
+		_, _ = s.SoftStopCtx(context.Background())
+	}()
+
+	deadline := time.Now().Add(time.Second * 5)
+	var stack []byte
+	for stack == nil && time.Now().Before(deadline) {
+		runtime.GC()
+		select {
+		case stack = <-leaks:
+		case <-time.After(time.Millisecond * 10):
+		}
+	}
+	require.NotNil(t, stack)
+	assert.Contains(t, string(stack), "TestLeakDetectionDerivedContext")
+}
+
+func TestIndependentTiers(t *testing.T) {
+	s := NewSignaller(WithIndependentTiers(), WithStrictOrdering(nil))
+
+	s.TriggerHardStop()
+	assert.True(t, s.IsHardStopSignalled())
+	assert.False(t, s.IsSoftStopSignalled())
+	assertOpen(t, s.SoftStopChan())
+
+	assert.NotPanics(t, s.TriggerHasStopped)
+
+	s.TriggerSoftStop()
+	assert.True(t, s.IsSoftStopSignalled())
+	assertClosed(t, s.SoftStopChan())
+}
+
+func TestConfig(t *testing.T) {
+	m := &recordingMetrics{}
+	clock := newManualClock()
+
+	s := NewSignaller(
+		WithName("foo"),
+		WithHardStopGrace(time.Second),
+		WithCloseTimeout(time.Minute),
+		WithIndependentTiers(),
+		WithMetrics(m),
+		WithClock(clock),
+	)
+	assert.Equal(t, Config{
+		Name:             "foo",
+		HardStopGrace:    time.Second,
+		CloseTimeout:     time.Minute,
+		IndependentTiers: true,
+		Metrics:          m,
+		Clock:            clock,
+	}, s.Config())
+
+	assert.Equal(t, Config{
+		CloseTimeout: time.Second * 30,
+		Clock:        systemClock{},
+	}, NewSignaller().Config())
+
+	var nilSig *Signaller
+	assert.Equal(t, NewSignaller().Config(), nilSig.Config())
+}
@@ -0,0 +1,66 @@
+package shutdown
+
+import (
+	"context"
+	"errors"
+	"net"
+	"time"
+)
+
+// maxDatagramSize is the size of the buffer that datagrams are read into,
+// which fits the largest possible UDP payload.
+const maxDatagramSize = 65535
+
+// ServePacket runs a read loop on the provided packet connection for the
+// lifetime of a signaller, calling handle with each datagram received along
+// with the address of its sender, to which responses can be written with
+// WriteTo. Datagrams are handled one at a time from the read loop, and the
+// buffer provided to handle is reused once it returns.
+//
+// Once a soft stop is signalled no further datagrams are read, the datagram
+// being handled, if any, is allowed to finish so that its response is written,
+// and the connection is then closed. Once a hard stop is signalled the context
+// provided to handle is cancelled and the connection is closed immediately.
+// The signaller is triggered as having stopped once the connection has
+// closed.
+//
+// The error of the connection is returned if reading fails for any reason
+// other than the stop, in which case a soft stop is also triggered so that the
+// owner of the signaller observes the failure.
+func ServePacket(s *Signaller, pc net.PacketConn, handle func(ctx context.Context, pc net.PacketConn, b []byte, addr net.Addr)) error {
+	defer s.TriggerHasStopped()
+	defer pc.Close()
+
+	ctx, done := s.HardStopCtx(context.Background())
+	defer done()
+
+	// Interrupt a blocked read once a soft stop is signalled, and abandon
+	// responses once a hard stop is signalled.
+	stopSoft := s.OnSoftStop(func() {
+		_ = pc.SetReadDeadline(time.Now())
+	})
+	defer stopSoft()
+	stopHard := s.OnHardStop(func() {
+		_ = pc.Close()
+	})
+	defer stopHard()
+
+	b := make([]byte, maxDatagramSize)
+	for {
+		n, addr, err := pc.ReadFrom(b)
+		if n > 0 && !s.IsSoftStopSignalled() {
+			handle(ctx, pc, b[:n], addr)
+		}
+		if s.IsSoftStopSignalled() {
+			return nil
+		}
+		if err != nil {
+			var ne net.Error
+			if errors.As(err, &ne) && ne.Timeout() {
+				continue
+			}
+			s.TriggerSoftStop()
+			return err
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package shutdown
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func listenPacketLocal(t *testing.T) net.PacketConn {
+	t.Helper()
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	return pc
+}
+
+func runServePacket(s *Signaller, pc net.PacketConn, handle func(ctx context.Context, pc net.PacketConn, b []byte, addr net.Addr)) <-chan error {
+	errC := make(chan error, 1)
+	go func() {
+		errC <- ServePacket(s, pc, handle)
+	}()
+	return errC
+}
+
+func waitServePacket(t *testing.T, errC <-chan error) {
+	t.Helper()
+	select {
+	case err := <-errC:
+		require.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for serve to return")
+	}
+}
+
+func TestServePacketEcho(t *testing.T) {
+	s := NewSignaller()
+	pc := listenPacketLocal(t)
+
+	errC := runServePacket(s, pc, func(ctx context.Context, pc net.PacketConn, b []byte, addr net.Addr) {
+		_, _ = pc.WriteTo(b, addr)
+	})
+
+	conn, err := net.Dial("udp", pc.LocalAddr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	_, err = conn.Write([]byte("hello"))
+	require.NoError(t, err)
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(time.Second)))
+	buf := make([]byte, 16)
+	n, err := conn.Read(buf)
+	require.NoError(t, err)
+	assert.Equal(t, "hello", string(buf[:n]))
+
+	s.TriggerSoftStop()
+	waitServePacket(t, errC)
+	assertClosed(t, s.HasStoppedChan())
+}
+
+func TestServePacketFlushesResponse(t *testing.T) {
+	s := NewSignaller()
+	pc := listenPacketLocal(t)
+
+	started := make(chan struct{})
+	errC := runServePacket(s, pc, func(ctx context.Context, pc net.PacketConn, b []byte, addr net.Addr) {
+		close(started)
+		<-s.SoftStopChan()
+		_, _ = pc.WriteTo([]byte("bye"), addr)
+	})
+
+	conn, err := net.Dial("udp", pc.LocalAddr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	_, err = conn.Write([]byte("hello"))
+	require.NoError(t, err)
+	<-started
+	s.TriggerSoftStop()
+
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(time.Second)))
+	buf := make([]byte, 16)
+	n, err := conn.Read(buf)
+	require.NoError(t, err)
+	assert.Equal(t, "bye", string(buf[:n]))
+
+	waitServePacket(t, errC)
+	assertClosed(t, s.HasStoppedChan())
+}
+
+func TestServePacketHardStop(t *testing.T) {
+	s := NewSignaller()
+	pc := listenPacketLocal(t)
+
+	started := make(chan struct{})
+	errC := runServePacket(s, pc, func(ctx context.Context, pc net.PacketConn, b []byte, addr net.Addr) {
+		close(started)
+		<-ctx.Done()
+	})
+
+	conn, err := net.Dial("udp", pc.LocalAddr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	_, err = conn.Write([]byte("hello"))
+	require.NoError(t, err)
+	<-started
+
+	s.TriggerHardStop()
+	waitServePacket(t, errC)
+	assertClosed(t, s.HasStoppedChan())
+}
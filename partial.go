@@ -0,0 +1,103 @@
+package shutdown
+
+import (
+	"context"
+	"time"
+)
+
+// TriggerSoftStopNamed signals to the members of the group with any of the
+// provided names that they should terminate at their own leisure, leaving the
+// remaining members running, which allows a subset of components to be
+// drained for maintenance. Returns the number of members triggered.
+func (g *Group) TriggerSoftStopNamed(names ...string) int {
+	return g.triggerSubset(g.named(names), false)
+}
+
+// TriggerHardStopNamed signals to the members of the group with any of the
+// provided names that they should terminate right now, leaving the remaining
+// members running. Returns the number of members triggered.
+func (g *Group) TriggerHardStopNamed(names ...string) int {
+	return g.triggerSubset(g.named(names), true)
+}
+
+// WaitNamed blocks until every member of the group with any of the provided
+// names has stopped, regardless of the remaining members, or the context is
+// cancelled, in which case a *PendingError naming the members of the subset
+// that have not stopped and wrapping the error of the context is returned.
+func (g *Group) WaitNamed(ctx context.Context, names ...string) error {
+	return waitSubset(ctx, g.named(names))
+}
+
+// named returns the members of the group with any of the provided names,
+// sorted by name.
+func (g *Group) named(names []string) []*Signaller {
+	want := make(map[string]struct{}, len(names))
+	for _, n := range names {
+		want[n] = struct{}{}
+	}
+	return g.subset(func(m *groupMember) bool {
+		_, ok := want[m.s.Name()]
+		return ok
+	})
+}
+
+// subset returns the members of the group that match, sorted by name.
+func (g *Group) subset(match func(m *groupMember) bool) []*Signaller {
+	var members []*Signaller
+	g.each(func(m *groupMember) {
+		if match(m) {
+			members = append(members, m.s)
+		}
+	})
+	sortByName(members)
+	return members
+}
+
+// each calls fn for each member of the group, with the shard of the member
+// locked.
+func (g *Group) each(fn func(m *groupMember)) {
+	for i := range g.shards {
+		shard := &g.shards[i]
+		shard.mut.Lock()
+		for _, m := range shard.members {
+			fn(m)
+		}
+		shard.mut.Unlock()
+	}
+}
+
+// triggerSubset triggers a subset of the members of the group without
+// triggering the group itself, and returns the size of the subset.
+func (g *Group) triggerSubset(members []*Signaller, hard bool) int {
+	for _, s := range members {
+		if hard {
+			s.triggerHardStop(groupCause)
+		} else {
+			s.triggerSoftStop(groupCause)
+		}
+	}
+	return len(members)
+}
+
+// waitSubset blocks until each of the members has stopped, or returns a
+// *PendingError once the context is cancelled.
+func waitSubset(ctx context.Context, members []*Signaller) error {
+	for _, s := range members {
+		select {
+		case <-s.HasStoppedChan():
+		case <-ctx.Done():
+			return &PendingError{
+				Pending:  pendingNames(members),
+				Draining: drainingSince(members),
+				Err:      ctx.Err(),
+			}
+		}
+	}
+	return nil
+}
+
+// drainingSince returns the longest that any of the members that have not
+// stopped has been draining, or zero if none has a recorded signal time.
+func drainingSince(members []*Signaller) time.Duration {
+	return longestDraining(pendingDraining(members, 0))
+}
@@ -0,0 +1,58 @@
+package shutdown
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGroupPartialStop(t *testing.T) {
+	g := NewGroup()
+	ingestA := NewSignaller(WithName("ingest"))
+	ingestB := NewSignaller(WithName("ingest"))
+	api := NewSignaller(WithName("api"))
+	for _, s := range []*Signaller{ingestA, ingestB, api} {
+		g.Add(s)
+	}
+
+	assert.Equal(t, 2, g.TriggerSoftStopNamed("ingest"))
+	assertClosed(t, ingestA.SoftStopChan())
+	assertClosed(t, ingestB.SoftStopChan())
+	assertOpen(t, api.SoftStopChan())
+
+	ingestA.TriggerHasStopped()
+	ctx, done := context.WithTimeout(context.Background(), time.Millisecond)
+	defer done()
+	err := g.WaitNamed(ctx, "ingest")
+	var pending *PendingError
+	require.ErrorAs(t, err, &pending)
+	assert.Equal(t, []string{"ingest"}, pending.Pending)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	ingestB.TriggerHasStopped()
+	require.NoError(t, g.WaitNamed(waitCtx(t), "ingest"))
+
+	// The group as a whole is unaffected by the partial stop.
+	assertOpen(t, g.HasStoppedChan())
+	g.TriggerSoftStop()
+	assertClosed(t, api.SoftStopChan())
+	api.TriggerHasStopped()
+	require.NoError(t, g.Wait(waitCtx(t)))
+}
+
+func TestGroupPartialHardStop(t *testing.T) {
+	g := NewGroup()
+	a, b := NewSignaller(WithName("a")), NewSignaller(WithName("b"))
+	g.Add(a)
+	g.Add(b)
+
+	assert.Equal(t, 1, g.TriggerHardStopNamed("b", "missing"))
+	assertClosed(t, b.HardStopChan())
+	assertOpen(t, a.SoftStopChan())
+
+	assert.Equal(t, 0, g.TriggerHardStopNamed())
+	require.NoError(t, g.WaitNamed(waitCtx(t), "missing"))
+}
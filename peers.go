@@ -0,0 +1,83 @@
+package shutdown
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// PeerStopRequest describes a request for a peer to stop, as sent by the
+// leader of a cluster through a PeerNotifier.
+type PeerStopRequest struct {
+	// The identity of the instance that made the request.
+	From string
+
+	// Whether the peers should hard stop rather than soft stop.
+	Hard bool
+}
+
+// PeerNotifier sends stop requests to the peers of an instance, over a
+// transport of the choosing of the application, where they are accepted with
+// Peers.HandleStopRequest.
+type PeerNotifier interface {
+	NotifyPeers(ctx context.Context, req PeerStopRequest) error
+}
+
+// Peers coordinates the shutdown of a cluster of instances, where the
+// shutdown of a designated leader is propagated to its peers, which is the
+// basis of a cluster wide drain driven by a single node.
+type Peers struct {
+	s        *Signaller
+	self     string
+	notifier PeerNotifier
+	isLeader func() bool
+
+	remote atomic.Bool
+}
+
+// NewPeers creates a coordinator of peers for the instance identified by self.
+// The isLeader function reports whether the instance is the leader of the
+// cluster at the time of the call, and a nil function designates every
+// instance as a leader.
+func NewPeers(s *Signaller, self string, notifier PeerNotifier, isLeader func() bool) *Peers {
+	if isLeader == nil {
+		isLeader = func() bool { return true }
+	}
+	return &Peers{s: s, self: self, notifier: notifier, isLeader: isLeader}
+}
+
+// HandleStopRequest accepts a stop request from a peer, triggering a soft or
+// hard stop of the signaller accordingly. A stop made by a request is not
+// propagated further, even if the instance has since become the leader, which
+// prevents requests from echoing around the cluster.
+func (p *Peers) HandleStopRequest(req PeerStopRequest) {
+	p.remote.Store(true)
+	if req.Hard {
+		p.s.TriggerHardStopFrom(SourceAdminAPI)
+	} else {
+		p.s.TriggerSoftStopFrom(SourceAdminAPI)
+	}
+}
+
+// Run blocks until a soft stop is signalled and, if the instance is the leader
+// and the stop was not requested by a peer, notifies the peers of the stop
+// with a context that is cancelled once a hard stop is signalled. Peers are
+// asked to hard stop when a hard stop has already been signalled by the time
+// they are notified. The error of the notifier is recorded against the
+// signaller and returned.
+//
+// The signaller is not triggered as having stopped, as it is the signaller of
+// the instance as a whole, and so it remains for the owner of the signaller to
+// do so once the components of the instance have drained.
+func (p *Peers) Run() error {
+	<-p.s.SoftStopChan()
+	if p.remote.Load() || !p.isLeader() {
+		return nil
+	}
+
+	ctx, done := p.s.HardStopCtx(context.Background())
+	defer done()
+
+	err := p.notifier.NotifyPeers(ctx, PeerStopRequest{From: p.self, Hard: ctx.Err() != nil})
+	p.s.RecordStopErr(err)
+	return err
+}
@@ -0,0 +1,81 @@
+package shutdown
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// loopbackNotifier delivers stop requests directly to in-process peers.
+type loopbackNotifier struct {
+	mut   sync.Mutex
+	peers []*Peers
+	sent  []PeerStopRequest
+	err   error
+}
+
+func (n *loopbackNotifier) NotifyPeers(ctx context.Context, req PeerStopRequest) error {
+	n.mut.Lock()
+	n.sent = append(n.sent, req)
+	peers := n.peers
+	n.mut.Unlock()
+
+	for _, p := range peers {
+		p.HandleStopRequest(req)
+	}
+	return n.err
+}
+
+func TestPeersLeaderDrivesCluster(t *testing.T) {
+	leaderSig, followerSig := NewSignaller(), NewSignaller()
+
+	toFollower := &loopbackNotifier{}
+	toLeader := &loopbackNotifier{}
+
+	leader := NewPeers(leaderSig, "a", toFollower, func() bool { return true })
+	follower := NewPeers(followerSig, "b", toLeader, func() bool { return false })
+	toFollower.peers = []*Peers{follower}
+	toLeader.peers = []*Peers{leader}
+
+	errC := make(chan error, 2)
+	go func() { errC <- leader.Run() }()
+	go func() { errC <- follower.Run() }()
+
+	leaderSig.TriggerSoftStop()
+	require.NoError(t, <-errC)
+	require.NoError(t, <-errC)
+
+	assert.True(t, followerSig.IsSoftStopSignalled())
+	assert.False(t, followerSig.IsHardStopSignalled())
+	assert.Equal(t, []PeerStopRequest{{From: "a"}}, toFollower.sent)
+	assert.Empty(t, toLeader.sent)
+	assertOpen(t, leaderSig.HasStoppedChan())
+	assertOpen(t, followerSig.HasStoppedChan())
+}
+
+func TestPeersRemoteStopNotPropagated(t *testing.T) {
+	s := NewSignaller()
+	n := &loopbackNotifier{}
+	p := NewPeers(s, "a", n, nil)
+
+	p.HandleStopRequest(PeerStopRequest{From: "b", Hard: true})
+	require.NoError(t, p.Run())
+	assert.True(t, s.IsHardStopSignalled())
+	assert.Empty(t, n.sent)
+}
+
+func TestPeersHardStop(t *testing.T) {
+	s := NewSignaller()
+	errNotify := errors.New("peer unreachable")
+	n := &loopbackNotifier{err: errNotify}
+	p := NewPeers(s, "a", n, nil)
+
+	s.TriggerHardStop()
+	assert.ErrorIs(t, p.Run(), errNotify)
+	assert.Equal(t, []PeerStopRequest{{From: "a", Hard: true}}, n.sent)
+	assert.ErrorIs(t, s.StopErr(), errNotify)
+}
@@ -0,0 +1,111 @@
+package shutdown
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// PendingError is returned when waiting for a set of components to stop is
+// abandoned, and names the components that had not yet stopped.
+type PendingError struct {
+	// The names of the components that had not stopped, sorted, where
+	// unnamed components are listed as "(unnamed)".
+	Pending []string
+
+	// The longest that any of the components had been draining since it was
+	// signalled to stop, which is zero if none were signalled.
+	Draining time.Duration
+
+	// The reason the wait was abandoned.
+	Err error
+}
+
+// Error returns a description of the components that had not stopped.
+func (e *PendingError) Error() string {
+	if e.Draining > 0 {
+		return fmt.Sprintf("%v after draining for %v, pending: %v", e.Err, e.Draining.Round(time.Millisecond), strings.Join(e.Pending, ", "))
+	}
+	return fmt.Sprintf("%v, pending: %v", e.Err, strings.Join(e.Pending, ", "))
+}
+
+// Unwrap returns the reason the wait was abandoned.
+func (e *PendingError) Unwrap() error {
+	return e.Err
+}
+
+// pendingNames returns the sorted names of the signallers that have not
+// stopped.
+func pendingNames(members []*Signaller) []string {
+	var names []string
+	for _, m := range members {
+		if m.IsHasStoppedSignalled() {
+			continue
+		}
+		names = append(names, pendingName(m))
+	}
+	sort.Strings(names)
+	return names
+}
+
+// pendingName returns the name under which a member is listed as pending.
+func pendingName(m *Signaller) string {
+	if name := m.Name(); name != "" {
+		return name
+	}
+	return "(unnamed)"
+}
+
+// memberDraining returns how long a member has been draining since it was
+// first signalled to soft or hard stop, according to its own clock, or false
+// if no time was recorded, see SignalledAt.
+func memberDraining(m *Signaller) (time.Duration, bool) {
+	at, ok := m.SignalledAt(TierSoftStop)
+	if hardAt, hardOK := m.SignalledAt(TierHardStop); hardOK && (!ok || hardAt.Before(at)) {
+		at, ok = hardAt, true
+	}
+	if !ok {
+		return 0, false
+	}
+	return m.clock().Now().Sub(at), true
+}
+
+// pendingDraining returns how long each member that has not stopped has been
+// draining, keyed by the names of pendingNames, where members that share a
+// name report the longest of their durations. Members without a recorded
+// signal time report the fallback when it is positive, and are otherwise
+// omitted.
+func pendingDraining(members []*Signaller, fallback time.Duration) map[string]time.Duration {
+	var draining map[string]time.Duration
+	for _, m := range members {
+		if m.IsHasStoppedSignalled() {
+			continue
+		}
+		d, ok := memberDraining(m)
+		if !ok {
+			if fallback <= 0 {
+				continue
+			}
+			d = fallback
+		}
+		if draining == nil {
+			draining = map[string]time.Duration{}
+		}
+		name := pendingName(m)
+		if d > draining[name] {
+			draining[name] = d
+		}
+	}
+	return draining
+}
+
+// longestDraining returns the longest of a set of draining durations.
+func longestDraining(draining map[string]time.Duration) (longest time.Duration) {
+	for _, d := range draining {
+		if d > longest {
+			longest = d
+		}
+	}
+	return
+}
@@ -0,0 +1,165 @@
+package shutdown
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// Duration is a time.Duration that is encoded within policy files as a string
+// parsed with time.ParseDuration, such as "20s" or "1m30s".
+type Duration time.Duration
+
+// UnmarshalText parses a duration string.
+func (d *Duration) UnmarshalText(text []byte) error {
+	v, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+// MarshalText formats the duration as a string.
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+// Policy describes the shutdown behaviour of a program and its components, and
+// is intended to be loaded from a file with LoadPolicy so that it can be
+// reviewed and tuned as configuration rather than as constants scattered
+// throughout the code. For example:
+//
+//	{
+//	  "grace_period": "20s",
+//	  "hard_timeout": "5s",
+//	  "drain_delay": "3s",
+//	  "signals": {"SIGTERM": "soft_stop", "SIGINT": "hard_stop"},
+//	  "components": {
+//	    "http_server": {"grace_period": "10s"},
+//	    "kafka_consumer": {"grace_period": "45s", "hard_timeout": "15s"}
+//	  }
+//	}
+//
+// Policies written as YAML can be loaded with the shutdownyaml module, which is
+// versioned separately so that this module does not depend on a YAML parser.
+type Policy struct {
+	// The hard stop grace period of each signaller, see WithHardStopGrace.
+	GracePeriod Duration `json:"grace_period" yaml:"grace_period"`
+
+	// The close timeout of each signaller, see WithCloseTimeout.
+	HardTimeout Duration `json:"hard_timeout" yaml:"hard_timeout"`
+
+	// The delay of soft stops triggered by OS signals, see WithDrainDelay.
+	// Only applies to the program signaller.
+	DrainDelay Duration `json:"drain_delay" yaml:"drain_delay"`
+
+	// OS signals that the program signaller listens for, mapped to an action
+	// which is one of "soft_stop", "hard_stop" or "escalate", where escalate
+	// is the behaviour of WithSignals.
+	Signals map[string]string `json:"signals" yaml:"signals"`
+
+	// Overrides of the policy for individual components, keyed by the name of
+	// their signallers.
+	Components map[string]ComponentPolicy `json:"components" yaml:"components"`
+}
+
+// ComponentPolicy overrides the Policy of a program for the signaller of an
+// individual component. Zero durations inherit the value of the Policy.
+type ComponentPolicy struct {
+	GracePeriod Duration `json:"grace_period" yaml:"grace_period"`
+	HardTimeout Duration `json:"hard_timeout" yaml:"hard_timeout"`
+}
+
+// LoadPolicy reads a Policy from a JSON file. The policy is validated before
+// it is returned.
+func LoadPolicy(path string) (*Policy, error) {
+	return LoadPolicyWith(path, json.Unmarshal)
+}
+
+// LoadPolicyWith reads a Policy from a file parsed with the provided unmarshal
+// function, such as json.Unmarshal, which allows policies to be written in
+// other formats. The policy is validated before it is returned.
+func LoadPolicyWith(path string, unmarshal func(data []byte, v any) error) (*Policy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &Policy{}
+	if err := unmarshal(data, p); err != nil {
+		return nil, fmt.Errorf("parsing policy %v: %w", path, err)
+	}
+	if err := p.Validate(); err != nil {
+		return nil, fmt.Errorf("policy %v: %w", path, err)
+	}
+	return p, nil
+}
+
+// Validate returns an error if the policy contains unrecognised signals or
+// actions.
+func (p *Policy) Validate() error {
+	_, err := p.signalOptions()
+	return err
+}
+
+func (p *Policy) signalOptions() ([]Option, error) {
+	var opts []Option
+	for name, action := range p.Signals {
+		sig, err := ParseSignal(name)
+		if err != nil {
+			return nil, err
+		}
+		switch action {
+		case "soft_stop":
+			opts = append(opts, WithSignalTier(TierSoftStop, sig))
+		case "hard_stop":
+			opts = append(opts, WithSignalTier(TierHardStop, sig))
+		case "escalate", "":
+			opts = append(opts, WithSignals(sig))
+		default:
+			return nil, fmt.Errorf("signal %v: unrecognised action: %q", name, action)
+		}
+	}
+	return opts, nil
+}
+
+// ProgramOptions returns the options of the program signaller, which is the
+// signaller that listens for OS signals and typically owns a Group of the
+// component signallers.
+func (p *Policy) ProgramOptions() ([]Option, error) {
+	opts, err := p.signalOptions()
+	if err != nil {
+		return nil, err
+	}
+	if p.DrainDelay > 0 {
+		opts = append(opts, WithDrainDelay(time.Duration(p.DrainDelay)))
+	}
+	return append(opts, p.durationOptions(p.GracePeriod, p.HardTimeout)...), nil
+}
+
+// ComponentOptions returns the options of the signaller of a named component,
+// which includes the name itself.
+func (p *Policy) ComponentOptions(name string) []Option {
+	grace, timeout := p.GracePeriod, p.HardTimeout
+	if c, ok := p.Components[name]; ok {
+		if c.GracePeriod > 0 {
+			grace = c.GracePeriod
+		}
+		if c.HardTimeout > 0 {
+			timeout = c.HardTimeout
+		}
+	}
+	return append([]Option{WithName(name)}, p.durationOptions(grace, timeout)...)
+}
+
+func (p *Policy) durationOptions(grace, timeout Duration) (opts []Option) {
+	if grace > 0 {
+		opts = append(opts, WithHardStopGrace(time.Duration(grace)))
+	}
+	if timeout > 0 {
+		opts = append(opts, WithCloseTimeout(time.Duration(timeout)))
+	}
+	return
+}
@@ -0,0 +1,102 @@
+package shutdown
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writePolicy(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	return path
+}
+
+// testPolicySignals returns the signals of a policy fixture along with the
+// tiers they are expected to map to, which are built from the signals of the
+// platform as only SIGINT is recognised on all of them, and the signals of some
+// share a value.
+func testPolicySignals(t *testing.T) (string, map[os.Signal]Tier) {
+	t.Helper()
+	signals := map[string]string{"SIGINT": "hard_stop"}
+	tiers := map[os.Signal]Tier{signalNames["INT"]: TierHardStop}
+	if sig, ok := signalNames["TERM"]; ok && sig != signalNames["INT"] {
+		signals["SIGTERM"] = "soft_stop"
+		tiers[sig] = TierSoftStop
+	}
+	b, err := json.Marshal(signals)
+	require.NoError(t, err)
+	return string(b), tiers
+}
+
+func TestLoadPolicy(t *testing.T) {
+	signals, tiers := testPolicySignals(t)
+	p, err := LoadPolicy(writePolicy(t, "policy.json", `{
+  "grace_period": "20s",
+  "hard_timeout": "5s",
+  "drain_delay": "3s",
+  "signals": `+signals+`,
+  "components": {"kafka_consumer": {"grace_period": "45s"}}
+}`))
+	require.NoError(t, err)
+
+	assert.Equal(t, Duration(time.Second*20), p.GracePeriod)
+	assert.Equal(t, Duration(time.Second*3), p.DrainDelay)
+
+	opts, err := p.ProgramOptions()
+	require.NoError(t, err)
+
+	// Program options are inspected without constructing a listening
+	// signaller.
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	assert.Equal(t, time.Second*20, o.hardStopGrace)
+	assert.Equal(t, time.Second*5, o.closeTimeout)
+	assert.Equal(t, time.Second*3, o.drainDelay)
+	assert.Equal(t, tiers, o.signalTiers)
+
+	cfg := NewSignaller(p.ComponentOptions("kafka_consumer")...).Config()
+	assert.Equal(t, "kafka_consumer", cfg.Name)
+	assert.Equal(t, time.Second*45, cfg.HardStopGrace)
+	assert.Equal(t, time.Second*5, cfg.CloseTimeout)
+
+	cfg = NewSignaller(p.ComponentOptions("http_server")...).Config()
+	assert.Equal(t, "http_server", cfg.Name)
+	assert.Equal(t, time.Second*20, cfg.HardStopGrace)
+}
+
+func TestLoadPolicyWith(t *testing.T) {
+	var unmarshalled []byte
+	p, err := LoadPolicyWith(writePolicy(t, "policy.conf", "grace=10s"), func(data []byte, v any) error {
+		unmarshalled = data
+		return json.Unmarshal([]byte(`{
+  "grace_period": "10s",
+  "components": {"foo": {"hard_timeout": "1m"}}
+}`), v)
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "grace=10s", string(unmarshalled))
+
+	cfg := NewSignaller(p.ComponentOptions("foo")...).Config()
+	assert.Equal(t, time.Second*10, cfg.HardStopGrace)
+	assert.Equal(t, time.Minute, cfg.CloseTimeout)
+}
+
+func TestLoadPolicyErrors(t *testing.T) {
+	_, err := LoadPolicy(writePolicy(t, "a.json", `{"grace_period": "soon"}`))
+	assert.ErrorContains(t, err, `invalid duration "soon"`)
+
+	_, err = LoadPolicy(writePolicy(t, "b.json", `{"signals": {"SIGNOPE": "soft_stop"}}`))
+	assert.ErrorContains(t, err, `unrecognised signal: "SIGNOPE"`)
+
+	_, err = LoadPolicy(writePolicy(t, "c.json", `{"signals": {"SIGINT": "explode"}}`))
+	assert.ErrorContains(t, err, `signal SIGINT: unrecognised action: "explode"`)
+}
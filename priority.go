@@ -0,0 +1,75 @@
+package shutdown
+
+import (
+	"context"
+	"sort"
+)
+
+// AddWithPriority adds a signaller to the group as with AddTagged, along with a
+// stop priority. When the group is soft stopped its members are stopped in
+// ascending order of priority, where every member of one priority is triggered
+// concurrently, subject to the strategy of the group, see WithStopStrategy,
+// and must report that it has stopped before the members of the next priority
+// are triggered. Members added with Add have a priority of zero.
+//
+// A hard stop of the group abandons any ordering and is broadcast to every
+// member immediately.
+func (g *Group) AddWithPriority(s *Signaller, priority int, tags ...string) {
+	if priority != 0 {
+		g.prioritised.Store(true)
+	}
+	g.add(s, priority, tags)
+}
+
+// sequenced returns true if soft stops of the group are sequenced rather than
+// broadcast.
+func (g *Group) sequenced() bool {
+	return g.strategy != nil || g.prioritised.Load()
+}
+
+// startSequence begins soft stopping the members of the group by priority
+// from a goroutine, which is cancelled by a hard stop of the group.
+func (g *Group) startSequence() {
+	ctx, cancel := context.WithCancel(context.Background())
+	g.seqCancel.Store(&cancel)
+	if g.triggered.Load()&TierHardStop.bit() != 0 {
+		cancel()
+	}
+	strategy := g.strategy
+	if strategy == nil {
+		strategy = StopBroadcast()
+	}
+	trigger := func(s *Signaller) {
+		s.triggerSoftStop(groupCause)
+	}
+	go func() {
+		defer cancel()
+		for _, level := range g.levels() {
+			if strategy.StopMembers(ctx, level, trigger) != nil {
+				return
+			}
+		}
+	}()
+}
+
+// levels returns the members of the group grouped by ascending priority, with
+// each priority sorted by name.
+func (g *Group) levels() [][]*Signaller {
+	byPriority := map[int][]*Signaller{}
+	g.each(func(m *groupMember) {
+		byPriority[m.priority] = append(byPriority[m.priority], m.s)
+	})
+	priorities := make([]int, 0, len(byPriority))
+	for p := range byPriority {
+		priorities = append(priorities, p)
+	}
+	sort.Ints(priorities)
+
+	levels := make([][]*Signaller, 0, len(priorities))
+	for _, p := range priorities {
+		level := byPriority[p]
+		sortByName(level)
+		levels = append(levels, level)
+	}
+	return levels
+}
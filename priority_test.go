@@ -0,0 +1,53 @@
+package shutdown
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGroupPriority(t *testing.T) {
+	g := NewGroup()
+	http := NewSignaller(WithName("http"))
+	worker := NewSignaller(WithName("worker"))
+	db := NewSignaller(WithName("db"))
+	g.Add(http)
+	g.AddWithPriority(worker, 1)
+	g.AddWithPriority(db, 2, "storage")
+	assert.Equal(t, map[string]TagStatus{"storage": {Members: 1}}, g.Tags())
+
+	g.TriggerSoftStop()
+	assertClosed(t, http.SoftStopChan())
+	assertOpen(t, worker.SoftStopChan())
+
+	http.TriggerHasStopped()
+	assertClosed(t, worker.SoftStopChan())
+	assertOpen(t, db.SoftStopChan())
+
+	worker.TriggerHasStopped()
+	assertClosed(t, db.SoftStopChan())
+
+	db.TriggerHasStopped()
+	require.NoError(t, g.Wait(waitCtx(t)))
+}
+
+func TestGroupPriorityHardStop(t *testing.T) {
+	g := NewGroup()
+	a, b := NewSignaller(WithName("a")), NewSignaller(WithName("b"))
+	g.Add(a)
+	g.AddWithPriority(b, 1)
+
+	g.TriggerSoftStop()
+	assertClosed(t, a.SoftStopChan())
+	assertOpen(t, b.SoftStopChan())
+
+	g.TriggerHardStop()
+	assertClosed(t, a.HardStopChan())
+	assertClosed(t, b.HardStopChan())
+
+	// The sequence is abandoned, and so the stop of a does not matter.
+	a.TriggerHasStopped()
+	b.TriggerHasStopped()
+	require.NoError(t, g.Wait(waitCtx(t)))
+}
@@ -0,0 +1,44 @@
+package shutdown
+
+import (
+	"log/slog"
+)
+
+// AddStopReason records a human readable reason for the signaller stopping,
+// such as "config reload failed" or "disk pressure", and can be called by any
+// number of callers before or during a stop, as real shutdowns often have
+// several contributing factors. Reasons are listed by Reasons, included in the
+// diagnostics and snapshots of the signaller, and logged together with each
+// tier that is signalled. Repeated reasons are ignored.
+func (s *Signaller) AddStopReason(reason string) {
+	if s == nil || reason == "" {
+		return
+	}
+	x := s.extra()
+	s.mut.Lock()
+	for _, r := range x.reasons {
+		if r == reason {
+			s.mut.Unlock()
+			return
+		}
+	}
+	x.reasons = append(x.reasons, reason)
+	s.mut.Unlock()
+
+	x.log(slog.LevelInfo, "shutdown reason added", "reason", reason)
+}
+
+// Reasons returns the reasons added with AddStopReason, in the order they were
+// added.
+func (s *Signaller) Reasons() []string {
+	if s == nil {
+		return nil
+	}
+	x := s.ext.Load()
+	if x == nil {
+		return nil
+	}
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	return append([]string(nil), x.reasons...)
+}
@@ -0,0 +1,46 @@
+package shutdown
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStopReasons(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}))
+	s := NewSignaller(WithLogger(logger))
+
+	s.AddStopReason("config reload failed")
+	s.AddStopReason("disk pressure")
+	s.AddStopReason("config reload failed")
+	s.AddStopReason("")
+	assert.Equal(t, []string{"config reload failed", "disk pressure"}, s.Reasons())
+
+	buf.Reset()
+	s.TriggerSoftStop()
+	assert.Equal(t, "level=INFO msg=\"shutdown signalled\" tier=\"soft stop\" reasons=\"[config reload failed disk pressure]\"\n", buf.String())
+
+	var report bytes.Buffer
+	_, err := s.Diagnostics().WriteTo(&report)
+	require.NoError(t, err)
+	assert.Contains(t, report.String(), "  reasons:\n    config reload failed\n    disk pressure\n")
+	assert.Equal(t, []string{"config reload failed", "disk pressure"}, s.Snapshot().Reasons)
+}
+
+func TestStopReasonsNil(t *testing.T) {
+	var s *Signaller
+	s.AddStopReason("foo")
+	assert.Nil(t, s.Reasons())
+	assert.Nil(t, NewSignaller().Reasons())
+}
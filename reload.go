@@ -0,0 +1,165 @@
+package shutdown
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"os/signal"
+	"time"
+)
+
+// tierReload identifies reloads to the waiters and hooks of a signaller. A
+// reload is not a stop tier, and so its bit is never set in the state word.
+const tierReload Tier = 3
+
+// reloadBit marks reloads within the masks of Notify, alongside the bits of
+// the tiers.
+const reloadBit = 1 << tierReload
+
+// WithReloadSignals causes the signaller to listen for the provided OS signals,
+// or SIGHUP when none are provided on platforms that have it, where receiving
+// one triggers a reload, see TriggerReload, rather than any stop tier.
+// Listening ends once the signaller has stopped.
+func WithReloadSignals(sigs ...os.Signal) Option {
+	return func(o *options) {
+		if len(sigs) == 0 {
+			sigs = reloadSignals
+		}
+		o.reloadSignals = append(o.reloadSignals, sigs...)
+	}
+}
+
+// listenReload triggers reloads on OS signals until the signaller has stopped.
+func (s *Signaller) listenReload(o *options) {
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, o.reloadSignals...)
+
+	stopped := s.HasStoppedChan()
+	go func() {
+		defer signal.Stop(c)
+		for {
+			select {
+			case sig := <-c:
+				s.triggerReload(cause{source: SourceOSSignal, reason: "signal " + sig.String()})
+			case <-stopped:
+				return
+			}
+		}
+	}()
+}
+
+// TriggerReload signals to the owner of this Signaller that it should reload
+// its configuration. Unlike the stop tiers a reload does not imply any other
+// signal and may be triggered any number of times, and each reload closes the
+// channel returned by ReloadChan, cancels the contexts returned by ReloadCtx,
+// calls the hooks registered with OnReload and is delivered to subscribers as
+// an EventReload. Reloads are ignored once the signaller has stopped.
+func (s *Signaller) TriggerReload() {
+	s.triggerReload(cause{})
+}
+
+// triggerReload is TriggerReload with a cause recorded against the signaller.
+func (s *Signaller) triggerReload(c cause) {
+	if s == nil || s.IsHasStoppedSignalled() {
+		return
+	}
+	x := s.extra()
+
+	s.mut.Lock()
+	x.reloads.Add(1)
+	if x.reloadChan != nil {
+		close(x.reloadChan)
+		x.reloadChan = nil
+	}
+	waiters := s.takeWaiters(tierReload)
+	s.mut.Unlock()
+	notifyWaiters(waiters)
+
+	x.log(slog.LevelInfo, "shutdown reload signalled", "reloads", x.reloads.Load())
+	s.emit(EventReload, c.source)
+	s.recordTransition(EventReload, c)
+	s.fireReloadHooks()
+}
+
+// fireReloadHooks calls each hook registered with OnReload in the order they
+// were added. Unlike the hooks of a tier they remain registered afterwards.
+func (s *Signaller) fireReloadHooks() {
+	var panicked bool
+	for _, h := range s.hooks.list(tierReload) {
+		// Hooks that have been deregistered are marked as called.
+		if h.called.Load() {
+			continue
+		}
+		if s.recordHookPanic(h.run()) {
+			panicked = true
+		}
+	}
+	if panicked && s.config().escalateHookPanics {
+		s.triggerHardStop(cause{reason: "hook panic"})
+	}
+}
+
+// Reloads returns the number of reloads that have been triggered.
+func (s *Signaller) Reloads() int64 {
+	if s == nil {
+		return 0
+	}
+	if x := s.ext.Load(); x != nil {
+		return x.reloads.Load()
+	}
+	return 0
+}
+
+// ReloadChan returns a channel that will be closed by the next reload, and so
+// a fresh channel must be obtained after each reload in order to observe the
+// next one:
+//
+//	for {
+//		select {
+//		case <-s.ReloadChan():
+//			reloadConfig()
+//		case <-s.SoftStopChan():
+//			return
+//		}
+//	}
+func (s *Signaller) ReloadChan() <-chan struct{} {
+	if s == nil {
+		return nil
+	}
+	x := s.extra()
+
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	if x.reloadChan == nil {
+		x.reloadChan = make(chan struct{})
+	}
+	return x.reloadChan
+}
+
+// ReloadCtx returns a context.Context that will be terminated when either the
+// provided context is cancelled or the next reload is triggered.
+func (s *Signaller) ReloadCtx(ctx context.Context) (context.Context, context.CancelFunc) {
+	return s.deriveCtx(ctx, tierReload, time.Time{})
+}
+
+// OnReload registers a function to be called each time a reload is triggered,
+// from the goroutine that triggered it. Calling the returned stop function
+// deregisters the hook, and returns true if it was still registered.
+//
+// A panic within a hook is recovered and recorded as a *HookPanicError, which
+// is reported by StopErr and does not prevent any other hooks from being
+// called.
+func (s *Signaller) OnReload(fn func()) (stop func() bool) {
+	if s == nil {
+		return func() bool { return true }
+	}
+	h := &hook{fn: fn, tier: tierReload, registeredAt: captureCallers(1)}
+	s.hooks.add(tierReload, h)
+	return func() bool {
+		if !h.called.CompareAndSwap(false, true) {
+			return false
+		}
+		s.hooks.remove(tierReload, h)
+		return true
+	}
+}
@@ -0,0 +1,116 @@
+package shutdown
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReload(t *testing.T) {
+	s := NewSignaller()
+	first := s.ReloadChan()
+	ctx, done := s.ReloadCtx(context.Background())
+	defer done()
+
+	var calls int
+	stop := s.OnReload(func() { calls++ })
+
+	assert.Equal(t, int64(0), s.Reloads())
+	assertOpen(t, first)
+
+	s.TriggerReload()
+	assertClosed(t, first)
+	assertClosed(t, ctx.Done())
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, int64(1), s.Reloads())
+
+	// A reload implies no stop tier.
+	assertOpen(t, s.SoftStopChan())
+
+	second := s.ReloadChan()
+	assertOpen(t, second)
+	assert.True(t, stop())
+	assert.False(t, stop())
+
+	s.TriggerReload()
+	assertClosed(t, second)
+	assert.Equal(t, 1, calls)
+
+	s.TriggerHasStopped()
+	s.TriggerReload()
+	assert.Equal(t, int64(2), s.Reloads())
+}
+
+func TestReloadEvents(t *testing.T) {
+	s := NewSignaller(WithHistory(4))
+	events, cancel := s.Subscribe()
+	defer cancel()
+	ch := make(chan Event, 4)
+	s.Notify(ch)
+	tiers := make(chan Event, 4)
+	s.Notify(tiers, TierSoftStop)
+
+	s.TriggerReload()
+	assert.Equal(t, []EventKind{EventReload}, readEvents(t, events))
+	assert.Equal(t, []EventKind{EventReload}, readNotified(ch))
+	assert.Empty(t, readNotified(tiers))
+
+	h := s.History()
+	require.Len(t, h, 1)
+	assert.Equal(t, EventReload, h[0].Kind)
+}
+
+func TestReloadHookPanic(t *testing.T) {
+	var buf bytes.Buffer
+	s := NewSignaller(WithLogger(slog.New(slog.NewTextHandler(&buf, nil))))
+
+	var called bool
+	s.OnReload(func() { panic("nope") })
+	s.OnReload(func() { called = true })
+
+	s.TriggerReload()
+	assert.True(t, called)
+	assert.Contains(t, buf.String(), "shutdown hook panicked")
+
+	var hookErr *HookPanicError
+	require.ErrorAs(t, s.StopErr(), &hookErr)
+	assert.Equal(t, "nope", hookErr.Value)
+	assert.EqualError(t, hookErr, "reload hook panicked: nope")
+	assert.Contains(t, string(hookErr.RegisteredAt), "TestReloadHookPanic")
+}
+
+func TestReloadCtx(t *testing.T) {
+	s := NewSignaller(WithClock(newManualClock()), WithEscalationPolicy(EscalationPolicy{
+		Steps: []EscalationStep{{Name: "hard_stop", After: time.Hour, Action: EscalateHardStop}},
+	}))
+	s.TriggerSoftStop()
+	_, ok := s.HardStopDeadline()
+	require.True(t, ok)
+
+	before := runtime.NumGoroutine()
+	ctx, done := s.ReloadCtx(context.Background())
+	assert.Equal(t, before, runtime.NumGoroutine())
+
+	// The escalation of a soft stop does not apply to reloads.
+	_, ok = ctx.Deadline()
+	assert.False(t, ok)
+	assert.Equal(t, 1, s.OutstandingContexts())
+
+	s.TriggerReload()
+	assertClosed(t, ctx.Done())
+	assert.ErrorIs(t, ctx.Err(), context.Canceled)
+	done()
+	assert.Equal(t, 0, s.OutstandingContexts())
+
+	// Released contexts are not cancelled by later reloads.
+	ctx, done = s.ReloadCtx(context.Background())
+	done()
+	s.TriggerReload()
+	assert.ErrorIs(t, ctx.Err(), context.Canceled)
+}
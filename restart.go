@@ -0,0 +1,161 @@
+package shutdown
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrRestartInProgress is returned by Builder.Restart when the component is
+// already being restarted.
+var ErrRestartInProgress = errors.New("component restart already in progress")
+
+// runningComponent is a component of a program started by Builder.Run, which
+// is given a fresh signaller of its own each time it is restarted.
+type runningComponent struct {
+	c       builderComponent
+	program *Signaller
+
+	// The grace of the component, see Builder.WithComponentGrace, or nil
+	// when it is hard stopped along with the program.
+	grace *time.Duration
+
+	mut        sync.Mutex
+	m          *Signaller
+	restarting chan error
+
+	// Set once the component has returned without being restarted.
+	finished bool
+}
+
+// newMember creates a signaller for the component.
+func (rc *runningComponent) newMember() *Signaller {
+	// Components share the metrics of the program, so that their stop
+	// durations are observed individually.
+	opts := []Option{WithName(rc.c.name)}
+	if m := rc.program.config().metrics; m != nil {
+		opts = append(opts, WithMetrics(m))
+	}
+	if rc.grace != nil && *rc.grace > 0 {
+		opts = append(opts, WithHardStopGrace(*rc.grace))
+	}
+	return NewSignaller(append(opts, rc.c.opts...)...)
+}
+
+// softStop propagates a soft stop of the program to a signaller of the
+// component, which is a hard stop for components with a grace of zero.
+func (rc *runningComponent) softStop(m *Signaller) {
+	if rc.grace != nil && *rc.grace <= 0 {
+		m.TriggerHardStopFrom(SourceParentContext)
+	} else {
+		m.TriggerSoftStopFrom(SourceParentContext)
+	}
+}
+
+// followsHardStop reports whether a hard stop of the program is propagated to
+// the component. Components with a grace of their own escalate themselves,
+// and so they ignore hard stops made by the escalation of the program, but
+// follow those triggered explicitly, such as by TriggerHardStop or a second
+// OS signal.
+func (rc *runningComponent) followsHardStop() bool {
+	if rc.grace == nil {
+		return true
+	}
+	src, _ := rc.program.TriggeredBy(TierHardStop)
+	return src != SourceWatchdog
+}
+
+// current returns the signaller of the current run of the component.
+func (rc *runningComponent) current() *Signaller {
+	rc.mut.Lock()
+	defer rc.mut.Unlock()
+	return rc.m
+}
+
+// run runs the component until it returns without having been restarted,
+// returning its error.
+func (rc *runningComponent) run() error {
+	for {
+		m := rc.current()
+		err := func() error {
+			defer m.TriggerHasStopped()
+			return rc.c.run(m)
+		}()
+
+		rc.mut.Lock()
+		restarted := rc.restarting
+		rc.restarting = nil
+		if restarted == nil || rc.program.IsSoftStopSignalled() {
+			rc.finished = true
+			rc.mut.Unlock()
+			if restarted != nil {
+				restarted <- fmt.Errorf("restart abandoned: %w", ErrSoftStop)
+			}
+			return err
+		}
+		next := rc.newMember()
+		rc.m = next
+		rc.mut.Unlock()
+
+		// The program may have begun stopping while the new signaller was
+		// created, in which case it must not miss the stop.
+		if rc.program.IsHardStopSignalled() && rc.followsHardStop() {
+			next.TriggerHardStopFrom(SourceParentContext)
+		} else if rc.program.IsSoftStopSignalled() {
+			rc.softStop(next)
+		}
+		restarted <- err
+	}
+}
+
+func errNotRunning(name string) error {
+	return fmt.Errorf("component %q is not running", name)
+}
+
+// Restart stops the named component of a running program and runs it again
+// with a fresh signaller, while the rest of the program keeps running, which
+// suits reloading the configuration of individual subsystems. The component is
+// soft stopped, and Restart blocks until its previous run has returned, with
+// the error it returned, which does not cause the program to stop. If the
+// context is cancelled first then the component is hard stopped and the error
+// of the context is returned, although the restart still takes place once the
+// previous run returns.
+//
+// Returns ErrSoftStop if the program is stopping, and an error if the
+// component is unknown, has already returned, or Run is not in progress.
+func (b *Builder) Restart(ctx context.Context, name string) error {
+	b.mut.Lock()
+	rc := b.running[name]
+	b.mut.Unlock()
+	if rc == nil {
+		return errNotRunning(name)
+	}
+	if rc.program.IsSoftStopSignalled() {
+		return ErrSoftStop
+	}
+
+	done := make(chan error, 1)
+	rc.mut.Lock()
+	if rc.finished {
+		rc.mut.Unlock()
+		return errNotRunning(name)
+	}
+	if rc.restarting != nil {
+		rc.mut.Unlock()
+		return ErrRestartInProgress
+	}
+	rc.restarting = done
+	m := rc.m
+	rc.mut.Unlock()
+
+	m.triggerSoftStop(cause{reason: "restart"})
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		m.triggerHardStop(cause{reason: "restart"})
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,139 @@
+package shutdown
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuilderRestart(t *testing.T) {
+	errFlush := errors.New("flush failed")
+
+	var starts atomic.Int32
+	started := make(chan *Signaller, 2)
+	other := make(chan *Signaller, 1)
+
+	b := New().
+		WithComponent("cache", func(s *Signaller) error {
+			started <- s
+			<-s.SoftStopChan()
+			if starts.Add(1) == 1 {
+				return errFlush
+			}
+			return nil
+		}).
+		WithComponent("api", func(s *Signaller) error {
+			other <- s
+			<-s.SoftStopChan()
+			return nil
+		})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errC := make(chan error, 1)
+	go func() {
+		errC <- b.Run(ctx)
+	}()
+
+	first, api := <-started, <-other
+	assert.ErrorIs(t, b.Restart(waitCtx(t), "cache"), errFlush)
+	assert.True(t, first.IsHasStoppedSignalled())
+
+	second := <-started
+	assert.NotSame(t, first, second)
+	assert.False(t, second.IsSoftStopSignalled())
+	assert.False(t, api.IsSoftStopSignalled())
+
+	cancel()
+	require.NoError(t, <-errC)
+	assert.True(t, second.IsHasStoppedSignalled())
+	assert.EqualError(t, b.Restart(waitCtx(t), "cache"), `component "cache" is not running`)
+}
+
+func TestBuilderRestartUnknown(t *testing.T) {
+	ready := make(chan struct{})
+	b := New().WithComponent("worker", func(s *Signaller) error {
+		close(ready)
+		<-s.SoftStopChan()
+		return nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errC := make(chan error, 1)
+	go func() {
+		errC <- b.Run(ctx)
+	}()
+	<-ready
+
+	assert.EqualError(t, b.Restart(waitCtx(t), "missing"), `component "missing" is not running`)
+
+	cancel()
+	require.NoError(t, <-errC)
+}
+
+func TestBuilderRestartCancelled(t *testing.T) {
+	release := make(chan struct{})
+	started := make(chan *Signaller, 2)
+	b := New().WithComponent("stuck", func(s *Signaller) error {
+		started <- s
+		<-s.HardStopChan()
+		<-release
+		return nil
+	})
+
+	errC := make(chan error, 1)
+	go func() {
+		errC <- b.Run(context.Background())
+	}()
+	first := <-started
+
+	ctx, done := context.WithCancel(context.Background())
+	done()
+	assert.ErrorIs(t, b.Restart(ctx, "stuck"), context.Canceled)
+	assert.True(t, first.IsHardStopSignalled())
+	assert.ErrorIs(t, b.Restart(waitCtx(t), "stuck"), ErrRestartInProgress)
+
+	close(release)
+	second := <-started
+	second.TriggerHardStop()
+	require.NoError(t, <-errC)
+}
+
+func TestBuilderRestartReturned(t *testing.T) {
+	returned := make(chan struct{})
+	b := New().
+		WithComponent("oneshot", func(s *Signaller) error {
+			defer close(returned)
+			return nil
+		}).
+		WithComponent("api", func(s *Signaller) error {
+			<-s.SoftStopChan()
+			return nil
+		})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errC := make(chan error, 1)
+	go func() {
+		errC <- b.Run(ctx)
+	}()
+	<-returned
+
+	// The run of the component has returned by itself, and so there is
+	// nothing to restart.
+	b.mut.Lock()
+	rc := b.running["oneshot"]
+	b.mut.Unlock()
+	assert.Eventually(t, func() bool {
+		rc.mut.Lock()
+		defer rc.mut.Unlock()
+		return rc.finished
+	}, time.Second, time.Millisecond)
+	assert.EqualError(t, b.Restart(waitCtx(t), "oneshot"), `component "oneshot" is not running`)
+
+	cancel()
+	require.NoError(t, <-errC)
+}
@@ -0,0 +1,57 @@
+package shutdown
+
+import (
+	"context"
+)
+
+// StopRolling returns a strategy for pools of identical members, such as shard
+// handlers or partition consumers, that never has more than maxUnavailable of
+// them draining at once, so that the pool keeps serving capacity throughout a
+// controlled drain. Unlike StopBoundedParallel, members that were already
+// draining when the strategy began, having been soft stopped by other means,
+// count towards the limit. A limit of less than one is treated as one.
+func StopRolling(maxUnavailable int) StopStrategy {
+	return rollingStrategy(max(maxUnavailable, 1))
+}
+
+type rollingStrategy int
+
+func (n rollingStrategy) StopMembers(ctx context.Context, members []*Signaller, trigger func(s *Signaller)) error {
+	// Members that are already draining are observed first so that they
+	// occupy slots before any others are triggered.
+	var draining, serving []*Signaller
+	for _, s := range members {
+		switch {
+		case s.IsHasStoppedSignalled():
+		case s.IsSoftStopSignalled():
+			draining = append(draining, s)
+		default:
+			serving = append(serving, s)
+		}
+	}
+	return boundedStrategy(n).StopMembers(ctx, append(draining, serving...), func(s *Signaller) {
+		if !s.IsSoftStopSignalled() {
+			trigger(s)
+		}
+	})
+}
+
+// RollTagged soft stops the members of the group with the tag, see AddTagged,
+// with no more than maxUnavailable of them draining at once, see StopRolling,
+// and blocks until each has stopped. The remaining members of the group are
+// left running. If the context is cancelled first then a *PendingError naming
+// the members with the tag that have not stopped is returned.
+func (g *Group) RollTagged(ctx context.Context, tag string, maxUnavailable int) error {
+	members := g.tagged(tag)
+	trigger := func(s *Signaller) {
+		s.triggerSoftStop(groupCause)
+	}
+	if err := StopRolling(maxUnavailable).StopMembers(ctx, members, trigger); err != nil {
+		return &PendingError{
+			Pending:  pendingNames(members),
+			Draining: drainingSince(members),
+			Err:      err,
+		}
+	}
+	return nil
+}
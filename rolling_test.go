@@ -0,0 +1,73 @@
+package shutdown
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGroupStopRolling(t *testing.T) {
+	g := NewGroup(WithStopStrategy(StopRolling(2)))
+	a, b, c := NewSignaller(WithName("a")), NewSignaller(WithName("b")), NewSignaller(WithName("c"))
+	g.Add(a)
+	g.Add(b)
+	g.Add(c)
+
+	// c is already draining, and so only one more member may drain.
+	c.TriggerSoftStop()
+	g.TriggerSoftStop()
+	assertClosed(t, a.SoftStopChan())
+	time.Sleep(time.Millisecond * 10)
+	assertOpen(t, b.SoftStopChan())
+
+	c.TriggerHasStopped()
+	assertClosed(t, b.SoftStopChan())
+
+	a.TriggerHasStopped()
+	b.TriggerHasStopped()
+	require.NoError(t, g.Wait(waitCtx(t)))
+}
+
+func TestGroupRollTagged(t *testing.T) {
+	g := NewGroup()
+	var shards []*Signaller
+	for _, name := range []string{"shard-0", "shard-1", "shard-2"} {
+		s := NewSignaller(WithName(name))
+		g.AddTagged(s, "shards")
+		shards = append(shards, s)
+	}
+	api := NewSignaller(WithName("api"))
+	g.Add(api)
+
+	errs := make(chan error, 1)
+	go func() {
+		errs <- g.RollTagged(waitCtx(t), "shards", 1)
+	}()
+
+	for i, s := range shards {
+		assertClosed(t, s.SoftStopChan())
+		for _, later := range shards[i+1:] {
+			assertOpen(t, later.SoftStopChan())
+		}
+		s.TriggerHasStopped()
+	}
+	require.NoError(t, <-errs)
+	assertOpen(t, api.SoftStopChan())
+}
+
+func TestGroupRollTaggedCancelled(t *testing.T) {
+	g := NewGroup()
+	g.AddTagged(NewSignaller(WithName("a")), "pool")
+	g.AddTagged(NewSignaller(WithName("b")), "pool")
+
+	ctx, done := context.WithTimeout(context.Background(), time.Millisecond)
+	defer done()
+
+	var pending *PendingError
+	require.ErrorAs(t, g.RollTagged(ctx, "pool", 1), &pending)
+	assert.Equal(t, []string{"a", "b"}, pending.Pending)
+	assert.ErrorIs(t, pending, context.DeadlineExceeded)
+}
@@ -0,0 +1,145 @@
+package shutdown
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// runStderr is where Run reports the error of the program.
+var runStderr io.Writer = os.Stderr
+
+// Run is a wrapper for the main function of a program, which runs fn with a
+// context that is cancelled once a soft stop is signalled and returns an exit
+// code suitable for os.Exit:
+//
+//	func main() {
+//		os.Exit(shutdown.Run(func(ctx context.Context, s *shutdown.Signaller) error {
+//			return serve(ctx, s)
+//		}, shutdown.WithHardStopGrace(20*time.Second)))
+//	}
+//
+// The signaller listens for SIGINT and SIGTERM, where the first signal triggers
+// a soft stop and any further signal a hard stop, unless the options configure
+// other signals with WithSignals, which then replace them. Escalation from the
+// soft stop to the hard stop is configured with options such as
+// WithHardStopGrace or WithEscalationPolicy.
+//
+// The program is considered to have stopped once fn returns, at which point the
+// signaller is triggered as having stopped. If fn does not return within the
+// close timeout after a hard stop, see WithCloseTimeout, then Run gives up
+// waiting. The error of fn, any errors recorded against the signaller and any
+// timeout are written to stderr, and the exit code is chosen from the outcome
+// by the ExitCodes configured with WithExitCodes, which are DefaultExitCodes
+// by default, where the code is 0 when there are no errors and 1 otherwise.
+func Run(fn func(ctx context.Context, s *Signaller) error, opts ...Option) int {
+	err := RunContext(context.Background(), fn, opts...)
+
+	var exitErr *ExitError
+	if errors.As(err, &exitErr) && exitErr.Err != nil {
+		fmt.Fprintf(runStderr, "error: %v\n", exitErr.Err)
+	}
+	return ExitCode(err)
+}
+
+// ExitError is returned by RunContext when the exit code of a program is not
+// zero, and carries the errors of the program, if any.
+type ExitError struct {
+	Code int
+	Err  error
+}
+
+// Error returns a description of the exit.
+func (e *ExitError) Error() string {
+	if e.Err == nil {
+		return fmt.Sprintf("exit status %v", e.Code)
+	}
+	return e.Err.Error()
+}
+
+// Unwrap returns the errors of the program.
+func (e *ExitError) Unwrap() error {
+	return e.Err
+}
+
+// ExitCode returns the exit code of an error returned by RunContext, which is
+// zero for a nil error and one for errors that do not carry an exit code.
+func ExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	var exitErr *ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.Code
+	}
+	return 1
+}
+
+// RunContext behaves as Run, except that a soft stop is also triggered when the
+// provided context is cancelled, and nothing is written to stderr. The context
+// provided to fn carries the signaller, see SignallerFromContext. Returns an
+// *ExitError when the exit code of the program is not zero, from which the
+// code can be obtained with ExitCode, which suits command line frameworks that
+// propagate errors from their commands to main.
+func RunContext(ctx context.Context, fn func(ctx context.Context, s *Signaller) error, opts ...Option) error {
+	s := NewSignaller(append(opts[:len(opts):len(opts)], withDefaultSignals(defaultSignals...))...)
+
+	stopCtx := context.AfterFunc(ctx, func() {
+		s.TriggerSoftStopFrom(SourceParentContext)
+	})
+	defer stopCtx()
+
+	ctx, done := s.SoftStopCtx(ContextWithSignaller(ctx, s))
+	defer done()
+
+	o := runUntilStopped(ctx, s, fn)
+
+	codes := DefaultExitCodes
+	if c := s.config().exitCodes; c != nil {
+		codes = *c
+	}
+	if code := codes.Code(o); code != 0 || o.Err != nil {
+		return &ExitError{Code: code, Err: o.Err}
+	}
+	return nil
+}
+
+// runUntilStopped runs fn and waits for it to return, or for the close timeout
+// to elapse after a hard stop, returning the outcome of the program.
+func runUntilStopped(ctx context.Context, s *Signaller, fn func(ctx context.Context, s *Signaller) error) StopOutcome {
+	errC := make(chan error, 1)
+	go func() {
+		defer s.triggerHasStopped(cause{}, true)
+		errC <- fn(ctx, s)
+	}()
+
+	var err error
+	select {
+	case err = <-errC:
+	case <-s.HardStopChan():
+		var timeout <-chan time.Time
+		if d := s.config().closeTimeout; d > 0 {
+			t := time.NewTimer(d)
+			defer t.Stop()
+			timeout = t.C
+		}
+		select {
+		case err = <-errC:
+		case <-timeout:
+			return StopOutcome{
+				Signal:   s.ReceivedSignal(),
+				HardStop: true,
+				TimedOut: true,
+				Err:      errors.Join(fmt.Errorf("program: %w", ErrStopTimeout), s.StopErr()),
+			}
+		}
+	}
+	return StopOutcome{
+		Signal:   s.ReceivedSignal(),
+		HardStop: s.IsHardStopSignalled(),
+		Err:      errors.Join(err, s.StopErr()),
+	}
+}
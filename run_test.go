@@ -0,0 +1,105 @@
+package shutdown
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func captureRunStderr(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	prev := runStderr
+	runStderr = &buf
+	t.Cleanup(func() {
+		runStderr = prev
+	})
+	return &buf
+}
+
+func TestRunClean(t *testing.T) {
+	stderr := captureRunStderr(t)
+
+	code := Run(func(ctx context.Context, s *Signaller) error {
+		go s.TriggerSoftStop()
+		<-ctx.Done()
+		return nil
+	})
+	assert.Equal(t, 0, code)
+	assert.Empty(t, stderr.String())
+}
+
+func TestRunError(t *testing.T) {
+	stderr := captureRunStderr(t)
+
+	var sig *Signaller
+	code := Run(func(ctx context.Context, s *Signaller) error {
+		sig = s
+		s.RecordStopErr(errors.New("flush failed"))
+		return errors.New("listener failed")
+	})
+	assert.Equal(t, 1, code)
+	assert.Equal(t, "error: listener failed\nflush failed\n", stderr.String())
+	assert.True(t, sig.IsHasStoppedSignalled())
+}
+
+func TestRunTimeout(t *testing.T) {
+	stderr := captureRunStderr(t)
+
+	release := make(chan struct{})
+	defer close(release)
+
+	code := Run(func(ctx context.Context, s *Signaller) error {
+		s.TriggerHardStop()
+		<-release
+		return nil
+	}, WithCloseTimeout(time.Millisecond*10))
+	assert.Equal(t, 1, code)
+	assert.Equal(t, "error: program: timed out waiting to stop\n", stderr.String())
+}
+
+func TestRunExitCodes(t *testing.T) {
+	captureRunStderr(t)
+
+	code := Run(func(ctx context.Context, s *Signaller) error {
+		s.TriggerHardStop()
+		return nil
+	}, WithExitCodes(ExitCodes{HardStopped: 3, Failed: 4}))
+	assert.Equal(t, 3, code)
+}
+
+func TestRunContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	err := RunContext(ctx, func(ctx context.Context, s *Signaller) error {
+		assert.Equal(t, s, SignallerFromContext(ctx))
+		cancel()
+		<-s.SoftStopChan()
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 0, ExitCode(err))
+}
+
+func TestRunContextExitError(t *testing.T) {
+	errFailed := errors.New("failed")
+	err := RunContext(context.Background(), func(ctx context.Context, s *Signaller) error {
+		return errFailed
+	}, WithExitCodes(ExitCodes{Failed: 7}))
+	assert.ErrorIs(t, err, errFailed)
+	assert.Equal(t, 7, ExitCode(err))
+
+	err = RunContext(context.Background(), func(ctx context.Context, s *Signaller) error {
+		s.TriggerHardStop()
+		return nil
+	}, WithExitCodes(ExitCodes{HardStopped: 3}))
+	assert.EqualError(t, err, "exit status 3")
+	assert.Equal(t, 3, ExitCode(err))
+
+	assert.Equal(t, 1, ExitCode(errFailed))
+}
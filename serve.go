@@ -0,0 +1,103 @@
+package shutdown
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"time"
+)
+
+// Serve runs an accept loop on the provided listener for the lifetime of a
+// signaller, calling handle from a goroutine of its own for each accepted
+// connection. The connection is closed once handle returns.
+//
+// Once a soft stop is signalled the listener is closed and no further
+// connections are accepted, while live connections are left to finish. Once a
+// hard stop is signalled the contexts provided to handle are cancelled and the
+// remaining connections are closed. The signaller is triggered as having
+// stopped once the last connection has closed.
+//
+// The error of the listener is returned if it fails for any reason other than
+// being closed by the soft stop, in which case a soft stop is also triggered
+// so that the owner of the signaller observes the failure.
+func Serve(s *Signaller, l net.Listener, handle func(ctx context.Context, conn net.Conn)) error {
+	defer s.TriggerHasStopped()
+
+	ctx, done := s.HardStopCtx(context.Background())
+	defer done()
+
+	var (
+		wg    sync.WaitGroup
+		mut   sync.Mutex
+		conns = map[net.Conn]struct{}{}
+	)
+	stopHard := s.OnHardStop(func() {
+		mut.Lock()
+		for conn := range conns {
+			_ = conn.Close()
+		}
+		mut.Unlock()
+	})
+	defer stopHard()
+
+	stopSoft := s.OnSoftStop(func() {
+		_ = l.Close()
+	})
+	defer stopSoft()
+
+	err := acceptLoop(s, l, func(conn net.Conn) {
+		mut.Lock()
+		conns[conn] = struct{}{}
+		mut.Unlock()
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() {
+				mut.Lock()
+				delete(conns, conn)
+				mut.Unlock()
+				_ = conn.Close()
+			}()
+			handle(ctx, conn)
+		}()
+	})
+	s.TriggerSoftStop()
+
+	wg.Wait()
+	return err
+}
+
+// acceptLoop accepts connections until the listener fails, retrying errors
+// that are timeouts after a short delay. Returns nil if the listener failed
+// because a soft stop was signalled.
+func acceptLoop(s *Signaller, l net.Listener, accepted func(conn net.Conn)) error {
+	var delay time.Duration
+	for {
+		conn, err := l.Accept()
+		if err == nil {
+			delay = 0
+			accepted(conn)
+			continue
+		}
+		if s.IsSoftStopSignalled() {
+			return nil
+		}
+
+		var ne net.Error
+		if !errors.As(err, &ne) || !ne.Timeout() {
+			return err
+		}
+		if delay == 0 {
+			delay = 5 * time.Millisecond
+		} else if delay *= 2; delay > time.Second {
+			delay = time.Second
+		}
+		select {
+		case <-time.After(delay):
+		case <-s.SoftStopChan():
+			return nil
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package shutdown
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func runServe(t *testing.T, s *Signaller, l net.Listener, handle func(ctx context.Context, conn net.Conn)) <-chan error {
+	t.Helper()
+	errC := make(chan error, 1)
+	go func() {
+		errC <- Serve(s, l, handle)
+	}()
+	return errC
+}
+
+func TestServeGraceful(t *testing.T) {
+	s := NewSignaller()
+	ln := listenLocal(t)
+
+	errC := runServe(t, s, ln, func(ctx context.Context, conn net.Conn) {
+		_, _ = io.Copy(conn, conn)
+	})
+
+	conn, err := net.Dial("tcp", ln.Addr().String())
+	require.NoError(t, err)
+
+	_, err = conn.Write([]byte("hello"))
+	require.NoError(t, err)
+	buf := make([]byte, 5)
+	_, err = io.ReadFull(conn, buf)
+	require.NoError(t, err)
+	assert.Equal(t, "hello", string(buf))
+
+	s.TriggerSoftStop()
+
+	// New connections are refused while the live one remains open.
+	assert.Eventually(t, func() bool {
+		c, err := net.Dial("tcp", ln.Addr().String())
+		if err == nil {
+			c.Close()
+		}
+		return err != nil
+	}, time.Second, time.Millisecond)
+	assertOpen(t, s.HasStoppedChan())
+
+	_, err = conn.Write([]byte("again"))
+	require.NoError(t, err)
+	_, err = io.ReadFull(conn, buf)
+	require.NoError(t, err)
+	assert.Equal(t, "again", string(buf))
+
+	require.NoError(t, conn.Close())
+	select {
+	case err := <-errC:
+		require.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for serve to return")
+	}
+	assertClosed(t, s.HasStoppedChan())
+}
+
+func TestServeHardStop(t *testing.T) {
+	s := NewSignaller()
+	ln := listenLocal(t)
+
+	handled := make(chan error, 1)
+	errC := runServe(t, s, ln, func(ctx context.Context, conn net.Conn) {
+		<-ctx.Done()
+		_, err := conn.Read(make([]byte, 1))
+		handled <- err
+	})
+
+	conn, err := net.Dial("tcp", ln.Addr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	s.TriggerHardStop()
+	select {
+	case err := <-errC:
+		require.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for serve to return")
+	}
+	assert.Error(t, <-handled)
+	assertClosed(t, s.HasStoppedChan())
+}
+
+type failingListener struct {
+	net.Listener
+	err error
+}
+
+func (l failingListener) Accept() (net.Conn, error) {
+	return nil, l.err
+}
+
+func TestServeListenerError(t *testing.T) {
+	s := NewSignaller()
+	ln := listenLocal(t)
+	defer ln.Close()
+
+	errListen := errors.New("listener broke")
+	err := Serve(s, failingListener{Listener: ln, err: errListen}, func(ctx context.Context, conn net.Conn) {})
+	assert.ErrorIs(t, err, errListen)
+	assert.True(t, s.IsSoftStopSignalled())
+	assertClosed(t, s.HasStoppedChan())
+}
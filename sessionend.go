@@ -0,0 +1,38 @@
+package shutdown
+
+import "time"
+
+// SessionEndDeadline is the time that Windows allows a console process to
+// clean up once the console is closed, the user logs off or the system shuts
+// down, after which it is terminated.
+const SessionEndDeadline = 5 * time.Second
+
+// WithSessionEnd causes the signaller to treat the end of the session of the
+// process as a hard stop. On Windows the Go runtime delivers the console close,
+// logoff and shutdown events as SIGTERM and then keeps the process alive until
+// it exits or Windows terminates it, which happens SessionEndDeadline after the
+// event. Mapping those events to a hard stop, rather than the soft stop that
+// SIGTERM triggers with WithSignals, gives components the best chance of
+// running their cleanup within the deadline, and pairs well with a close
+// timeout of the same duration:
+//
+//	s := shutdown.NewSignaller(
+//		shutdown.WithSignals(os.Interrupt),
+//		shutdown.WithSessionEnd(),
+//		shutdown.WithCloseTimeout(shutdown.SessionEndDeadline),
+//	)
+//
+// Terminating a job object with TerminateJobObject, as well as killing a
+// process with TerminateProcess, ends the process immediately without
+// delivering any event, and so cannot be detected.
+//
+// On other platforms this option has no effect, as the end of a session is
+// delivered as SIGHUP or SIGTERM, which are already handled by WithSignals and
+// WithSignalTier.
+func WithSessionEnd() Option {
+	return func(o *options) {
+		if len(sessionEndSignals) > 0 {
+			WithSignalTier(TierHardStop, sessionEndSignals...)(o)
+		}
+	}
+}
@@ -0,0 +1,23 @@
+package shutdown
+
+import (
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithSessionEnd(t *testing.T) {
+	s := NewSignaller(WithSessionEnd())
+	defer s.TriggerHasStopped()
+
+	tiers := s.Config().SignalTiers
+	if runtime.GOOS != "windows" {
+		assert.Empty(t, tiers)
+		return
+	}
+	assert.Len(t, tiers, 1)
+	for _, tier := range tiers {
+		assert.Equal(t, TierHardStop, tier)
+	}
+}
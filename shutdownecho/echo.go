@@ -0,0 +1,34 @@
+// Package shutdownecho provides echo middleware that admits requests through a
+// shutdown.Gate, which tracks the requests in flight and rejects those that
+// arrive after a soft stop.
+//
+// It is a package of its own so that importing github.com/Jeffail/shutdown
+// does not compile echo into programs that do not use it.
+package shutdownecho
+
+import (
+	"net/http"
+
+	"github.com/Jeffail/shutdown"
+	"github.com/labstack/echo/v4"
+)
+
+// Middleware returns echo middleware equivalent to shutdown.HTTPMiddleware,
+// which admits each request through the gate and rejects requests that arrive
+// after a soft stop with a 503 Service Unavailable response and a
+// Connection: close header. The signaller of the gate is carried by the
+// context of each admitted request, see shutdown.SignallerFromContext.
+func Middleware(gate *shutdown.Gate) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if err := gate.Enter(); err != nil {
+				c.Response().Header().Set(echo.HeaderConnection, "close")
+				return c.String(http.StatusServiceUnavailable, "server shutting down")
+			}
+			defer gate.Exit()
+			r := c.Request()
+			c.SetRequest(r.WithContext(shutdown.ContextWithSignaller(r.Context(), gate.Signaller())))
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package shutdownecho
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Jeffail/shutdown"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMiddleware(t *testing.T) {
+	s := shutdown.NewSignaller()
+	gate := shutdown.NewGate(s)
+
+	var inFlight int
+	e := echo.New()
+	e.Use(Middleware(gate))
+	e.GET("/", func(c echo.Context) error {
+		inFlight = gate.InFlight()
+		assert.Equal(t, s, shutdown.SignallerFromContext(c.Request().Context()))
+		return c.NoContent(http.StatusTeapot)
+	})
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, http.StatusTeapot, rec.Code)
+	assert.Equal(t, 1, inFlight)
+	assert.Equal(t, 0, gate.InFlight())
+
+	s.TriggerSoftStop()
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.Equal(t, "close", rec.Header().Get("Connection"))
+}
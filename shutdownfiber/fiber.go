@@ -0,0 +1,29 @@
+// Package shutdownfiber provides fiber middleware that admits requests through
+// a shutdown.Gate, which tracks the requests in flight and rejects those that
+// arrive after a soft stop.
+//
+// It is a package of its own so that importing github.com/Jeffail/shutdown
+// does not compile fiber into programs that do not use it.
+package shutdownfiber
+
+import (
+	"github.com/Jeffail/shutdown"
+	"github.com/gofiber/fiber/v2"
+)
+
+// Middleware returns fiber middleware equivalent to shutdown.HTTPMiddleware,
+// which admits each request through the gate and rejects requests that arrive
+// after a soft stop with a 503 Service Unavailable response and a
+// Connection: close header. The signaller of the gate is carried by the user
+// context of each admitted request, see shutdown.SignallerFromContext.
+func Middleware(gate *shutdown.Gate) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if err := gate.Enter(); err != nil {
+			c.Context().SetConnectionClose()
+			return c.Status(fiber.StatusServiceUnavailable).SendString("server shutting down")
+		}
+		defer gate.Exit()
+		c.SetUserContext(shutdown.ContextWithSignaller(c.UserContext(), gate.Signaller()))
+		return c.Next()
+	}
+}
@@ -0,0 +1,38 @@
+package shutdownfiber
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Jeffail/shutdown"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMiddleware(t *testing.T) {
+	s := shutdown.NewSignaller()
+	gate := shutdown.NewGate(s)
+
+	var inFlight int
+	app := fiber.New()
+	app.Use(Middleware(gate))
+	app.Get("/", func(c *fiber.Ctx) error {
+		inFlight = gate.InFlight()
+		assert.Equal(t, s, shutdown.SignallerFromContext(c.UserContext()))
+		return c.SendStatus(http.StatusTeapot)
+	})
+
+	res, err := app.Test(httptest.NewRequest(http.MethodGet, "/", nil))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusTeapot, res.StatusCode)
+	assert.Equal(t, 1, inFlight)
+	assert.Equal(t, 0, gate.InFlight())
+
+	s.TriggerSoftStop()
+	res, err = app.Test(httptest.NewRequest(http.MethodGet, "/", nil))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusServiceUnavailable, res.StatusCode)
+	assert.True(t, res.Close)
+}
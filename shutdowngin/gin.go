@@ -0,0 +1,33 @@
+// Package shutdowngin provides gin middleware that admits requests through a
+// shutdown.Gate, which tracks the requests in flight and rejects those that
+// arrive after a soft stop.
+//
+// It is a package of its own so that importing github.com/Jeffail/shutdown
+// does not compile gin into programs that do not use it.
+package shutdowngin
+
+import (
+	"net/http"
+
+	"github.com/Jeffail/shutdown"
+	"github.com/gin-gonic/gin"
+)
+
+// Middleware returns gin middleware equivalent to shutdown.HTTPMiddleware,
+// which admits each request through the gate and rejects requests that arrive
+// after a soft stop with a 503 Service Unavailable response and a
+// Connection: close header. The signaller of the gate is carried by the
+// context of each admitted request, see shutdown.SignallerFromContext.
+func Middleware(gate *shutdown.Gate) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := gate.Enter(); err != nil {
+			c.Header("Connection", "close")
+			c.String(http.StatusServiceUnavailable, "server shutting down")
+			c.Abort()
+			return
+		}
+		defer gate.Exit()
+		c.Request = c.Request.WithContext(shutdown.ContextWithSignaller(c.Request.Context(), gate.Signaller()))
+		c.Next()
+	}
+}
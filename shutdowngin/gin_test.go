@@ -0,0 +1,39 @@
+package shutdowngin
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Jeffail/shutdown"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	s := shutdown.NewSignaller()
+	gate := shutdown.NewGate(s)
+
+	var inFlight int
+	r := gin.New()
+	r.Use(Middleware(gate))
+	r.GET("/", func(c *gin.Context) {
+		inFlight = gate.InFlight()
+		assert.Equal(t, s, shutdown.SignallerFromContext(c.Request.Context()))
+		c.Status(http.StatusTeapot)
+	})
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, http.StatusTeapot, rec.Code)
+	assert.Equal(t, 1, inFlight)
+	assert.Equal(t, 0, gate.InFlight())
+
+	s.TriggerSoftStop()
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.Equal(t, "close", rec.Header().Get("Connection"))
+}
@@ -0,0 +1,30 @@
+// Package shutdownyaml loads shutdown policies written as YAML.
+//
+// It is a package of its own so that importing github.com/Jeffail/shutdown
+// does not compile a YAML parser into programs that do not use it.
+package shutdownyaml
+
+import (
+	"github.com/Jeffail/shutdown"
+	"gopkg.in/yaml.v3"
+)
+
+// LoadPolicy reads a shutdown.Policy from a YAML file, for example:
+//
+//	grace_period: 20s
+//	hard_timeout: 5s
+//	drain_delay: 3s
+//	signals:
+//	  SIGTERM: soft_stop
+//	  SIGINT: hard_stop
+//	components:
+//	  http_server:
+//	    grace_period: 10s
+//	  kafka_consumer:
+//	    grace_period: 45s
+//	    hard_timeout: 15s
+//
+// The policy is validated before it is returned.
+func LoadPolicy(path string) (*shutdown.Policy, error) {
+	return shutdown.LoadPolicyWith(path, yaml.Unmarshal)
+}
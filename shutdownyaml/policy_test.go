@@ -0,0 +1,50 @@
+package shutdownyaml
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Jeffail/shutdown"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writePolicy(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "policy.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	return path
+}
+
+func TestLoadPolicy(t *testing.T) {
+	p, err := LoadPolicy(writePolicy(t, `
+grace_period: 20s
+hard_timeout: 5s
+drain_delay: 3s
+signals:
+  SIGINT: soft_stop
+components:
+  kafka_consumer:
+    grace_period: 45s
+`))
+	require.NoError(t, err)
+
+	assert.Equal(t, shutdown.Duration(time.Second*20), p.GracePeriod)
+	assert.Equal(t, shutdown.Duration(time.Second*5), p.HardTimeout)
+	assert.Equal(t, shutdown.Duration(time.Second*3), p.DrainDelay)
+	assert.Equal(t, map[string]string{"SIGINT": "soft_stop"}, p.Signals)
+
+	cfg := shutdown.NewSignaller(p.ComponentOptions("kafka_consumer")...).Config()
+	assert.Equal(t, time.Second*45, cfg.HardStopGrace)
+	assert.Equal(t, time.Second*5, cfg.CloseTimeout)
+}
+
+func TestLoadPolicyErrors(t *testing.T) {
+	_, err := LoadPolicy(writePolicy(t, `grace_period: soon`))
+	assert.ErrorContains(t, err, `invalid duration "soon"`)
+
+	_, err = LoadPolicy(writePolicy(t, "signals:\n  SIGINT: explode"))
+	assert.ErrorContains(t, err, `signal SIGINT: unrecognised action: "explode"`)
+}
@@ -2,7 +2,61 @@ package shutdown
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
 	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Tier identifies one of the three signals of a Signaller.
+type Tier int
+
+// The signals of a Signaller.
+const (
+	TierSoftStop Tier = iota
+	TierHardStop
+	TierHasStopped
+)
+
+// String returns a human readable name of the tier.
+func (t Tier) String() string {
+	switch t {
+	case TierSoftStop:
+		return "soft stop"
+	case TierHardStop:
+		return "hard stop"
+	case TierHasStopped:
+		return "has stopped"
+	case tierReload:
+		return "reload"
+	}
+	return "unknown"
+}
+
+// bit returns the bit of the Signaller state word that is set once the signal
+// has been made.
+func (t Tier) bit() uint32 {
+	return 1 << uint32(t)
+}
+
+// Sentinel errors describing stop conditions, which allow callers to tell work
+// that was rejected because a component is stopping apart from real failures
+// with errors.Is.
+var (
+	// ErrSoftStop is returned, or wrapped, by helpers that reject new work
+	// once a soft stop has been signalled, such as ErrGateClosed.
+	ErrSoftStop = errors.New("soft stop signalled")
+
+	// ErrHardStop is returned, or wrapped, by helpers that abandon work once a
+	// hard stop has been signalled.
+	ErrHardStop = errors.New("hard stop signalled")
+
+	// ErrStopTimeout is wrapped by the errors of helpers that gave up waiting
+	// for a component to stop, such as Close.
+	ErrStopTimeout = errors.New("timed out waiting to stop")
 )
 
 // Signaller is a mechanism owned by components that support graceful
@@ -20,50 +74,539 @@ import (
 // Finally, there is also a signal of having stopped, which is made by the
 // component and can be used from outside to determine whether the component
 // has finished terminating.
+//
+// A nil *Signaller is valid and behaves as a signaller that is never
+// signalled: its channels never close, its contexts are only cancelled by
+// their parents, its hooks are never called and its Trigger methods do
+// nothing. This allows libraries to accept an optional signaller without
+// checking for nil.
 type Signaller struct {
-	softStopChan chan struct{}
-	softStopOnce sync.Once
+	// The state word is the source of truth for which tiers have been
+	// signalled, and the mutex guards transitions of it along with the
+	// waiters. Channels are allocated lazily on first access, as many owners
+	// never observe every tier.
+	state atomic.Uint32
+	mut   sync.Mutex
+
+	// The number of contexts derived with the *Ctx and Acquire*Ctx methods
+	// that have not been released, which occupies what would otherwise be
+	// padding after the mutex.
+	ctxs atomic.Int32
+
+	// The channel of each tier, which is replaced with closedChan once the
+	// tier is signalled, so that channels are obtained without the mutex.
+	chans   [3]atomic.Pointer[chan struct{}]
+	waiters *waiter
+
+	hooks hookList
+
+	// Configuration and infrequently used state is allocated lazily.
+	ext atomic.Pointer[extra]
+}
+
+// extra holds the configuration and infrequently used state of a Signaller.
+type extra struct {
+	// Immutable after construction.
+	options
+
+	// Guarded by the mutex of the Signaller.
+	stopErrs  []error
+	stopAttrs []slog.Attr
+	reasons   []string
+
+	subs subscribers
+
+	// The first OS signal received by the signal listener.
+	received atomic.Pointer[os.Signal]
 
-	hardStopChan chan struct{}
-	hardStopOnce sync.Once
+	// Set once a soft stop has been requested with RequestSoftStop.
+	softRequested atomic.Bool
 
-	hasStoppedChan chan struct{}
-	hasStoppedOnce sync.Once
+	// Guards the abdication functions, which are called at most once.
+	abdicated sync.Once
+
+	// Sections added with AddDiagnostics, guarded by the mutex of the
+	// Signaller.
+	diagnostics []*diagnosticsSection
+
+	// Set when the signaller is renamed with SetName.
+	renamed atomic.Pointer[string]
+
+	// Unix nanoseconds of the soft stop that armed the escalation policy and
+	// of a scheduled hard stop, and the timers of pending steps, which are
+	// guarded by the mutex of the Signaller.
+	softAt      atomic.Int64
+	escalateAt  atomic.Int64
+	escalations []pendingStep
+
+	// The total duration by which escalation steps have been postponed with
+	// RequestExtension during the current soft stop, and a record of each
+	// request, which is guarded by the mutex of the Signaller.
+	extended   atomic.Int64
+	extensions []Extension
+
+	// The timer of the watchdog configured with WithWatchdog, guarded by the
+	// mutex of the Signaller.
+	watchdog Timer
+
+	// The ring of transitions recorded with WithHistory, and the total number
+	// recorded, guarded by the mutex of the Signaller.
+	history      []Transition
+	historyCount int
+
+	// The stacks of the goroutines that triggered each tier, recorded with
+	// WithTriggerStacks and guarded by the mutex of the Signaller.
+	stacks [3][]byte
+
+	// The creation sites of outstanding contexts, keyed by context, recorded
+	// with WithContextSites and guarded by the mutex of the Signaller.
+	ctxSiteMap map[any]string
+
+	// The number of goroutines started with Go that are running, the number
+	// of barriers that are waiting for confirmations, and whether
+	// TriggerHasStopped has been deferred until both reach zero.
+	goroutines   atomic.Int64
+	barriers     atomic.Int64
+	stopDeferred atomic.Bool
+
+	// Functions called by TriggerHasStopped before the tier is signalled, such
+	// as those of a Cleanup, which are called serially under their mutex so
+	// that concurrent triggers wait for them.
+	stoppingMut sync.Mutex
+	stopping    []func()
+
+	// The sentinel of WithLeakDetection, which is marked once the signaller
+	// has stopped.
+	leak *leakSentinel
+
+	// Unix nanoseconds at which each tier was last signalled.
+	signalledAt [3]atomic.Int64
+
+	// The TriggerSource of each tier that has been signalled.
+	sources [3]atomic.Int32
+
+	// The number of reloads, and the channel that is closed by the next
+	// reload, guarded by the mutex of the Signaller.
+	reloads    atomic.Int64
+	reloadChan chan struct{}
+
+	// The channels bound to tiers with BindTrigger.
+	binder binder
+
+	// Goroutines currently calling hooks, guarded by the mutex of the
+	// Signaller, and a count of them that can be checked without it.
+	firing      []*hookFiring
+	firingCount atomic.Int32
+}
+
+// defaultOptions is the configuration of signallers constructed without
+// options.
+var defaultOptions = options{
+	closeTimeout: time.Second * 30,
 }
 
 // NewSignaller creates a new signaller.
-func NewSignaller() *Signaller {
-	return &Signaller{
-		softStopChan:   make(chan struct{}),
-		hardStopChan:   make(chan struct{}),
-		hasStoppedChan: make(chan struct{}),
+func NewSignaller(opts ...Option) *Signaller {
+	s := &Signaller{}
+	if len(opts) > 0 {
+		x := &extra{options: defaultOptions}
+		for _, o := range opts {
+			o(&x.options)
+		}
+		x.applyLabels()
+		s.ext.Store(x)
+		if x.onLeak != nil {
+			setLeakFinalizer(x, x.onLeak)
+		}
+		if len(x.signals) > 0 || len(x.signalTiers) > 0 {
+			s.listenSignals(&x.options)
+		}
+		if len(x.diagnosticsSignals) > 0 {
+			s.listenDiagnostics(&x.options)
+		}
+		if len(x.reloadSignals) > 0 {
+			s.listenReload(&x.options)
+		}
+	}
+	return s
+}
+
+// extra returns the extra state of the signaller, allocating it if necessary.
+func (s *Signaller) extra() *extra {
+	if x := s.ext.Load(); x != nil {
+		return x
+	}
+	s.ext.CompareAndSwap(nil, &extra{options: defaultOptions})
+	return s.ext.Load()
+}
+
+// config returns the configuration of the signaller.
+func (s *Signaller) config() *options {
+	if s == nil {
+		return &defaultOptions
+	}
+	if x := s.ext.Load(); x != nil {
+		return &x.options
+	}
+	return &defaultOptions
+}
+
+// Name returns the name of the signaller, as assigned with WithName or SetName,
+// or an empty string if it has not been named.
+func (s *Signaller) Name() string {
+	if s == nil {
+		return ""
 	}
+	if x := s.ext.Load(); x != nil {
+		return x.currentName()
+	}
+	return ""
+}
+
+// SetName assigns a name to the signaller, replacing any name given with
+// WithName, which identifies it within logs, metrics and errors. This is useful
+// for components that are constructed with a signaller before they know their
+// own identity, such as one signaller per connection.
+func (s *Signaller) SetName(name string) {
+	if s == nil {
+		return
+	}
+	s.extra().renamed.Store(&name)
+}
+
+func (x *extra) currentName() string {
+	if n := x.renamed.Load(); n != nil {
+		return *n
+	}
+	return x.name
+}
+
+// clock returns the clock of the signaller.
+func (s *Signaller) clock() Clock {
+	if c := s.config().clock; c != nil {
+		return c
+	}
+	return systemClock{}
 }
 
 // TriggerSoftStop signals to the owner of this Signaller that it should
 // terminate at its own leisure, meaning it's okay to complete any tasks that
 // are in progress but no new work should be started.
 func (s *Signaller) TriggerSoftStop() {
-	s.softStopOnce.Do(func() {
-		close(s.softStopChan)
-	})
+	s.triggerSoftStop(cause{})
+}
+
+// triggerSoftStop is TriggerSoftStop with a cause recorded against the
+// signaller.
+func (s *Signaller) triggerSoftStop(c cause) {
+	if s == nil {
+		return
+	}
+	s.checkReentrant(TierSoftStop)
+	s.trigger(TierSoftStop, c)
 }
 
 // TriggerHardStop signals to the owner of this Signaller that it should
-// terminate right now regardless of any in progress tasks.
+// terminate right now regardless of any in progress tasks. This also signals a
+// soft stop unless the signaller was constructed with WithIndependentTiers.
 func (s *Signaller) TriggerHardStop() {
-	s.TriggerSoftStop()
-	s.hardStopOnce.Do(func() {
-		close(s.hardStopChan)
-	})
+	s.triggerHardStop(cause{})
+}
+
+// triggerHardStop is TriggerHardStop with a cause recorded against the
+// signaller.
+func (s *Signaller) triggerHardStop(c cause) {
+	if s == nil {
+		return
+	}
+	s.checkReentrant(TierHardStop)
+	if !s.config().independentTiers {
+		s.trigger(TierSoftStop, cause{source: c.source, reason: "hard stop"})
+	}
+	s.trigger(TierHardStop, c)
 }
 
 // TriggerHasStopped is a signal made by the component that it and all of its
-// owned resources have terminated.
+// owned resources have terminated. When constructed with WithGoroutineGate the
+// signal is deferred until the goroutines started with Go have returned, and
+// it is likewise deferred while a Barrier of the signaller is waiting for
+// confirmations.
 func (s *Signaller) TriggerHasStopped() {
-	s.hasStoppedOnce.Do(func() {
-		close(s.hasStoppedChan)
-	})
+	s.triggerHasStopped(cause{}, false)
+}
+
+// triggerHasStopped is TriggerHasStopped with a cause recorded against the
+// signaller. When completed is true the signaller is being triggered by this
+// package on completion of the work that it watches, such as the function of
+// Run returning or the members of a Group having stopped, which may happen
+// without a prior stop and from within hooks, and so neither strict ordering
+// nor re-entrancy is checked.
+func (s *Signaller) triggerHasStopped(c cause, completed bool) {
+	if s == nil {
+		return
+	}
+	if s.deferStop() {
+		return
+	}
+	if !completed {
+		if cfg := s.config(); cfg.strictOrdering != nil && s.state.Load()&(TierSoftStop.bit()|TierHardStop.bit()) == 0 {
+			cfg.strictOrdering(ErrStoppedBeforeSignal)
+		}
+		s.checkReentrant(TierHasStopped)
+	}
+	s.callStopping()
+	s.trigger(TierHasStopped, c)
+}
+
+// callStopping calls the functions registered with onStopping, unless the
+// signaller has already stopped.
+func (s *Signaller) callStopping() {
+	x := s.ext.Load()
+	if x == nil {
+		return
+	}
+	x.stoppingMut.Lock()
+	defer x.stoppingMut.Unlock()
+	if s.state.Load()&TierHasStopped.bit() != 0 {
+		return
+	}
+	for _, fn := range x.stopping {
+		fn()
+	}
+}
+
+// onStopping registers a function to be called by TriggerHasStopped before the
+// has stopped tier is signalled. The function may be called more than once if
+// TriggerHasStopped is called concurrently.
+func (s *Signaller) onStopping(fn func()) {
+	x := s.extra()
+	x.stoppingMut.Lock()
+	x.stopping = append(x.stopping, fn)
+	x.stoppingMut.Unlock()
+}
+
+// tierChan returns the channel that is closed once the tier is signalled,
+// allocating it if necessary. This takes no lock, where racing allocations
+// are resolved by whichever is swapped in first.
+func (s *Signaller) tierChan(t Tier) <-chan struct{} {
+	if s == nil {
+		// Receiving from a nil channel blocks forever.
+		return nil
+	}
+	if s.state.Load()&t.bit() != 0 {
+		return closedChan
+	}
+	if c := s.chans[t].Load(); c != nil {
+		return *c
+	}
+	c := make(chan struct{})
+	if s.chans[t].CompareAndSwap(nil, &c) {
+		// Once swapped in the channel is closed by the signal, which replaces
+		// it with closedChan.
+		return c
+	}
+	return *s.chans[t].Load()
+}
+
+// trigger signals a tier, if it has not already been signalled, and then
+// calls any hooks registered against it.
+func (s *Signaller) trigger(t Tier, c cause) {
+	if s.state.Load()&t.bit() != 0 {
+		return
+	}
+	if t != TierHasStopped {
+		s.abdicate()
+	}
+	if s.signal(t) {
+		s.recordTriggerStack(t)
+		s.recordSignalledAt(t)
+		s.recordSource(t, c.source)
+		switch t {
+		case TierSoftStop:
+			s.armEscalation()
+			s.armWatchdog()
+		case TierHardStop:
+			s.disarmEscalation(EscalateHardStop)
+			s.armWatchdog()
+		default:
+			s.disarmEscalation(EscalateExit)
+			s.disarmWatchdog()
+			if x := s.ext.Load(); x != nil && x.leak != nil {
+				x.leak.stopped.Store(true)
+			}
+		}
+		s.emit(tierEventKind(t), c.source)
+		s.recordTransition(tierEventKind(t), c)
+		s.observeSignal(t)
+		s.fireHooks(t)
+	}
+}
+
+// AbortSoftStop returns a signaller that has been signalled to soft stop, but
+// not to hard stop and has not yet stopped, to a running state. Subscribers are
+// notified with an EventSoftStopAborted event. Returns false if the signaller
+// was not constructed with WithReversibleSoftStop, or if the soft stop can no
+// longer be aborted.
+//
+// Channels that have already been closed cannot be reopened, and so any
+// channel previously obtained from SoftStopChan remains closed, as do any
+// contexts derived from the soft stop. Subsequent calls to SoftStopChan return
+// a new open channel, and hooks registered after the abort are called on the
+// next soft stop.
+func (s *Signaller) AbortSoftStop() bool {
+	if s == nil {
+		return false
+	}
+	if !s.config().reversibleSoftStop {
+		return false
+	}
+
+	s.mut.Lock()
+	old := s.state.Load()
+	if old&TierSoftStop.bit() == 0 || old&(TierHardStop.bit()|TierHasStopped.bit()) != 0 {
+		s.mut.Unlock()
+		return false
+	}
+	s.chans[TierSoftStop].Store(nil)
+	s.state.Store(old &^ TierSoftStop.bit())
+	s.mut.Unlock()
+
+	s.disarmEscalation(EscalateExit)
+	s.disarmWatchdog()
+	s.emit(EventSoftStopAborted, SourceProgrammatic)
+	s.recordTransition(EventSoftStopAborted, cause{})
+	return true
+}
+
+// signal sets the state bit of a tier, closes the channel of the tier, if it
+// has been allocated, and then notifies any waiters of the tier. Returns false
+// if the tier had already been signalled.
+//
+// The state bit is always stored before the channel is closed, and therefore a
+// goroutine woken by a receive on the channel is guaranteed to observe the bit
+// through methods such as IsSoftStopSignalled. Once the bit is stored
+// tierChan returns closedChan rather than allocating a channel.
+func (s *Signaller) signal(t Tier) bool {
+	s.mut.Lock()
+	old := s.state.Load()
+	if old&t.bit() != 0 {
+		s.mut.Unlock()
+		return false
+	}
+	// All transitions of the state word are made with the mutex held.
+	s.state.Store(old | t.bit())
+	if c := s.chans[t].Swap(&closedChan); c != nil && c != &closedChan {
+		close(*c)
+	}
+	notify := s.takeWaiters(t)
+	s.mut.Unlock()
+
+	notifyWaiters(notify)
+	return true
+}
+
+// takeWaiters unlinks the waiters of a tier and returns them chained through
+// their next fields, the mutex must be held.
+func (s *Signaller) takeWaiters(t Tier) *waiter {
+	var taken *waiter
+	for w := s.waiters; w != nil; {
+		next := w.next
+		if w.tier == t {
+			s.unlinkWaiter(w)
+			w.next = taken
+			taken = w
+		}
+		w = next
+	}
+	return taken
+}
+
+// notifyWaiters notifies a chain of waiters returned by takeWaiters, which
+// must be done without the mutex held.
+func notifyWaiters(w *waiter) {
+	for w != nil {
+		next := w.next
+		w.next = nil
+		w.n.notify()
+		w = next
+	}
+}
+
+// RecordStopErr records an error encountered by the component while stopping,
+// which is then reported by StopErr and Close. Nil errors are ignored.
+func (s *Signaller) RecordStopErr(err error) {
+	if s == nil || err == nil {
+		return
+	}
+	x := s.extra()
+	s.mut.Lock()
+	x.stopErrs = append(x.stopErrs, err)
+	s.mut.Unlock()
+}
+
+// StopErr returns any errors encountered while the signaller was stopping, such
+// as those recorded with RecordStopErr and panics recovered from hooks, joined
+// into a single error. Returns nil if no
+// errors have been encountered.
+func (s *Signaller) StopErr() error {
+	if s == nil {
+		return nil
+	}
+	x := s.ext.Load()
+	if x == nil {
+		return nil
+	}
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	return errors.Join(x.stopErrs...)
+}
+
+// Close triggers a hard stop and then waits for the component to signal that it
+// has stopped, returning the result of StopErr. If the component does not stop
+// within the close timeout of the signaller, 30 seconds unless configured with
+// WithCloseTimeout, then an error is returned instead.
+//
+// This allows a component that owns a Signaller to be managed through any API
+// that accepts an io.Closer.
+func (s *Signaller) Close() error {
+	if s == nil {
+		return nil
+	}
+	s.TriggerHardStop()
+
+	ctx := context.Background()
+	if timeout := s.config().closeTimeout; timeout > 0 {
+		var done context.CancelFunc
+		ctx, done = context.WithTimeout(ctx, timeout)
+		defer done()
+	}
+
+	select {
+	case <-s.HasStoppedChan():
+	case <-ctx.Done():
+		if name := s.Name(); name != "" {
+			return fmt.Errorf("signaller %v: %w: %w", name, ErrStopTimeout, ctx.Err())
+		}
+		return fmt.Errorf("%w: %w", ErrStopTimeout, ctx.Err())
+	}
+	return s.StopErr()
+}
+
+// Err returns ErrHardStop once a hard stop has been signalled, ErrSoftStop once
+// a soft stop has been signalled, and nil otherwise, which suits components
+// that reject new work while stopping:
+//
+//	if err := s.Err(); err != nil {
+//		return err
+//	}
+func (s *Signaller) Err() error {
+	switch {
+	case s.IsHardStopSignalled():
+		return ErrHardStop
+	case s.IsSoftStopSignalled():
+		return ErrSoftStop
+	}
+	return nil
 }
 
 //------------------------------------------------------------------------------
@@ -71,97 +614,134 @@ func (s *Signaller) TriggerHasStopped() {
 // IsSoftStopSignalled returns true if the signaller has received the signal to
 // soft stop.
 func (s *Signaller) IsSoftStopSignalled() bool {
-	select {
-	case <-s.SoftStopChan():
-		return true
-	default:
-	}
-	return false
+	return s != nil && s.state.Load()&TierSoftStop.bit() != 0
 }
 
 // SoftStopChan returns a channel that will be closed when the signal to soft or
 // hard stop has been made.
 func (s *Signaller) SoftStopChan() <-chan struct{} {
-	return s.softStopChan
+	return s.tierChan(TierSoftStop)
 }
 
 // SoftStopCtx returns a context.Context that will be terminated when either the
 // provided context is cancelled or the signal to soft or hard stop has been
 // made.
 func (s *Signaller) SoftStopCtx(ctx context.Context) (context.Context, context.CancelFunc) {
-	var cancel context.CancelFunc
-	ctx, cancel = context.WithCancel(ctx)
-	go func() {
-		select {
-		case <-ctx.Done():
-		case <-s.softStopChan:
-		}
-		cancel()
-	}()
-	return ctx, cancel
+	return s.deriveCtx(ctx, TierSoftStop, time.Time{})
+}
+
+// SoftStopCtxWithTimeout returns a context.Context that will be terminated when
+// either the provided context is cancelled, the signal to soft or hard stop has
+// been made, or the timeout elapses. This is equivalent to wrapping the result
+// of SoftStopCtx with context.WithTimeout, but with a single derivation.
+func (s *Signaller) SoftStopCtxWithTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	return s.deriveCtx(ctx, TierSoftStop, s.clock().Now().Add(timeout))
 }
 
 // IsHardStopSignalled returns true if the signaller has received the signal to
 // hard stop.
 func (s *Signaller) IsHardStopSignalled() bool {
-	select {
-	case <-s.HardStopChan():
-		return true
-	default:
-	}
-	return false
+	return s != nil && s.state.Load()&TierHardStop.bit() != 0
 }
 
 // HardStopChan returns a channel that will be closed when the signal to hard
 // stop has been made.
 func (s *Signaller) HardStopChan() <-chan struct{} {
-	return s.hardStopChan
+	return s.tierChan(TierHardStop)
 }
 
 // HardStopCtx returns a context.Context that will be terminated when either the
 // provided context is cancelled or the signal to hard stop has been made.
 func (s *Signaller) HardStopCtx(ctx context.Context) (context.Context, context.CancelFunc) {
-	var cancel context.CancelFunc
-	ctx, cancel = context.WithCancel(ctx)
-	go func() {
-		select {
-		case <-ctx.Done():
-		case <-s.hardStopChan:
-		}
-		cancel()
-	}()
-	return ctx, cancel
+	return s.deriveCtx(ctx, TierHardStop, time.Time{})
+}
+
+// HardStopCtxWithTimeout returns a context.Context that will be terminated when
+// either the provided context is cancelled, the signal to hard stop has been
+// made, or the timeout elapses. This is equivalent to wrapping the result of
+// HardStopCtx with context.WithTimeout, but with a single derivation.
+func (s *Signaller) HardStopCtxWithTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	return s.deriveCtx(ctx, TierHardStop, s.clock().Now().Add(timeout))
 }
 
 // IsHasStoppedSignalled returns true if the signaller has received the signal
 // that the component has stopped.
 func (s *Signaller) IsHasStoppedSignalled() bool {
-	select {
-	case <-s.HasStoppedChan():
-		return true
-	default:
-	}
-	return false
+	return s != nil && s.state.Load()&TierHasStopped.bit() != 0
 }
 
 // HasStoppedChan returns a channel that will be closed when the signal that the
 // component has stopped has been made.
 func (s *Signaller) HasStoppedChan() <-chan struct{} {
-	return s.hasStoppedChan
+	return s.tierChan(TierHasStopped)
 }
 
 // HasStoppedCtx returns a context.Context that will be cancelled when either
 // the provided context is cancelled or the signal that the component has
 // stopped has been made.
 func (s *Signaller) HasStoppedCtx(ctx context.Context) (context.Context, context.CancelFunc) {
-	var cancel context.CancelFunc
-	ctx, cancel = context.WithCancel(ctx)
-	go func() {
-		select {
-		case <-ctx.Done():
-		case <-s.hasStoppedChan:
-		}
-		cancel()
-	}()
-	return ctx, cancel
+	return s.deriveCtx(ctx, TierHasStopped, time.Time{})
+}
+
+//------------------------------------------------------------------------------
+
+// notifier is implemented by types that wish to be notified once a tier has
+// been signalled.
+type notifier interface {
+	notify()
+}
+
+// waiter is an intrusive list entry for a notifier to be called once a tier
+// has been signalled, allowing derived contexts to observe a Signaller without
+// a goroutine of their own.
+type waiter struct {
+	prev, next *waiter
+	tier       Tier
+	listed     bool
+	n          notifier
+}
+
+// addWaiter registers a waiter to be called once the tier is signalled. If the
+// tier has already been signalled the waiter is not registered and false is
+// returned.
+func (s *Signaller) addWaiter(t Tier, w *waiter) bool {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	if s.state.Load()&t.bit() != 0 {
+		return false
+	}
+
+	w.prev, w.next, w.tier, w.listed = nil, s.waiters, t, true
+	if w.next != nil {
+		w.next.prev = w
+	}
+	s.waiters = w
+	return true
+}
+
+// removeWaiter deregisters a waiter, returning true if it was removed before it
+// was called.
+func (s *Signaller) removeWaiter(w *waiter) bool {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	if !w.listed {
+		return false
+	}
+	s.unlinkWaiter(w)
+	return true
+}
+
+// unlinkWaiter removes a waiter from the list, the mutex must be held.
+func (s *Signaller) unlinkWaiter(w *waiter) {
+	if w.prev != nil {
+		w.prev.next = w.next
+	} else {
+		s.waiters = w.next
+	}
+	if w.next != nil {
+		w.next.prev = w.prev
+	}
+	w.prev, w.next, w.listed = nil, nil, false
 }
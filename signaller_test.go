@@ -2,8 +2,12 @@ package shutdown
 
 import (
 	"context"
+	"errors"
+	"io"
+	"sync"
 	"testing"
 	"time"
+	"unsafe"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -100,6 +104,20 @@ func TestSignallerNow(t *testing.T) {
 	assert.True(t, s.IsHasStoppedSignalled())
 }
 
+func TestSignallerErr(t *testing.T) {
+	var nilS *Signaller
+	assert.NoError(t, nilS.Err())
+
+	s := NewSignaller()
+	assert.NoError(t, s.Err())
+
+	s.TriggerSoftStop()
+	assert.ErrorIs(t, s.Err(), ErrSoftStop)
+
+	s.TriggerHardStop()
+	assert.ErrorIs(t, s.Err(), ErrHardStop)
+}
+
 func TestSignallerAtLeisureCtx(t *testing.T) {
 	s := NewSignaller()
 
@@ -206,3 +224,273 @@ func TestSignallerHasClosedCtx(t *testing.T) {
 	done()
 	inDone()
 }
+
+func BenchmarkSignallerIsSignalled(b *testing.B) {
+	s := NewSignaller()
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			if s.IsSoftStopSignalled() || s.IsHardStopSignalled() || s.IsHasStoppedSignalled() {
+				b.Error("unexpected signal")
+			}
+		}
+	})
+}
+
+func TestSignallerLazyChannels(t *testing.T) {
+	s := NewSignaller()
+
+	// Allocated before the signal
+	softChan := s.SoftStopChan()
+	assertOpen(t, softChan)
+	assert.Equal(t, softChan, s.SoftStopChan())
+
+	s.TriggerHardStop()
+	assertClosed(t, softChan)
+
+	// Allocated after the signal
+	assertClosed(t, s.HardStopChan())
+	assertOpen(t, s.HasStoppedChan())
+
+	s.TriggerHasStopped()
+	assertClosed(t, s.HasStoppedChan())
+}
+
+func TestSignallerLazyChannelsRace(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		s := NewSignaller()
+		chans := make(chan (<-chan struct{}), 8)
+		var wg sync.WaitGroup
+		for j := 0; j < cap(chans); j++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				chans <- s.SoftStopChan()
+			}()
+		}
+		s.TriggerSoftStop()
+		wg.Wait()
+		close(chans)
+
+		// Every channel handed out, whichever allocation won, is closed.
+		for c := range chans {
+			assertClosed(t, c)
+		}
+	}
+}
+
+func TestSignallerStateBeforeChannel(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		s := NewSignaller()
+		c := s.SoftStopChan()
+		observed := make(chan bool)
+		go func() {
+			<-c
+			observed <- s.IsSoftStopSignalled() && s.Err() != nil
+		}()
+		s.TriggerSoftStop()
+
+		// A goroutine woken by the channel observes the stop.
+		assert.True(t, <-observed)
+	}
+}
+
+func BenchmarkSignallerChan(b *testing.B) {
+	s := NewSignaller()
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			_ = s.SoftStopChan()
+		}
+	})
+}
+
+func BenchmarkNewSignaller(b *testing.B) {
+	b.ReportAllocs()
+	b.ReportMetric(float64(unsafe.Sizeof(Signaller{})), "struct-bytes")
+	for i := 0; i < b.N; i++ {
+		s := NewSignaller()
+		s.TriggerHardStop()
+		s.TriggerHasStopped()
+	}
+}
+
+func BenchmarkNewSignallerObserved(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		s := NewSignaller()
+		_, _, _ = s.SoftStopChan(), s.HardStopChan(), s.HasStoppedChan()
+		s.TriggerHardStop()
+		s.TriggerHasStopped()
+	}
+}
+
+func TestSignallerCtxWithTimeout(t *testing.T) {
+	s := NewSignaller()
+
+	// Cancelled from timeout
+	ctx, done := s.SoftStopCtxWithTimeout(context.Background(), time.Millisecond*10)
+	deadline, ok := ctx.Deadline()
+	assert.True(t, ok)
+	assert.WithinDuration(t, time.Now().Add(time.Millisecond*10), deadline, time.Millisecond*10)
+	assertClosed(t, ctx.Done())
+	assert.Equal(t, context.DeadlineExceeded, ctx.Err())
+	done()
+
+	// Parent deadline is earlier
+	inCtx, inDone := context.WithTimeout(context.Background(), time.Millisecond*10)
+	ctx, done = s.HardStopCtxWithTimeout(inCtx, time.Hour)
+	parentDeadline, _ := inCtx.Deadline()
+	deadline, _ = ctx.Deadline()
+	assert.Equal(t, parentDeadline, deadline)
+	assertClosed(t, ctx.Done())
+	assert.Equal(t, context.DeadlineExceeded, ctx.Err())
+	done()
+	inDone()
+
+	// Cancelled from returned cancel func
+	ctx, done = s.HardStopCtxWithTimeout(context.Background(), time.Hour)
+	assertOpen(t, ctx.Done())
+	done()
+	assertClosed(t, ctx.Done())
+	assert.Equal(t, context.Canceled, ctx.Err())
+
+	// Cancelled from signals
+	softCtx, softDone := s.SoftStopCtxWithTimeout(context.Background(), time.Hour)
+	hardCtx, hardDone := s.HardStopCtxWithTimeout(context.Background(), time.Hour)
+	s.TriggerSoftStop()
+	assertClosed(t, softCtx.Done())
+	assertOpen(t, hardCtx.Done())
+	s.TriggerHardStop()
+	assertClosed(t, hardCtx.Done())
+	softDone()
+	hardDone()
+
+	// Zero timeout
+	s = NewSignaller()
+	ctx, done = s.SoftStopCtxWithTimeout(context.Background(), 0)
+	assertClosed(t, ctx.Done())
+	assert.Equal(t, context.DeadlineExceeded, ctx.Err())
+	done()
+}
+
+func TestSignallerClose(t *testing.T) {
+	s := NewSignaller()
+
+	var _ io.Closer = s
+
+	errFoo := errors.New("foo")
+	go func() {
+		<-s.HardStopChan()
+		s.RecordStopErr(nil)
+		s.RecordStopErr(errFoo)
+		s.TriggerHasStopped()
+	}()
+
+	assert.ErrorIs(t, s.Close(), errFoo)
+	assert.True(t, s.IsHasStoppedSignalled())
+}
+
+func TestSignallerCloseClean(t *testing.T) {
+	s := NewSignaller()
+	go func() {
+		<-s.HardStopChan()
+		s.TriggerHasStopped()
+	}()
+	assert.NoError(t, s.Close())
+}
+
+func TestSignallerCloseTimeout(t *testing.T) {
+	s := NewSignaller(WithCloseTimeout(time.Millisecond * 10))
+	assert.ErrorIs(t, s.Close(), context.DeadlineExceeded)
+	assert.True(t, s.IsHardStopSignalled())
+}
+
+func TestSignallerName(t *testing.T) {
+	s := NewSignaller()
+	assert.Equal(t, "", s.Name())
+
+	s.SetName("foo")
+	assert.Equal(t, "foo", s.Name())
+
+	s = NewSignaller(WithName("bar"), WithCloseTimeout(time.Millisecond*10))
+	assert.Equal(t, "bar", s.Name())
+	assert.Equal(t, "bar", s.Config().Name)
+
+	s.SetName("baz")
+	assert.Equal(t, "baz", s.Name())
+	assert.Equal(t, "baz", s.Config().Name)
+	err := s.Close()
+	assert.ErrorIs(t, err, ErrStopTimeout)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.EqualError(t, err, "signaller baz: timed out waiting to stop: context deadline exceeded")
+
+	var nilSig *Signaller
+	nilSig.SetName("foo")
+	assert.Equal(t, "", nilSig.Name())
+}
+
+func TestSignallerNil(t *testing.T) {
+	var s *Signaller
+
+	assert.NotPanics(t, func() {
+		s.TriggerSoftStop()
+		s.TriggerHardStop()
+		s.TriggerHasStopped()
+		s.RecordStopErr(errors.New("nope"))
+	})
+
+	assert.False(t, s.IsSoftStopSignalled())
+	assert.False(t, s.IsHardStopSignalled())
+	assert.False(t, s.IsHasStoppedSignalled())
+	assertOpen(t, s.SoftStopChan())
+	assertOpen(t, s.HardStopChan())
+	assertOpen(t, s.HasStoppedChan())
+	assert.False(t, s.AbortSoftStop())
+	assert.NoError(t, s.StopErr())
+	assert.NoError(t, s.Close())
+
+	_, ok := s.HardStopDeadline()
+	assert.False(t, ok)
+
+	called := false
+	stop := s.OnSoftStop(func() { called = true })
+	assert.True(t, stop())
+	assert.False(t, called)
+
+	events, cancel := s.Subscribe()
+	assert.Nil(t, events)
+	cancel()
+
+	// Contexts are only cancelled by their parents or cancel funcs
+	inCtx, inDone := context.WithCancel(context.Background())
+	ctx, done := s.SoftStopCtx(inCtx)
+	tCtx, tDone := s.HardStopCtxWithTimeout(inCtx, time.Hour)
+	pCtx := s.AcquireHasStoppedCtx(inCtx)
+	bgCtx := s.AcquireSoftStopCtx(context.Background())
+	assertOpen(t, ctx.Done())
+	assertOpen(t, tCtx.Done())
+	assertOpen(t, pCtx.Done())
+	assertOpen(t, bgCtx.Done())
+	assert.NoError(t, bgCtx.Err())
+
+	inDone()
+	assertClosed(t, ctx.Done())
+	assertClosed(t, tCtx.Done())
+	assertClosed(t, pCtx.Done())
+	assert.Equal(t, context.Canceled, pCtx.Err())
+
+	done()
+	tDone()
+	pCtx.Release()
+	bgCtx.Release()
+
+	ctx, done = s.HasStoppedCtx(context.Background())
+	done()
+	assertClosed(t, ctx.Done())
+
+	g := NewGroup()
+	g.Add(s)
+	assert.Equal(t, 0, g.Len())
+	assert.False(t, g.Remove(s))
+}
@@ -0,0 +1,134 @@
+package shutdown
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+)
+
+// WithSignals causes the signaller to listen for the provided OS signals, where
+// the first signal received triggers a soft stop and any further signal
+// triggers a hard stop. Listening ends once the signaller has stopped, at which
+// point the default behaviour of the signals is restored.
+//
+// The listener holds a reference to the signaller until it has stopped, and so
+// this option defeats WithLeakDetection.
+func WithSignals(sigs ...os.Signal) Option {
+	return func(o *options) {
+		o.signals = append(o.signals, sigs...)
+	}
+}
+
+// withDefaultSignals listens for the provided OS signals as with WithSignals,
+// unless signals have already been configured with WithSignals, and is
+// therefore applied after any options provided by the user.
+func withDefaultSignals(sigs ...os.Signal) Option {
+	return func(o *options) {
+		if len(o.signals) == 0 {
+			o.signals = append(o.signals, sigs...)
+		}
+	}
+}
+
+// WithSignalTier causes the signaller to listen for the provided OS signals,
+// where receiving any of them triggers the given tier directly rather than
+// escalating as with WithSignals. The tier must be TierSoftStop or
+// TierHardStop, other tiers are treated as TierHardStop.
+func WithSignalTier(t Tier, sigs ...os.Signal) Option {
+	return func(o *options) {
+		if o.signalTiers == nil {
+			o.signalTiers = map[os.Signal]Tier{}
+		}
+		for _, sig := range sigs {
+			o.signalTiers[sig] = t
+		}
+	}
+}
+
+// WithDrainDelay delays the soft stop triggered by an OS signal, as configured
+// with WithSignals or WithSignalTier, or by RequestSoftStop, by the provided
+// duration. This allows a process to continue accepting work while load
+// balancers observe that it is terminating and stop routing to it. A hard stop
+// signal, or a repeated signal, received during the delay is acted upon
+// immediately.
+func WithDrainDelay(delay time.Duration) Option {
+	return func(o *options) {
+		o.drainDelay = delay
+	}
+}
+
+// listenSignals relays OS signals to the signaller until it has stopped.
+func (s *Signaller) listenSignals(o *options) {
+	sigs := append([]os.Signal(nil), o.signals...)
+	for sig := range o.signalTiers {
+		sigs = append(sigs, sig)
+	}
+	c := make(chan os.Signal, 2)
+	signal.Notify(c, sigs...)
+
+	stopped := s.HasStoppedChan()
+	go func() {
+		defer signal.Stop(c)
+
+		var received bool
+		for {
+			select {
+			case sig := <-c:
+				s.ext.Load().received.CompareAndSwap(nil, &sig)
+				t, mapped := o.signalTiers[sig]
+				if !mapped {
+					t = TierSoftStop
+					if received || s.IsSoftStopSignalled() {
+						t = TierHardStop
+					}
+				}
+				received = true
+
+				c := cause{source: SourceOSSignal, reason: "signal " + sig.String()}
+				attrs := []any{"signal", sig.String()}
+				if n, ok := signalNumber(sig); ok {
+					attrs = append(attrs, "signal_number", n)
+				}
+				s.attachStopAttrs(t, attrs)
+				if t != TierSoftStop {
+					s.triggerHardStop(c)
+				} else {
+					o.interruptMessages.print(false)
+					s.requestSoftStop(c)
+				}
+			case <-stopped:
+				if received {
+					o.interruptMessages.print(true)
+				}
+				return
+			}
+		}
+	}()
+}
+
+// ReceivedSignal returns the first OS signal received by the signaller, as
+// configured with WithSignals or WithSignalTier, or nil if none has been
+// received.
+func (s *Signaller) ReceivedSignal() os.Signal {
+	if s == nil {
+		return nil
+	}
+	if x := s.ext.Load(); x != nil {
+		if sig := x.received.Load(); sig != nil {
+			return *sig
+		}
+	}
+	return nil
+}
+
+// ParseSignal returns the OS signal identified by a name such as "SIGTERM",
+// "term" or "INT". The signals available depend on the platform.
+func ParseSignal(name string) (os.Signal, error) {
+	key := strings.TrimPrefix(strings.ToUpper(strings.TrimSpace(name)), "SIG")
+	if sig, ok := signalNames[key]; ok {
+		return sig, nil
+	}
+	return nil, fmt.Errorf("unrecognised signal: %q", name)
+}
@@ -0,0 +1,30 @@
+//go:build !unix && !windows
+
+package shutdown
+
+import "os"
+
+var signalNames = map[string]os.Signal{
+	"INT": os.Interrupt,
+}
+
+// defaultSignals are listened for by a Builder unless configured otherwise.
+var defaultSignals = []os.Signal{os.Interrupt}
+
+// diagnosticsSignals are listened for by WithDiagnosticsDump unless configured
+// otherwise, of which there are none on this platform.
+var diagnosticsSignals []os.Signal
+
+// reloadSignals are listened for by WithReloadSignals unless configured
+// otherwise, of which there are none on this platform.
+var reloadSignals []os.Signal
+
+// sessionEndSignals are delivered at the end of a session, see WithSessionEnd,
+// of which there are none specific to this platform.
+var sessionEndSignals []os.Signal
+
+// signalNumber returns the number of an OS signal, which signals do not have on
+// this platform.
+func signalNumber(sig os.Signal) (int, bool) {
+	return 0, false
+}
@@ -0,0 +1,198 @@
+//go:build unix
+
+package shutdown
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"os"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSignal(t *testing.T) {
+	for _, name := range []string{"SIGTERM", "term", " Term "} {
+		sig, err := ParseSignal(name)
+		require.NoError(t, err)
+		assert.Equal(t, syscall.SIGTERM, sig)
+	}
+
+	_, err := ParseSignal("SIGNOPE")
+	assert.EqualError(t, err, `unrecognised signal: "SIGNOPE"`)
+}
+
+func TestWithSignals(t *testing.T) {
+	s := NewSignaller(WithSignals(syscall.SIGUSR2))
+
+	require.NoError(t, syscall.Kill(syscall.Getpid(), syscall.SIGUSR2))
+	select {
+	case <-s.SoftStopChan():
+	case <-time.After(time.Second * 5):
+		t.Fatal("timed out waiting for soft stop")
+	}
+	assert.False(t, s.IsHardStopSignalled())
+
+	require.NoError(t, syscall.Kill(syscall.Getpid(), syscall.SIGUSR2))
+	select {
+	case <-s.HardStopChan():
+	case <-time.After(time.Second * 5):
+		t.Fatal("timed out waiting for hard stop")
+	}
+	assert.Contains(t, s.StopAttrs(), slog.Int("signal_number", int(syscall.SIGUSR2)))
+
+	s.TriggerHasStopped()
+}
+
+func TestWithSignalTier(t *testing.T) {
+	s := NewSignaller(WithSignalTier(TierHardStop, syscall.SIGUSR2))
+
+	require.NoError(t, syscall.Kill(syscall.Getpid(), syscall.SIGUSR2))
+	select {
+	case <-s.HardStopChan():
+	case <-time.After(time.Second * 5):
+		t.Fatal("timed out waiting for hard stop")
+	}
+
+	s.TriggerHasStopped()
+}
+
+func TestWithDrainDelay(t *testing.T) {
+	clock := newManualClock()
+	s := NewSignaller(WithClock(clock), WithDrainDelay(time.Second), WithSignalTier(TierSoftStop, syscall.SIGUSR2))
+	defer s.TriggerHasStopped()
+
+	require.NoError(t, syscall.Kill(syscall.Getpid(), syscall.SIGUSR2))
+
+	// Wait for the listener to schedule the soft stop.
+	deadline := time.Now().Add(time.Second * 5)
+	for {
+		clock.mut.Lock()
+		scheduled := len(clock.timers) > 0
+		clock.mut.Unlock()
+		if scheduled {
+			break
+		}
+		require.True(t, time.Now().Before(deadline), "timed out waiting for drain delay")
+		time.Sleep(time.Millisecond)
+	}
+	assert.False(t, s.IsSoftStopSignalled())
+
+	clock.Advance(time.Second)
+	assert.True(t, s.IsSoftStopSignalled())
+	assert.False(t, s.IsHardStopSignalled())
+}
+
+func TestWithDiagnosticsDump(t *testing.T) {
+	dumped := make(chan Diagnostics, 1)
+	s := NewSignaller(WithName("worker"), WithDiagnosticsDump(func(d Diagnostics) {
+		dumped <- d
+	}, syscall.SIGUSR2))
+	defer s.TriggerHasStopped()
+
+	require.NoError(t, syscall.Kill(syscall.Getpid(), syscall.SIGUSR2))
+	select {
+	case d := <-dumped:
+		assert.Equal(t, "worker", d.Name)
+	case <-time.After(time.Second * 5):
+		t.Fatal("timed out waiting for diagnostics")
+	}
+	assert.False(t, s.IsSoftStopSignalled())
+}
+
+type syncBuffer struct {
+	mut sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mut.Lock()
+	defer b.mut.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mut.Lock()
+	defer b.mut.Unlock()
+	return b.buf.String()
+}
+
+func TestWithInterruptMessages(t *testing.T) {
+	var out syncBuffer
+	s := NewSignaller(
+		WithSignals(syscall.SIGUSR2),
+		WithInterruptMessages(&out, DefaultInterruptMessage, DefaultStoppedMessage),
+	)
+
+	require.NoError(t, syscall.Kill(syscall.Getpid(), syscall.SIGUSR2))
+	select {
+	case <-s.SoftStopChan():
+	case <-time.After(time.Second * 5):
+		t.Fatal("timed out waiting for soft stop")
+	}
+	assert.Eventually(t, func() bool {
+		return out.String() == DefaultInterruptMessage+"\n"
+	}, time.Second, time.Millisecond)
+
+	s.TriggerHasStopped()
+	assert.Eventually(t, func() bool {
+		return out.String() == DefaultInterruptMessage+"\n"+DefaultStoppedMessage+"\n"
+	}, time.Second, time.Millisecond)
+}
+
+func TestWithInterruptMessagesNotTerminal(t *testing.T) {
+	f, err := os.CreateTemp(t.TempDir(), "out")
+	require.NoError(t, err)
+	defer f.Close()
+
+	s := NewSignaller(WithInterruptMessages(f, "first", "stopped"))
+	assert.Nil(t, s.config().interruptMessages)
+}
+
+func TestReloadSignals(t *testing.T) {
+	s := NewSignaller(WithReloadSignals(syscall.SIGUSR2), WithHistory(2))
+	defer s.TriggerHasStopped()
+	reload := s.ReloadChan()
+
+	require.NoError(t, syscall.Kill(syscall.Getpid(), syscall.SIGUSR2))
+	assertClosed(t, reload)
+	assertOpen(t, s.SoftStopChan())
+	assert.Eventually(t, func() bool {
+		h := s.History()
+		return len(h) == 1 && h[0].TriggeredBy == SourceOSSignal
+	}, time.Second, time.Millisecond)
+}
+
+func TestSignalsBlockingSubscriber(t *testing.T) {
+	s := NewSignaller(WithSignals(syscall.SIGUSR2))
+	defer s.TriggerHasStopped()
+
+	// A subscriber that never receives must not stall the signal listener.
+	_, cancel := s.Subscribe(WithDelivery(DeliverBlocking), WithBuffer(0))
+	defer cancel()
+
+	require.NoError(t, syscall.Kill(syscall.Getpid(), syscall.SIGUSR2))
+	assertClosed(t, s.SoftStopChan())
+	require.NoError(t, syscall.Kill(syscall.Getpid(), syscall.SIGUSR2))
+	assertClosed(t, s.HardStopChan())
+}
+
+func TestRunSignalsReplaceDefaults(t *testing.T) {
+	code := Run(func(ctx context.Context, s *Signaller) error {
+		assert.Equal(t, []os.Signal{syscall.SIGUSR2}, s.Config().Signals)
+
+		require.NoError(t, syscall.Kill(syscall.Getpid(), syscall.SIGUSR2))
+		select {
+		case <-ctx.Done():
+		case <-time.After(time.Second * 5):
+			t.Fatal("timed out waiting for soft stop")
+		}
+		return nil
+	}, WithSignals(syscall.SIGUSR2))
+	assert.Equal(t, 0, code)
+}
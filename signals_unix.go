@@ -0,0 +1,38 @@
+//go:build unix
+
+package shutdown
+
+import (
+	"os"
+	"syscall"
+)
+
+var signalNames = map[string]os.Signal{
+	"INT":  syscall.SIGINT,
+	"TERM": syscall.SIGTERM,
+	"HUP":  syscall.SIGHUP,
+	"QUIT": syscall.SIGQUIT,
+	"USR1": syscall.SIGUSR1,
+	"USR2": syscall.SIGUSR2,
+}
+
+// defaultSignals are listened for by a Builder unless configured otherwise.
+var defaultSignals = []os.Signal{syscall.SIGINT, syscall.SIGTERM}
+
+// diagnosticsSignals are listened for by WithDiagnosticsDump unless configured
+// otherwise.
+var diagnosticsSignals = []os.Signal{syscall.SIGUSR1}
+
+// reloadSignals are listened for by WithReloadSignals unless configured
+// otherwise.
+var reloadSignals = []os.Signal{syscall.SIGHUP}
+
+// sessionEndSignals are delivered at the end of a session, see WithSessionEnd,
+// of which there are none specific to this platform.
+var sessionEndSignals []os.Signal
+
+// signalNumber returns the number of an OS signal.
+func signalNumber(sig os.Signal) (int, bool) {
+	n, ok := sig.(syscall.Signal)
+	return int(n), ok
+}
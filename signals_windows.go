@@ -0,0 +1,36 @@
+//go:build windows
+
+package shutdown
+
+import (
+	"os"
+	"syscall"
+)
+
+var signalNames = map[string]os.Signal{
+	"INT":  syscall.SIGINT,
+	"TERM": syscall.SIGTERM,
+	"HUP":  syscall.SIGHUP,
+	"QUIT": syscall.SIGQUIT,
+}
+
+// defaultSignals are listened for by a Builder unless configured otherwise.
+var defaultSignals = []os.Signal{syscall.SIGINT, syscall.SIGTERM}
+
+// diagnosticsSignals are listened for by WithDiagnosticsDump unless configured
+// otherwise, of which there are none on this platform.
+var diagnosticsSignals []os.Signal
+
+// reloadSignals are listened for by WithReloadSignals unless configured
+// otherwise, of which there are none on this platform.
+var reloadSignals []os.Signal
+
+// sessionEndSignals are delivered by the Go runtime for the console close,
+// logoff and shutdown events, see WithSessionEnd.
+var sessionEndSignals = []os.Signal{syscall.SIGTERM}
+
+// signalNumber returns the number of an OS signal.
+func signalNumber(sig os.Signal) (int, bool) {
+	n, ok := sig.(syscall.Signal)
+	return int(n), ok
+}
@@ -0,0 +1,144 @@
+package shutdown
+
+import (
+	"time"
+)
+
+// Snapshot is a serializable description of the full lifecycle state of a
+// Signaller, as returned by its Snapshot method, which is suitable for
+// including in support bundles or responding to administrative API queries
+// with encoding/json.
+type Snapshot struct {
+	Name string    `json:"name,omitempty"`
+	Time time.Time `json:"time"`
+
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// One of running, soft stopping, hard stopping or stopped.
+	State string `json:"state"`
+
+	SoftStop   bool `json:"soft_stop"`
+	HardStop   bool `json:"hard_stop"`
+	HasStopped bool `json:"has_stopped"`
+
+	// The time of a scheduled hard stop, if any.
+	HardStopDeadline *time.Time `json:"hard_stop_deadline,omitempty"`
+
+	// The received OS signal, if any.
+	Signal string `json:"signal,omitempty"`
+
+	// The errors recorded against the signaller, one per line of StopErr.
+	StopErrors []string `json:"stop_errors,omitempty"`
+
+	// The attributes attached to the signaller, see TriggerSoftStopWith.
+	Attrs map[string]any `json:"attrs,omitempty"`
+
+	// The reasons added with AddStopReason.
+	Reasons []string `json:"reasons,omitempty"`
+
+	OutstandingContexts int `json:"outstanding_contexts"`
+	Goroutines          int `json:"goroutines"`
+
+	Extensions []SnapshotExtension  `json:"extensions,omitempty"`
+	History    []SnapshotTransition `json:"history,omitempty"`
+
+	// The sections added with AddDiagnostics, such as the progress of
+	// drainers and the components of a Builder.
+	Sections []DiagnosticsSection `json:"sections,omitempty"`
+}
+
+// SnapshotExtension is the serializable form of an Extension.
+type SnapshotExtension struct {
+	Reason    string        `json:"reason"`
+	Time      time.Time     `json:"time"`
+	Requested time.Duration `json:"requested_ns"`
+	Granted   time.Duration `json:"granted_ns"`
+}
+
+// SnapshotTransition is the serializable form of a Transition.
+type SnapshotTransition struct {
+	Kind   string         `json:"kind"`
+	Time   time.Time      `json:"time"`
+	Cause  string         `json:"cause,omitempty"`
+	Source string         `json:"source,omitempty"`
+	Attrs  map[string]any `json:"attrs,omitempty"`
+
+	TriggeredBy string `json:"triggered_by"`
+}
+
+// Snapshot returns a serializable description of the current lifecycle state
+// of the signaller, including its recorded history when constructed with
+// WithHistory. A nil signaller returns a zero value Snapshot.
+func (s *Signaller) Snapshot() Snapshot {
+	if s == nil {
+		return Snapshot{}
+	}
+	d := s.Diagnostics()
+	snap := Snapshot{
+		Name:                d.Name,
+		Time:                d.Time,
+		Labels:              d.Labels,
+		State:               lifecycleStateName(s),
+		SoftStop:            d.SoftStop,
+		HardStop:            d.HardStop,
+		HasStopped:          d.HasStopped,
+		Attrs:               attrsMap(d.Attrs),
+		Reasons:             d.Reasons,
+		OutstandingContexts: d.OutstandingContexts,
+		Goroutines:          d.Goroutines,
+		Sections:            d.Sections,
+	}
+	if !d.HardStopDeadline.IsZero() {
+		snap.HardStopDeadline = &d.HardStopDeadline
+	}
+	if sig := s.ReceivedSignal(); sig != nil {
+		snap.Signal = sig.String()
+	}
+	if d.StopErr != nil {
+		snap.StopErrors = splitLines(d.StopErr.Error())
+	}
+	for _, e := range d.Extensions {
+		snap.Extensions = append(snap.Extensions, SnapshotExtension(e))
+	}
+	for _, t := range d.History {
+		snap.History = append(snap.History, SnapshotTransition{
+			Kind:   t.Kind.String(),
+			Time:   t.Time,
+			Cause:  t.Cause,
+			Source: t.Source,
+			Attrs:  attrsMap(t.Attrs),
+
+			TriggeredBy: t.TriggeredBy.String(),
+		})
+	}
+	return snap
+}
+
+// GroupSnapshot is a serializable description of the state of a Group, as
+// returned by its Snapshot method.
+type GroupSnapshot struct {
+	Triggered bool `json:"triggered"`
+	Stopped   bool `json:"stopped"`
+
+	// The names of the members that have not stopped, and how long each of
+	// them has been draining.
+	Pending  []string                 `json:"pending,omitempty"`
+	Draining map[string]time.Duration `json:"draining_ns,omitempty"`
+
+	Members []Snapshot `json:"members,omitempty"`
+}
+
+// Snapshot returns a serializable description of the current state of the
+// group and each of its members.
+func (g *Group) Snapshot() GroupSnapshot {
+	snap := GroupSnapshot{
+		Triggered: g.triggered.Load() != 0,
+		Pending:   g.Pending(),
+		Draining:  g.Draining(),
+		Stopped:   g.stopped.IsHasStoppedSignalled(),
+	}
+	for _, m := range g.members() {
+		snap.Members = append(snap.Members, m.Snapshot())
+	}
+	return snap
+}
@@ -0,0 +1,95 @@
+package shutdown
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSnapshot(t *testing.T) {
+	clock := newManualClock()
+	s := NewSignaller(
+		WithName("app"),
+		WithClock(clock),
+		WithHistory(4),
+		WithHardStopGrace(time.Second*10),
+		WithExtensionLimit(time.Second),
+	)
+	s.AddDiagnostics("drainers", func() string {
+		return "db: draining\n"
+	})
+	s.RecordStopErr(errors.New("first"))
+	s.RecordStopErr(errors.New("second"))
+	s.TriggerSoftStopWith("deploy_id", "d-123")
+	s.RequestExtension("flush", time.Second)
+
+	b, err := json.Marshal(s.Snapshot())
+	require.NoError(t, err)
+
+	var snap map[string]any
+	require.NoError(t, json.Unmarshal(b, &snap))
+	delete(snap["history"].([]any)[0].(map[string]any), "source")
+
+	assert.Equal(t, map[string]any{
+		"name":                 "app",
+		"time":                 "1970-01-01T00:16:40Z",
+		"state":                "soft stopping",
+		"soft_stop":            true,
+		"hard_stop":            false,
+		"has_stopped":          false,
+		"hard_stop_deadline":   "1970-01-01T00:16:51Z",
+		"stop_errors":          []any{"first", "second"},
+		"attrs":                map[string]any{"deploy_id": "d-123"},
+		"outstanding_contexts": float64(0),
+		"goroutines":           float64(0),
+		"extensions": []any{map[string]any{
+			"reason":       "flush",
+			"time":         "1970-01-01T00:16:40Z",
+			"requested_ns": float64(time.Second),
+			"granted_ns":   float64(time.Second),
+		}},
+		"history": []any{map[string]any{
+			"kind":         "soft stop",
+			"time":         "1970-01-01T00:16:40Z",
+			"attrs":        map[string]any{"deploy_id": "d-123"},
+			"triggered_by": "programmatic",
+		}},
+		"sections": []any{map[string]any{
+			"name":   "drainers",
+			"report": "db: draining\n",
+		}},
+	}, snap)
+}
+
+func TestSnapshotNil(t *testing.T) {
+	var s *Signaller
+	assert.Equal(t, Snapshot{}, s.Snapshot())
+}
+
+func TestGroupSnapshot(t *testing.T) {
+	g := NewGroup()
+	a, b := NewSignaller(WithName("b")), NewSignaller(WithName("a"))
+	g.Add(a)
+	g.Add(b)
+
+	snap := g.Snapshot()
+	assert.False(t, snap.Triggered)
+	assert.Equal(t, []string{"a", "b"}, snap.Pending)
+	require.Len(t, snap.Members, 2)
+	assert.Equal(t, "a", snap.Members[0].Name)
+	assert.Equal(t, "running", snap.Members[0].State)
+
+	g.TriggerHardStop()
+	a.TriggerHasStopped()
+	b.TriggerHasStopped()
+
+	snap = g.Snapshot()
+	assert.True(t, snap.Triggered)
+	assert.True(t, snap.Stopped)
+	assert.Empty(t, snap.Pending)
+	assert.Equal(t, "stopped", snap.Members[1].State)
+}
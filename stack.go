@@ -0,0 +1,77 @@
+package shutdown
+
+import (
+	"bytes"
+	"fmt"
+	"runtime"
+	"runtime/debug"
+)
+
+// WithTriggerStacks is a debug option that records the stack of the goroutine
+// that triggers each tier of the signaller, which is returned by TriggerStack
+// and included in its diagnostics. This answers the question of who triggered
+// a stop when a component begins stopping unexpectedly.
+//
+// Capturing stacks is expensive, but only happens once per tier.
+func WithTriggerStacks() Option {
+	return func(o *options) {
+		o.triggerStacks = true
+	}
+}
+
+// TriggerStack returns the stack of the goroutine that triggered the tier, as
+// recorded when constructed with WithTriggerStacks, or nil if the tier has not
+// been triggered or stacks are not recorded.
+func (s *Signaller) TriggerStack(t Tier) []byte {
+	if s == nil || t < TierSoftStop || t > TierHasStopped {
+		return nil
+	}
+	x := s.ext.Load()
+	if x == nil || !x.triggerStacks {
+		return nil
+	}
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	return x.stacks[t]
+}
+
+// recordTriggerStack records the stack of the caller as having triggered the
+// tier, if configured.
+func (s *Signaller) recordTriggerStack(t Tier) {
+	x := s.ext.Load()
+	if x == nil || !x.triggerStacks {
+		return
+	}
+	stack := debug.Stack()
+	s.mut.Lock()
+	x.stacks[t] = stack
+	s.mut.Unlock()
+}
+
+// callers is a stack captured as program counters, which is far cheaper than
+// debug.Stack, and is only symbolized once it is reported.
+type callers []uintptr
+
+// captureCallers returns the stack of the caller, omitting skip frames above
+// it.
+func captureCallers(skip int) callers {
+	var pcs [32]uintptr
+	n := runtime.Callers(skip+2, pcs[:])
+	return append(callers(nil), pcs[:n]...)
+}
+
+// format symbolizes the stack in the style of debug.Stack.
+func (c callers) format() []byte {
+	if len(c) == 0 {
+		return nil
+	}
+	var buf bytes.Buffer
+	frames := runtime.CallersFrames(c)
+	for {
+		f, more := frames.Next()
+		fmt.Fprintf(&buf, "%v(...)\n\t%v:%v\n", f.Function, f.File, f.Line)
+		if !more {
+			return buf.Bytes()
+		}
+	}
+}
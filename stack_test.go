@@ -0,0 +1,51 @@
+package shutdown
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTriggerStacks(t *testing.T) {
+	s := NewSignaller(WithTriggerStacks())
+	assert.Nil(t, s.TriggerStack(TierSoftStop))
+
+	var inHook []byte
+	s.OnSoftStop(func() {
+		inHook = s.TriggerStack(TierSoftStop)
+	})
+
+	triggerFromHelper(s)
+	assert.Contains(t, string(s.TriggerStack(TierSoftStop)), "shutdown.triggerFromHelper")
+	assert.Equal(t, s.TriggerStack(TierSoftStop), inHook)
+	assert.Nil(t, s.TriggerStack(TierHardStop))
+	assert.Nil(t, s.TriggerStack(Tier(7)))
+	assert.True(t, s.Config().TriggerStacks)
+
+	var buf bytes.Buffer
+	_, err := s.Diagnostics().WriteTo(&buf)
+	require.NoError(t, err)
+	assert.Contains(t, buf.String(), "  soft stop triggered by:\n    goroutine ")
+}
+
+func triggerFromHelper(s *Signaller) {
+	s.TriggerSoftStop()
+}
+
+func TestTriggerStacksDisabled(t *testing.T) {
+	s := NewSignaller()
+	s.TriggerHardStop()
+	assert.Nil(t, s.TriggerStack(TierHardStop))
+
+	var nilS *Signaller
+	assert.Nil(t, nilS.TriggerStack(TierHardStop))
+}
+
+func TestCaptureCallers(t *testing.T) {
+	stack := string(captureCallers(0).format())
+	assert.Contains(t, stack, "shutdown.TestCaptureCallers(...)\n\t")
+	assert.Contains(t, stack, "stack_test.go:")
+	assert.Nil(t, callers(nil).format())
+}
@@ -0,0 +1,35 @@
+package shutdown
+
+import (
+	"context"
+)
+
+// StopFuture is a handle on the eventual outcome of a signaller stopping,
+// obtained with Stopped, which suits code organised around futures or promises
+// rather than channels.
+type StopFuture struct {
+	s *Signaller
+}
+
+// Stopped returns a handle that resolves once the signaller has reported that
+// it has stopped, carrying the result of StopErr at that point.
+func (s *Signaller) Stopped() StopFuture {
+	return StopFuture{s: s}
+}
+
+// Done returns a channel that is closed once the signaller has stopped.
+func (f StopFuture) Done() <-chan struct{} {
+	return f.s.HasStoppedChan()
+}
+
+// Get blocks until the signaller has stopped, returning the result of StopErr
+// and true, or until the context is cancelled, returning the error of the
+// context and false.
+func (f StopFuture) Get(ctx context.Context) (error, bool) {
+	select {
+	case <-f.Done():
+		return f.s.StopErr(), true
+	case <-ctx.Done():
+		return ctx.Err(), false
+	}
+}
@@ -0,0 +1,39 @@
+package shutdown
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStopped(t *testing.T) {
+	s := NewSignaller()
+	f := s.Stopped()
+
+	ctx, done := context.WithTimeout(context.Background(), time.Millisecond)
+	defer done()
+	err, ok := f.Get(ctx)
+	assert.False(t, ok)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assertOpen(t, f.Done())
+
+	s.RecordStopErr(errors.New("flush failed"))
+	s.TriggerHasStopped()
+	assertClosed(t, f.Done())
+
+	err, ok = f.Get(context.Background())
+	assert.True(t, ok)
+	assert.EqualError(t, err, "flush failed")
+}
+
+func TestStoppedClean(t *testing.T) {
+	s := NewSignaller()
+	go s.TriggerHasStopped()
+
+	err, ok := s.Stopped().Get(context.Background())
+	assert.True(t, ok)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,113 @@
+package shutdown
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// StopStrategy decides how the members of a group are soft stopped, see
+// WithStopStrategy. StopMembers is called for the members of each priority of
+// the group in turn, see AddWithPriority, and should soft stop each of them
+// with trigger, which attributes the stop to the group, and block until they
+// have all reported that they have stopped. The context is cancelled by a
+// hard stop of the group, at which point StopMembers should return the error
+// of the context, and both the remaining members and any later priorities are
+// hard stopped by the group.
+type StopStrategy interface {
+	StopMembers(ctx context.Context, members []*Signaller, trigger func(s *Signaller)) error
+}
+
+// WithStopStrategy sets how the members of a group are soft stopped. Without
+// this option, and without members added with a priority, a soft stop is
+// broadcast to every member from the calling goroutine. Otherwise the stop is
+// carried out from a goroutine of its own.
+func WithStopStrategy(strategy StopStrategy) GroupOption {
+	return func(g *Group) {
+		g.strategy = strategy
+	}
+}
+
+// WithStopParallelism caps the number of members of a group that are soft
+// stopped concurrently within each priority, and is shorthand for
+// WithStopStrategy(StopBoundedParallel(n)).
+func WithStopParallelism(n int) GroupOption {
+	return WithStopStrategy(StopBoundedParallel(n))
+}
+
+// StopBroadcast returns a strategy that soft stops every member at once.
+func StopBroadcast() StopStrategy {
+	return boundedStrategy(0)
+}
+
+// StopSequential returns a strategy that soft stops one member at a time,
+// waiting for each to report that it has stopped before triggering the next.
+func StopSequential() StopStrategy {
+	return boundedStrategy(1)
+}
+
+// StopBoundedParallel returns a strategy that soft stops no more than n members
+// at a time, where further members are only triggered as earlier ones report
+// that they have stopped. A limit of zero or less soft stops every member at
+// once, as with StopBroadcast.
+func StopBoundedParallel(n int) StopStrategy {
+	return boundedStrategy(max(n, 0))
+}
+
+// boundedStrategy triggers no more than the given number of members at a time,
+// or every member at once when zero.
+type boundedStrategy int
+
+// stopWaiter calls fn once a member has stopped.
+type stopWaiter struct {
+	w  waiter
+	fn func()
+}
+
+func (l *stopWaiter) notify() {
+	l.fn()
+}
+
+func (n boundedStrategy) StopMembers(ctx context.Context, members []*Signaller, trigger func(s *Signaller)) error {
+	var slots chan struct{}
+	if n > 0 {
+		slots = make(chan struct{}, n)
+	}
+	var remaining atomic.Int64
+	remaining.Store(int64(len(members)))
+	stopped := make(chan struct{})
+	if len(members) == 0 {
+		close(stopped)
+	}
+
+	for _, s := range members {
+		if slots != nil {
+			select {
+			case slots <- struct{}{}:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		trigger(s)
+
+		// Members are observed with waiters rather than a goroutine each.
+		l := &stopWaiter{fn: func() {
+			if slots != nil {
+				<-slots
+			}
+			if remaining.Add(-1) == 0 {
+				close(stopped)
+			}
+		}}
+		l.w.n = l
+		if !s.addWaiter(TierHasStopped, &l.w) {
+			l.fn()
+		}
+	}
+
+	select {
+	case <-stopped:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
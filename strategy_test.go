@@ -0,0 +1,84 @@
+package shutdown
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGroupStopParallelism(t *testing.T) {
+	g := NewGroup(WithStopParallelism(2))
+	a, b, c := NewSignaller(WithName("a")), NewSignaller(WithName("b")), NewSignaller(WithName("c"))
+	g.Add(a)
+	g.Add(b)
+	g.Add(c)
+
+	g.TriggerSoftStop()
+	assertClosed(t, a.SoftStopChan())
+	assertClosed(t, b.SoftStopChan())
+	time.Sleep(time.Millisecond * 10)
+	assertOpen(t, c.SoftStopChan())
+
+	b.TriggerHasStopped()
+	assertClosed(t, c.SoftStopChan())
+
+	a.TriggerHasStopped()
+	c.TriggerHasStopped()
+	require.NoError(t, g.Wait(waitCtx(t)))
+}
+
+func TestGroupStopSequential(t *testing.T) {
+	g := NewGroup(WithStopStrategy(StopSequential()))
+	a, b := NewSignaller(WithName("a")), NewSignaller(WithName("b"))
+	g.Add(b)
+	g.Add(a)
+
+	g.TriggerSoftStop()
+	assertClosed(t, a.SoftStopChan())
+	time.Sleep(time.Millisecond * 10)
+	assertOpen(t, b.SoftStopChan())
+
+	a.TriggerHasStopped()
+	assertClosed(t, b.SoftStopChan())
+	b.TriggerHasStopped()
+	require.NoError(t, g.Wait(waitCtx(t)))
+}
+
+type reverseStrategy struct {
+	levels [][]string
+}
+
+func (r *reverseStrategy) StopMembers(ctx context.Context, members []*Signaller, trigger func(s *Signaller)) error {
+	var names []string
+	for i := len(members) - 1; i >= 0; i-- {
+		names = append(names, members[i].Name())
+	}
+	r.levels = append(r.levels, names)
+
+	for i := len(members) - 1; i >= 0; i-- {
+		trigger(members[i])
+		select {
+		case <-members[i].HasStoppedChan():
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+func TestGroupCustomStopStrategy(t *testing.T) {
+	strategy := &reverseStrategy{}
+	g := NewGroup(WithStopStrategy(strategy))
+	for _, name := range []string{"a", "b", "c"} {
+		s := NewSignaller(WithName(name))
+		s.OnSoftStop(func() { go s.TriggerHasStopped() })
+		g.Add(s)
+	}
+
+	g.TriggerSoftStop()
+	require.NoError(t, g.Wait(waitCtx(t)))
+	assert.Equal(t, [][]string{{"c", "b", "a"}}, strategy.levels)
+}
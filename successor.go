@@ -0,0 +1,104 @@
+package shutdown
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// EnvReadyFD is the environment variable through which a process passes the
+// descriptor of the write end of a readiness pipe to its successor, see
+// NotifyReadyFromEnv.
+const EnvReadyFD = "SHUTDOWN_READY_FD"
+
+// readyMessage is written by a successor once it is ready.
+const readyMessage = "ready"
+
+// ErrSuccessorTimeout is returned by HandoffSoftStop when the successor did not
+// signal readiness before the timeout elapsed.
+var ErrSuccessorTimeout = errors.New("timed out waiting for successor to become ready")
+
+// ErrSuccessorGone is returned by HandoffSoftStop when the readiness pipe or
+// socket was closed without the successor having signalled readiness, which
+// usually means that it failed to start.
+var ErrSuccessorGone = errors.New("successor exited without becoming ready")
+
+// NotifyReady signals to a predecessor that the calling process is ready to
+// take over, by writing to the readiness pipe or socket that the predecessor
+// reads with HandoffSoftStop.
+func NotifyReady(w io.Writer) error {
+	_, err := io.WriteString(w, readyMessage+"\n")
+	return err
+}
+
+// NotifyReadyFromEnv signals readiness through the descriptor named by the
+// SHUTDOWN_READY_FD environment variable, which is then closed, and does
+// nothing if the variable is unset. A predecessor passes the write end of a
+// pipe to its successor with exec.Cmd.ExtraFiles and sets the variable to its
+// descriptor, which is 3 for the first extra file.
+func NotifyReadyFromEnv() error {
+	v := os.Getenv(EnvReadyFD)
+	if v == "" {
+		return nil
+	}
+	fd, err := strconv.ParseUint(v, 10, 0)
+	if err != nil {
+		return fmt.Errorf("parsing %v: %w", EnvReadyFD, err)
+	}
+	f := os.NewFile(uintptr(fd), "ready")
+	if f == nil {
+		return fmt.Errorf("parsing %v: invalid descriptor %v", EnvReadyFD, fd)
+	}
+	return errors.Join(NotifyReady(f), f.Close())
+}
+
+// HandoffSoftStop delays the soft stop of a process that is being replaced
+// until its successor signals that it is ready, by calling NotifyReady, over
+// the provided pipe or socket, and then triggers the soft stop. This pairs
+// with handing the listeners of the process over to the successor for restarts
+// without downtime, as the process continues to serve until the successor is
+// able to.
+//
+// If the successor does not become ready within the timeout, or closes the
+// pipe without becoming ready, then the soft stop is not triggered and
+// ErrSuccessorTimeout or ErrSuccessorGone is returned, leaving the process to
+// decide whether to continue serving or to stop regardless. A soft stop
+// signalled by other means during the wait ends it early with a nil error.
+func HandoffSoftStop(s *Signaller, r io.Reader, timeout time.Duration) error {
+	readyC := make(chan error, 1)
+	go func() {
+		lines := bufio.NewScanner(r)
+		for lines.Scan() {
+			if strings.TrimSpace(lines.Text()) == readyMessage {
+				readyC <- nil
+				return
+			}
+		}
+		if err := lines.Err(); err != nil {
+			readyC <- fmt.Errorf("%w: %w", ErrSuccessorGone, err)
+			return
+		}
+		readyC <- ErrSuccessorGone
+	}()
+
+	t := time.NewTimer(timeout)
+	defer t.Stop()
+
+	select {
+	case err := <-readyC:
+		if err != nil {
+			return err
+		}
+		s.TriggerSoftStop()
+		return nil
+	case <-t.C:
+		return ErrSuccessorTimeout
+	case <-s.SoftStopChan():
+		return nil
+	}
+}
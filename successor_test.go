@@ -0,0 +1,71 @@
+package shutdown
+
+import (
+	"io"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandoffSoftStop(t *testing.T) {
+	s := NewSignaller()
+	r, w := io.Pipe()
+
+	errC := make(chan error, 1)
+	go func() {
+		errC <- HandoffSoftStop(s, r, time.Second*5)
+	}()
+
+	_, err := io.WriteString(w, "warming up\n")
+	require.NoError(t, err)
+	assert.False(t, s.IsSoftStopSignalled())
+
+	require.NoError(t, NotifyReady(w))
+	require.NoError(t, <-errC)
+	assert.True(t, s.IsSoftStopSignalled())
+}
+
+func TestHandoffSoftStopTimeout(t *testing.T) {
+	s := NewSignaller()
+	r, w := io.Pipe()
+	defer w.Close()
+
+	assert.ErrorIs(t, HandoffSoftStop(s, r, time.Millisecond*10), ErrSuccessorTimeout)
+	assert.False(t, s.IsSoftStopSignalled())
+}
+
+func TestHandoffSoftStopSuccessorGone(t *testing.T) {
+	s := NewSignaller()
+	r, w := io.Pipe()
+	require.NoError(t, w.Close())
+
+	assert.ErrorIs(t, HandoffSoftStop(s, r, time.Second*5), ErrSuccessorGone)
+	assert.False(t, s.IsSoftStopSignalled())
+}
+
+func TestNotifyReadyFromEnv(t *testing.T) {
+	t.Setenv(EnvReadyFD, "")
+	require.NoError(t, NotifyReadyFromEnv())
+
+	r, w, err := os.Pipe()
+	require.NoError(t, err)
+	defer r.Close()
+
+	t.Setenv(EnvReadyFD, strconv.Itoa(int(w.Fd())))
+	require.NoError(t, NotifyReadyFromEnv())
+
+	// The descriptor has been closed by NotifyReadyFromEnv, which prevents
+	// the finalizer of w from closing it again later.
+	_ = w.Close()
+
+	s := NewSignaller()
+	require.NoError(t, HandoffSoftStop(s, r, time.Second*5))
+	assert.True(t, s.IsSoftStopSignalled())
+
+	t.Setenv(EnvReadyFD, "nope")
+	assert.ErrorContains(t, NotifyReadyFromEnv(), "parsing "+EnvReadyFD)
+}
@@ -0,0 +1,62 @@
+package shutdown
+
+import (
+	"context"
+	"slices"
+)
+
+// TagStatus describes the members of a group that share a tag.
+type TagStatus struct {
+	// The number of members with the tag.
+	Members int
+
+	// The number of members with the tag that have stopped.
+	Stopped int
+}
+
+// TriggerSoftStopTagged signals to the members of the group with the tag, see
+// AddTagged, that they should terminate at their own leisure, leaving the
+// remaining members running. Returns the number of members triggered.
+func (g *Group) TriggerSoftStopTagged(tag string) int {
+	return g.triggerSubset(g.tagged(tag), false)
+}
+
+// TriggerHardStopTagged signals to the members of the group with the tag that
+// they should terminate right now, leaving the remaining members running.
+// Returns the number of members triggered.
+func (g *Group) TriggerHardStopTagged(tag string) int {
+	return g.triggerSubset(g.tagged(tag), true)
+}
+
+// WaitTagged blocks until every member of the group with the tag has stopped,
+// or the context is cancelled, in which case a *PendingError naming the members
+// with the tag that have not stopped and wrapping the error of the context is
+// returned.
+func (g *Group) WaitTagged(ctx context.Context, tag string) error {
+	return waitSubset(ctx, g.tagged(tag))
+}
+
+// Tags returns the status of the members of the group aggregated by each of
+// their tags.
+func (g *Group) Tags() map[string]TagStatus {
+	tags := map[string]TagStatus{}
+	g.each(func(m *groupMember) {
+		stopped := m.s.IsHasStoppedSignalled()
+		for _, t := range m.tags {
+			status := tags[t]
+			status.Members++
+			if stopped {
+				status.Stopped++
+			}
+			tags[t] = status
+		}
+	})
+	return tags
+}
+
+// tagged returns the members of the group with the tag, sorted by name.
+func (g *Group) tagged(tag string) []*Signaller {
+	return g.subset(func(m *groupMember) bool {
+		return slices.Contains(m.tags, tag)
+	})
+}
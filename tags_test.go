@@ -0,0 +1,42 @@
+package shutdown
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGroupTags(t *testing.T) {
+	g := NewGroup()
+	http := NewSignaller(WithName("http"))
+	db := NewSignaller(WithName("db"))
+	cache := NewSignaller(WithName("cache"))
+	g.AddTagged(http, "network")
+	g.AddTagged(db, "storage", "network")
+	g.AddTagged(cache, "storage")
+	g.Add(NewSignaller(WithName("untagged")))
+
+	assert.Equal(t, map[string]TagStatus{
+		"network": {Members: 2},
+		"storage": {Members: 2},
+	}, g.Tags())
+
+	assert.Equal(t, 2, g.TriggerSoftStopTagged("storage"))
+	assertClosed(t, db.SoftStopChan())
+	assertClosed(t, cache.SoftStopChan())
+	assertOpen(t, http.SoftStopChan())
+
+	db.TriggerHasStopped()
+	cache.TriggerHasStopped()
+	require.NoError(t, g.WaitTagged(waitCtx(t), "storage"))
+
+	assert.Equal(t, map[string]TagStatus{
+		"network": {Members: 2, Stopped: 1},
+		"storage": {Members: 2, Stopped: 2},
+	}, g.Tags())
+
+	assert.Equal(t, 2, g.TriggerHardStopTagged("network"))
+	assertClosed(t, http.HardStopChan())
+	assert.Equal(t, 0, g.TriggerHardStopTagged("missing"))
+}
@@ -0,0 +1,76 @@
+package shutdown
+
+import (
+	"time"
+)
+
+// MetricsWithDurations is an optional extension of Metrics, where
+// implementations additionally receive the durations between the tiers of a
+// signaller, which are suited to histograms for tracking shutdown latency
+// across deploys. When a tier is signalled StopDuration is called once for each
+// earlier tier that has been signalled, with the duration between them, and so
+// the soft to hard stop, soft stop to stopped and hard stop to stopped
+// durations are observed for each named signaller.
+type MetricsWithDurations interface {
+	Metrics
+
+	StopDuration(name string, from, to Tier, d time.Duration)
+}
+
+// SignalledAt returns the time at which the tier was signalled, according to
+// the clock of the signaller, or false if it has not been signalled. Times are
+// only recorded for signallers constructed with options.
+func (s *Signaller) SignalledAt(t Tier) (time.Time, bool) {
+	if s == nil || t < TierSoftStop || t > TierHasStopped || s.state.Load()&t.bit() == 0 {
+		return time.Time{}, false
+	}
+	x := s.ext.Load()
+	if x == nil {
+		return time.Time{}, false
+	}
+	at := x.signalledAt[t].Load()
+	if at == 0 {
+		return time.Time{}, false
+	}
+	return time.Unix(0, at), true
+}
+
+// StopDuration returns the duration between two tiers being signalled, or
+// false if either has not been signalled, see SignalledAt.
+func (s *Signaller) StopDuration(from, to Tier) (time.Duration, bool) {
+	fromAt, ok := s.SignalledAt(from)
+	if !ok {
+		return 0, false
+	}
+	toAt, ok := s.SignalledAt(to)
+	if !ok {
+		return 0, false
+	}
+	return toAt.Sub(fromAt), true
+}
+
+// recordSignalledAt records the time at which a tier was signalled, and
+// reports the durations since earlier tiers to metrics implementing
+// MetricsWithDurations.
+func (s *Signaller) recordSignalledAt(t Tier) {
+	x := s.ext.Load()
+	if x == nil {
+		return
+	}
+	now := s.clock().Now()
+	x.signalledAt[t].Store(now.UnixNano())
+
+	m, ok := x.metrics.(MetricsWithDurations)
+	if !ok {
+		return
+	}
+	name := x.currentName()
+	for from := TierSoftStop; from < t; from++ {
+		if s.state.Load()&from.bit() == 0 {
+			continue
+		}
+		if at := x.signalledAt[from].Load(); at != 0 {
+			m.StopDuration(name, from, t, now.Sub(time.Unix(0, at)))
+		}
+	}
+}
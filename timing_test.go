@@ -0,0 +1,96 @@
+package shutdown
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type durationObservation struct {
+	name     string
+	from, to Tier
+	d        time.Duration
+}
+
+type durationMetrics struct {
+	recordingMetrics
+
+	mut       sync.Mutex
+	durations []durationObservation
+}
+
+func (m *durationMetrics) StopDuration(name string, from, to Tier, d time.Duration) {
+	m.mut.Lock()
+	m.durations = append(m.durations, durationObservation{name, from, to, d})
+	m.mut.Unlock()
+}
+
+func TestStopDurations(t *testing.T) {
+	clock := newManualClock()
+	m := &durationMetrics{}
+	s := NewSignaller(WithName("foo"), WithClock(clock), WithMetrics(m))
+	start := clock.Now()
+
+	_, ok := s.SignalledAt(TierSoftStop)
+	assert.False(t, ok)
+
+	s.TriggerSoftStop()
+	clock.Advance(time.Second)
+	s.TriggerHardStop()
+	clock.Advance(time.Second * 2)
+	s.TriggerHasStopped()
+
+	at, ok := s.SignalledAt(TierHardStop)
+	require.True(t, ok)
+	assert.Equal(t, start.Add(time.Second), at)
+
+	d, ok := s.StopDuration(TierSoftStop, TierHasStopped)
+	require.True(t, ok)
+	assert.Equal(t, time.Second*3, d)
+
+	assert.Equal(t, []durationObservation{
+		{"foo", TierSoftStop, TierHardStop, time.Second},
+		{"foo", TierSoftStop, TierHasStopped, time.Second * 3},
+		{"foo", TierHardStop, TierHasStopped, time.Second * 2},
+	}, m.durations)
+}
+
+func TestStopDurationsUnsignalled(t *testing.T) {
+	s := NewSignaller(WithName("foo"))
+	s.TriggerSoftStop()
+
+	_, ok := s.StopDuration(TierSoftStop, TierHardStop)
+	assert.False(t, ok)
+
+	_, ok = NewSignaller().SignalledAt(TierSoftStop)
+	assert.False(t, ok)
+}
+
+func TestBuilderComponentDurations(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	m := &durationMetrics{}
+	err := New().
+		WithOptions(WithMetrics(m)).
+		WithComponent("foo", func(s *Signaller) error {
+			<-s.SoftStopChan()
+			return nil
+		}).
+		Run(ctx)
+	require.NoError(t, err)
+
+	m.mut.Lock()
+	defer m.mut.Unlock()
+	var names []string
+	for _, o := range m.durations {
+		if o.from == TierSoftStop && o.to == TierHasStopped {
+			names = append(names, o.name)
+		}
+	}
+	assert.Contains(t, names, "foo")
+}
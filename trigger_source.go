@@ -0,0 +1,87 @@
+package shutdown
+
+// TriggerSource describes what triggered a tier of a signaller, which is
+// recorded with each trigger and exposed by TriggeredBy, events and the
+// history of the signaller.
+type TriggerSource int32
+
+const (
+	// SourceProgrammatic is a trigger made directly by the program, such as a
+	// call to TriggerSoftStop, and is the source of any trigger that does not
+	// specify one.
+	SourceProgrammatic TriggerSource = iota
+
+	// SourceOSSignal is a trigger made by an OS signal, see WithSignals.
+	SourceOSSignal
+
+	// SourceAdminAPI is a trigger made by an administrative request, such as
+	// a stop command received by ServeControl or a stop request from a peer.
+	SourceAdminAPI
+
+	// SourceWatchdog is a trigger made by timers of the signaller itself, such
+	// as escalating a soft stop to a hard stop once its grace elapses.
+	SourceWatchdog
+
+	// SourceParentContext is a trigger propagated from a parent, such as a
+	// group or a cancelled context.
+	SourceParentContext
+)
+
+// String returns a human readable name of the source.
+func (src TriggerSource) String() string {
+	switch src {
+	case SourceProgrammatic:
+		return "programmatic"
+	case SourceOSSignal:
+		return "os signal"
+	case SourceAdminAPI:
+		return "admin api"
+	case SourceWatchdog:
+		return "watchdog"
+	case SourceParentContext:
+		return "parent context"
+	}
+	return "unknown"
+}
+
+// cause describes a trigger of a signaller, which is its source and a human
+// readable reason recorded in the history of the signaller.
+type cause struct {
+	source TriggerSource
+	reason string
+}
+
+// TriggerSoftStopFrom behaves as TriggerSoftStop, but attributes the trigger to
+// the provided source, for callers that relay stops from elsewhere, such as an
+// admin endpoint.
+func (s *Signaller) TriggerSoftStopFrom(src TriggerSource) {
+	s.triggerSoftStop(cause{source: src})
+}
+
+// TriggerHardStopFrom behaves as TriggerHardStop, but attributes the trigger to
+// the provided source.
+func (s *Signaller) TriggerHardStopFrom(src TriggerSource) {
+	s.triggerHardStop(cause{source: src})
+}
+
+// TriggeredBy returns the source of the trigger that signalled the tier, and
+// false if the tier has not been signalled. Sources are only recorded for
+// signallers constructed with options, and are otherwise reported as
+// SourceProgrammatic.
+func (s *Signaller) TriggeredBy(t Tier) (TriggerSource, bool) {
+	if s == nil || t < TierSoftStop || t > TierHasStopped || s.state.Load()&t.bit() == 0 {
+		return SourceProgrammatic, false
+	}
+	x := s.ext.Load()
+	if x == nil {
+		return SourceProgrammatic, true
+	}
+	return TriggerSource(x.sources[t].Load()), true
+}
+
+// recordSource records the source of the trigger that signalled a tier.
+func (s *Signaller) recordSource(t Tier, src TriggerSource) {
+	if x := s.ext.Load(); x != nil {
+		x.sources[t].Store(int32(src))
+	}
+}
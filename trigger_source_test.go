@@ -0,0 +1,86 @@
+package shutdown
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTriggeredBy(t *testing.T) {
+	s := NewSignaller(WithHistory(4))
+
+	_, ok := s.TriggeredBy(TierSoftStop)
+	assert.False(t, ok)
+
+	s.TriggerHardStopFrom(SourceAdminAPI)
+	s.TriggerHasStopped()
+
+	src, ok := s.TriggeredBy(TierSoftStop)
+	assert.True(t, ok)
+	assert.Equal(t, SourceAdminAPI, src)
+
+	src, _ = s.TriggeredBy(TierHardStop)
+	assert.Equal(t, SourceAdminAPI, src)
+
+	src, _ = s.TriggeredBy(TierHasStopped)
+	assert.Equal(t, SourceProgrammatic, src)
+
+	h := s.History()
+	require.Len(t, h, 3)
+	assert.Equal(t, SourceAdminAPI, h[0].TriggeredBy)
+	assert.Equal(t, SourceAdminAPI, h[1].TriggeredBy)
+	assert.Contains(t, h[1].String(), "by admin api")
+	assert.Equal(t, SourceProgrammatic, h[2].TriggeredBy)
+}
+
+func TestTriggeredByEscalation(t *testing.T) {
+	clock := newManualClock()
+	s := NewSignaller(WithClock(clock), WithHardStopGrace(time.Second))
+
+	s.TriggerSoftStop()
+	clock.Advance(time.Second)
+
+	src, _ := s.TriggeredBy(TierSoftStop)
+	assert.Equal(t, SourceProgrammatic, src)
+	src, _ = s.TriggeredBy(TierHardStop)
+	assert.Equal(t, SourceWatchdog, src)
+}
+
+func TestTriggeredByGroup(t *testing.T) {
+	g := NewGroup()
+	s := NewSignaller(WithName("member"))
+	g.Add(s)
+
+	g.TriggerSoftStop()
+	src, ok := s.TriggeredBy(TierSoftStop)
+	assert.True(t, ok)
+	assert.Equal(t, SourceParentContext, src)
+}
+
+func TestTriggeredByEvents(t *testing.T) {
+	s := NewSignaller()
+	events, cancel := s.Subscribe()
+	defer cancel()
+
+	s.TriggerSoftStopFrom(SourceOSSignal)
+	s.TriggerHasStopped()
+
+	e := <-events
+	assert.Equal(t, EventSoftStop, e.Kind)
+	assert.Equal(t, SourceOSSignal, e.TriggeredBy)
+
+	e = <-events
+	assert.Equal(t, EventHasStopped, e.Kind)
+	assert.Equal(t, SourceProgrammatic, e.TriggeredBy)
+}
+
+func TestTriggerSourceString(t *testing.T) {
+	assert.Equal(t, "programmatic", SourceProgrammatic.String())
+	assert.Equal(t, "os signal", SourceOSSignal.String())
+	assert.Equal(t, "admin api", SourceAdminAPI.String())
+	assert.Equal(t, "watchdog", SourceWatchdog.String())
+	assert.Equal(t, "parent context", SourceParentContext.String())
+	assert.Equal(t, "unknown", TriggerSource(-1).String())
+}
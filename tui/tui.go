@@ -0,0 +1,198 @@
+// Package tui renders a live dashboard of the shutdown of a shutdown.Signaller
+// to a terminal, showing the state of the signaller, a countdown to any
+// scheduled hard stop, and the diagnostics sections of the signaller, such as
+// the progress of drainers and the states of the components of a
+// shutdown.Builder.
+//
+// The dashboard is drawn with ANSI escape sequences, and so this package adds
+// no dependencies to a module. It is intended for local development and for
+// operators running a program interactively:
+//
+//	go tui.Run(s, os.Stderr)
+package tui
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Jeffail/shutdown"
+)
+
+// ANSI escape sequences used to draw the dashboard.
+const (
+	ansiReset  = "\x1b[0m"
+	ansiBold   = "\x1b[1m"
+	ansiDim    = "\x1b[2m"
+	ansiRed    = "\x1b[31m"
+	ansiGreen  = "\x1b[32m"
+	ansiYellow = "\x1b[33m"
+	ansiClear  = "\x1b[J"
+)
+
+// Option configures the dashboard.
+type Option func(d *dashboard)
+
+// WithInterval sets the interval at which the dashboard is redrawn, which is
+// 100 milliseconds by default.
+func WithInterval(interval time.Duration) Option {
+	return func(d *dashboard) {
+		d.interval = interval
+	}
+}
+
+// WithForce causes the dashboard to be drawn even when w is not a terminal.
+func WithForce() Option {
+	return func(d *dashboard) {
+		d.force = true
+	}
+}
+
+type dashboard struct {
+	interval time.Duration
+	force    bool
+
+	// The number of lines of the last frame drawn, which are redrawn over.
+	lines int
+}
+
+// Run draws the dashboard of the signaller to w from the moment a soft or hard
+// stop is signalled, redrawing it until the signaller has stopped, and then
+// returns once the final state has been drawn. Unless WithForce is provided Run
+// returns immediately when w is not a terminal, and so it can be called
+// unconditionally without polluting the logs of programs that are not run
+// interactively.
+func Run(s *shutdown.Signaller, w io.Writer, opts ...Option) {
+	d := &dashboard{interval: 100 * time.Millisecond}
+	for _, o := range opts {
+		o(d)
+	}
+	if !d.force && !isTerminal(w) {
+		return
+	}
+
+	select {
+	case <-s.SoftStopChan():
+	case <-s.HardStopChan():
+	case <-s.HasStoppedChan():
+		return
+	}
+
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+
+	for {
+		d.draw(w, s.Diagnostics())
+		select {
+		case <-s.HasStoppedChan():
+			d.draw(w, s.Diagnostics())
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// draw writes a frame over the previous one.
+func (d *dashboard) draw(w io.Writer, diag shutdown.Diagnostics) {
+	frame := render(diag)
+
+	var b strings.Builder
+	if d.lines > 0 {
+		fmt.Fprintf(&b, "\x1b[%dA\r", d.lines)
+	}
+	b.WriteString(ansiClear)
+	b.WriteString(frame)
+	d.lines = strings.Count(frame, "\n")
+
+	_, _ = io.WriteString(w, b.String())
+}
+
+// render returns a frame of the dashboard describing the diagnostics.
+func render(d shutdown.Diagnostics) string {
+	var b strings.Builder
+
+	name := d.Name
+	if name == "" {
+		name = "shutdown"
+	}
+	colour, state := stateOf(d)
+	fmt.Fprintf(&b, "%v%v%v %v%v%v\n", ansiBold, name, ansiReset, colour, state, ansiReset)
+
+	if !d.HardStopDeadline.IsZero() && !d.HardStop {
+		remaining := d.HardStopDeadline.Sub(d.Time)
+		if remaining < 0 {
+			remaining = 0
+		}
+		fmt.Fprintf(&b, "  hard stop in %v%v%v\n", ansiYellow, remaining.Round(100*time.Millisecond), ansiReset)
+	}
+	for _, e := range d.Extensions {
+		if e.Granted > 0 {
+			fmt.Fprintf(&b, "  %vextended by %v: %v%v\n", ansiDim, e.Granted, e.Reason, ansiReset)
+		}
+	}
+	if d.Goroutines > 0 {
+		fmt.Fprintf(&b, "  goroutines running: %v\n", d.Goroutines)
+	}
+
+	for _, sec := range d.Sections {
+		fmt.Fprintf(&b, "  %v%v%v\n", ansiBold, sec.Name, ansiReset)
+		for _, line := range strings.Split(strings.TrimRight(sec.Report, "\n"), "\n") {
+			if line == "" {
+				continue
+			}
+			fmt.Fprintf(&b, "    %v\n", colourLine(line))
+		}
+	}
+
+	if d.StopErr != nil {
+		fmt.Fprintf(&b, "  %verrors:%v\n", ansiRed, ansiReset)
+		for _, line := range strings.Split(d.StopErr.Error(), "\n") {
+			fmt.Fprintf(&b, "    %v\n", line)
+		}
+	}
+	return b.String()
+}
+
+func stateOf(d shutdown.Diagnostics) (colour, state string) {
+	switch {
+	case d.HasStopped:
+		return ansiDim, "stopped"
+	case d.HardStop:
+		return ansiRed, "hard stopping"
+	case d.SoftStop:
+		return ansiYellow, "soft stopping"
+	}
+	return ansiGreen, "running"
+}
+
+// colourLine highlights the state of a "name: state" line of a diagnostics
+// section, such as those of drainers and builder components.
+func colourLine(line string) string {
+	name, state, ok := strings.Cut(line, ": ")
+	if !ok {
+		return line
+	}
+	var colour string
+	switch state {
+	case "stopped", "drained":
+		colour = ansiGreen
+	case "soft stopping", "hard stopping", "draining", "pending":
+		colour = ansiYellow
+	case "failed", "aborted":
+		colour = ansiRed
+	default:
+		return line
+	}
+	return name + ": " + colour + state + ansiReset
+}
+
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,101 @@
+package tui
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Jeffail/shutdown"
+)
+
+type syncBuffer struct {
+	mut sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mut.Lock()
+	defer b.mut.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mut.Lock()
+	defer b.mut.Unlock()
+	return b.buf.String()
+}
+
+func TestRender(t *testing.T) {
+	now := time.Unix(1000, 0)
+	frame := render(shutdown.Diagnostics{
+		Name:             "app",
+		Time:             now,
+		SoftStop:         true,
+		HardStopDeadline: now.Add(time.Second * 12),
+		StopErr:          errors.New("flushing: nope"),
+		Sections: []shutdown.DiagnosticsSection{
+			{Name: "drainers", Report: "db: draining\nqueue: drained\ncache: aborted\n"},
+		},
+	})
+
+	assert.Equal(t, strings.Join([]string{
+		ansiBold + "app" + ansiReset + " " + ansiYellow + "soft stopping" + ansiReset,
+		"  hard stop in " + ansiYellow + "12s" + ansiReset,
+		"  " + ansiBold + "drainers" + ansiReset,
+		"    db: " + ansiYellow + "draining" + ansiReset,
+		"    queue: " + ansiGreen + "drained" + ansiReset,
+		"    cache: " + ansiRed + "aborted" + ansiReset,
+		"  " + ansiRed + "errors:" + ansiReset,
+		"    flushing: nope",
+		"",
+	}, "\n"), frame)
+}
+
+func TestRenderStopped(t *testing.T) {
+	frame := render(shutdown.Diagnostics{SoftStop: true, HardStop: true, HasStopped: true})
+	assert.Equal(t, ansiBold+"shutdown"+ansiReset+" "+ansiDim+"stopped"+ansiReset+"\n", frame)
+}
+
+func TestRun(t *testing.T) {
+	s := shutdown.NewSignaller(shutdown.WithName("app"))
+	var buf syncBuffer
+
+	done := make(chan struct{})
+	go func() {
+		Run(s, &buf, WithForce(), WithInterval(time.Millisecond))
+		close(done)
+	}()
+
+	time.Sleep(time.Millisecond * 10)
+	assert.Empty(t, buf.String())
+
+	s.TriggerSoftStop()
+	assert.Eventually(t, func() bool {
+		return strings.Contains(buf.String(), "soft stopping")
+	}, time.Second, time.Millisecond)
+
+	s.TriggerHasStopped()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("timed out")
+	}
+
+	out := buf.String()
+	assert.True(t, strings.HasSuffix(out, ansiClear+ansiBold+"app"+ansiReset+" "+ansiDim+"stopped"+ansiReset+"\n"), out)
+	assert.Contains(t, out, "\x1b[1A\r")
+}
+
+func TestRunNotTerminal(t *testing.T) {
+	s := shutdown.NewSignaller()
+	var buf bytes.Buffer
+
+	// Returns immediately rather than waiting for the signaller.
+	Run(s, &buf)
+	assert.Empty(t, buf.String())
+}
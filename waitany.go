@@ -0,0 +1,65 @@
+package shutdown
+
+import (
+	"context"
+)
+
+// anyWaiter notifies WaitAny that the signaller at an index has reached the
+// tier being waited on.
+type anyWaiter struct {
+	index int
+	fired chan<- int
+	w     waiter
+}
+
+func (a *anyWaiter) notify() {
+	select {
+	case a.fired <- a.index:
+	default:
+	}
+}
+
+// WaitAny blocks until any of the provided signallers has signalled the tier,
+// returning the index of that signaller within the arguments. When several
+// have already signalled the tier the lowest index is returned. If the context
+// is cancelled first then an index of -1 is returned along with the error of
+// the context. Nil signallers never signal and are therefore never reported.
+//
+// This allows a supervisor to watch a heterogeneous set of components and
+// react to whichever stops first, without building select cases by hand:
+//
+//	i, err := shutdown.WaitAny(ctx, shutdown.TierHasStopped, db, cache, server)
+func WaitAny(ctx context.Context, t Tier, sigs ...*Signaller) (int, error) {
+	for i, s := range sigs {
+		if s != nil && s.state.Load()&t.bit() != 0 {
+			return i, nil
+		}
+	}
+
+	fired := make(chan int, 1)
+	waiters := make([]anyWaiter, len(sigs))
+	for i, s := range sigs {
+		if s == nil {
+			continue
+		}
+		a := &waiters[i]
+		a.index, a.fired, a.w.n = i, fired, a
+		if !s.addWaiter(t, &a.w) {
+			a.notify()
+		}
+	}
+	defer func() {
+		for i, s := range sigs {
+			if s != nil {
+				s.removeWaiter(&waiters[i].w)
+			}
+		}
+	}()
+
+	select {
+	case i := <-fired:
+		return i, nil
+	case <-ctx.Done():
+		return -1, ctx.Err()
+	}
+}
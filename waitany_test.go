@@ -0,0 +1,60 @@
+package shutdown
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWaitAny(t *testing.T) {
+	a, b, c := NewSignaller(), NewSignaller(), NewSignaller()
+
+	go func() {
+		time.Sleep(time.Millisecond)
+		b.TriggerSoftStop()
+	}()
+
+	i, err := WaitAny(context.Background(), TierSoftStop, a, b, c)
+	require.NoError(t, err)
+	assert.Equal(t, 1, i)
+
+	// None of the signallers should retain a waiter once WaitAny returns.
+	for _, s := range []*Signaller{a, b, c} {
+		assert.Nil(t, s.waiters)
+	}
+}
+
+func TestWaitAnyAlreadySignalled(t *testing.T) {
+	a, b, c := NewSignaller(), NewSignaller(), NewSignaller()
+	c.TriggerHardStop()
+	b.TriggerHardStop()
+
+	i, err := WaitAny(context.Background(), TierHardStop, a, b, c)
+	require.NoError(t, err)
+	assert.Equal(t, 1, i)
+
+	i, err = WaitAny(context.Background(), TierSoftStop, nil, c)
+	require.NoError(t, err)
+	assert.Equal(t, 1, i)
+}
+
+func TestWaitAnyCancelled(t *testing.T) {
+	a, b := NewSignaller(), NewSignaller()
+	a.TriggerSoftStop()
+
+	ctx, done := context.WithTimeout(context.Background(), time.Millisecond)
+	defer done()
+
+	i, err := WaitAny(ctx, TierHasStopped, a, nil, b)
+	assert.Equal(t, -1, i)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Nil(t, a.waiters)
+	assert.Nil(t, b.waiters)
+
+	i, err = WaitAny(ctx, TierHasStopped)
+	assert.Equal(t, -1, i)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
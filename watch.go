@@ -0,0 +1,168 @@
+package shutdown
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// watchTiers maps the names accepted by the until parameter of
+// LifecycleHandler to tiers.
+var watchTiers = map[string]Tier{
+	"soft_stop":   TierSoftStop,
+	"hard_stop":   TierHardStop,
+	"has_stopped": TierHasStopped,
+}
+
+// lifecycleState is the response body of LifecycleHandler.
+type lifecycleState struct {
+	SoftStop   bool `json:"soft_stop"`
+	HardStop   bool `json:"hard_stop"`
+	HasStopped bool `json:"has_stopped"`
+}
+
+func currentLifecycleState(s *Signaller) lifecycleState {
+	return lifecycleState{
+		SoftStop:   s.IsSoftStopSignalled(),
+		HardStop:   s.IsHardStopSignalled(),
+		HasStopped: s.IsHasStoppedSignalled(),
+	}
+}
+
+// LifecycleHandler returns an HTTP handler that allows clients, such as
+// deployment tooling, to wait on the lifecycle of the signaller.
+//
+// By default a request long-polls until the tier named by the until query
+// parameter, which is one of soft_stop, hard_stop or has_stopped and defaults
+// to has_stopped, has been signalled, responding immediately if it already has
+// been. The response is a JSON object describing which tiers have been
+// signalled:
+//
+//	{"soft_stop":true,"hard_stop":false,"has_stopped":true}
+//
+// Requests that accept text/event-stream are instead sent a server-sent event
+// for each transition, beginning with those already made, until the signaller
+// has stopped. The name of each event is the name of its tier, or
+// soft_stop_aborted, and its data is the time of the transition in RFC 3339
+// format, which is empty for transitions made before the request.
+func LifecycleHandler(s *Signaller) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.Header.Get("Accept"), "text/event-stream") {
+			streamLifecycle(s, w, r)
+			return
+		}
+
+		until := r.URL.Query().Get("until")
+		if until == "" {
+			until = "has_stopped"
+		}
+		t, ok := watchTiers[until]
+		if !ok {
+			http.Error(w, fmt.Sprintf("unrecognised tier: %q", until), http.StatusBadRequest)
+			return
+		}
+
+		select {
+		case <-s.tierChan(t):
+		case <-r.Context().Done():
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(currentLifecycleState(s))
+	})
+}
+
+func eventName(k EventKind) string {
+	switch k {
+	case EventSoftStop:
+		return "soft_stop"
+	case EventHardStop:
+		return "hard_stop"
+	case EventHasStopped:
+		return "has_stopped"
+	case EventSoftStopAborted:
+		return "soft_stop_aborted"
+	case EventReload:
+		return "reload"
+	}
+	return "unknown"
+}
+
+func streamLifecycle(s *Signaller, w http.ResponseWriter, r *http.Request) {
+	events, cancel := s.Subscribe()
+	defer cancel()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	rc := http.NewResponseController(w)
+
+	sent := map[EventKind]bool{}
+	send := func(e Event) bool {
+		if e.Kind == EventSoftStopAborted {
+			delete(sent, EventSoftStop)
+		} else if sent[e.Kind] && e.Kind != EventReload {
+			return !sent[EventHasStopped]
+		}
+		sent[e.Kind] = true
+
+		var ts string
+		if !e.Time.IsZero() {
+			ts = e.Time.Format(time.RFC3339Nano)
+		}
+		if _, err := fmt.Fprintf(w, "event: %v\ndata: %v\n\n", eventName(e.Kind), ts); err != nil {
+			return false
+		}
+		_ = rc.Flush()
+		return e.Kind != EventHasStopped
+	}
+
+	// Transitions are replayed from the state of the signaller, which
+	// subscribers observe in order.
+	replay := func(at time.Time) bool {
+		state := currentLifecycleState(s)
+		for _, past := range []struct {
+			kind EventKind
+			made bool
+		}{
+			{EventSoftStop, state.SoftStop},
+			{EventHardStop, state.HardStop},
+			{EventHasStopped, state.HasStopped},
+		} {
+			if past.made && !send(Event{Kind: past.kind, Time: at}) {
+				return false
+			}
+		}
+		return true
+	}
+	if !replay(time.Time{}) {
+		return
+	}
+	_ = rc.Flush()
+
+	for {
+		select {
+		case e, open := <-events:
+			if !open || !send(e) {
+				return
+			}
+		case <-s.HasStoppedChan():
+			// Events are dropped by subscribers that fall behind, and so the
+			// stop is also observed directly, after sending those buffered.
+			for {
+				select {
+				case e, open := <-events:
+					if open && send(e) {
+						continue
+					}
+				default:
+					replay(s.clock().Now())
+				}
+				return
+			}
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
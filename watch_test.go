@@ -0,0 +1,117 @@
+package shutdown
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLifecycleHandlerLongPoll(t *testing.T) {
+	s := NewSignaller()
+	srv := httptest.NewServer(LifecycleHandler(s))
+	defer srv.Close()
+
+	respC := make(chan *http.Response, 1)
+	go func() {
+		resp, err := http.Get(srv.URL + "?until=soft_stop")
+		assert.NoError(t, err)
+		respC <- resp
+	}()
+
+	select {
+	case <-respC:
+		t.Fatal("responded before soft stop")
+	case <-time.After(time.Millisecond * 20):
+	}
+	s.TriggerSoftStop()
+
+	resp := <-respC
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var state lifecycleState
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&state))
+	assert.Equal(t, lifecycleState{SoftStop: true}, state)
+}
+
+func TestLifecycleHandlerAlreadySignalled(t *testing.T) {
+	s := NewSignaller()
+	s.TriggerHardStop()
+	s.TriggerHasStopped()
+
+	rec := httptest.NewRecorder()
+	LifecycleHandler(s).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"soft_stop":true,"hard_stop":true,"has_stopped":true}`, rec.Body.String())
+}
+
+func TestLifecycleHandlerBadTier(t *testing.T) {
+	rec := httptest.NewRecorder()
+	LifecycleHandler(NewSignaller()).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/?until=nope", nil))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestLifecycleHandlerCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	rec := httptest.NewRecorder()
+	LifecycleHandler(NewSignaller()).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx))
+	assert.Empty(t, rec.Body.String())
+}
+
+func TestLifecycleHandlerEventStream(t *testing.T) {
+	s := NewSignaller()
+	s.TriggerSoftStop()
+
+	srv := httptest.NewServer(LifecycleHandler(s))
+	defer srv.Close()
+
+	req, err := http.NewRequest(http.MethodGet, srv.URL, nil)
+	require.NoError(t, err)
+	req.Header.Set("Accept", "text/event-stream")
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	lines := bufio.NewScanner(resp.Body)
+	nextEvent := func() (name, data string) {
+		t.Helper()
+		for lines.Scan() {
+			line := lines.Text()
+			if line == "" {
+				return
+			}
+			if v, ok := strings.CutPrefix(line, "event: "); ok {
+				name = v
+			} else if v, ok := strings.CutPrefix(line, "data: "); ok {
+				data = v
+			}
+		}
+		t.Fatal("event stream ended")
+		return
+	}
+
+	name, data := nextEvent()
+	assert.Equal(t, "soft_stop", name)
+	assert.Empty(t, data)
+
+	s.TriggerHardStop()
+	name, data = nextEvent()
+	assert.Equal(t, "hard_stop", name)
+	assert.NotEmpty(t, data)
+
+	s.TriggerHasStopped()
+	name, _ = nextEvent()
+	assert.Equal(t, "has_stopped", name)
+	assert.False(t, lines.Scan())
+}
@@ -0,0 +1,64 @@
+package shutdown
+
+import (
+	"log/slog"
+	"time"
+)
+
+// WithWatchdog gives the signaller a stop deadline of its own, where if the
+// component has not reported having stopped within the deadline of the first
+// soft or hard stop the provided function is called, from its own goroutine.
+// This flags components that are known to stop quickly as soon as they overrun,
+// rather than only once a global timeout elapses, and the function is free to
+// log, record a metric or escalate the component alone with TriggerHardStop.
+// When the function is nil the overrun is logged to the logger of the
+// signaller at warning level instead.
+func WithWatchdog(deadline time.Duration, onOverrun func(s *Signaller)) Option {
+	return func(o *options) {
+		o.watchdogDeadline = deadline
+		o.onWatchdog = onOverrun
+	}
+}
+
+// armWatchdog starts the watchdog timer of the signaller, if it has one, and
+// is called when a soft or hard stop is signalled.
+func (s *Signaller) armWatchdog() {
+	x := s.ext.Load()
+	if x == nil || x.watchdogDeadline <= 0 {
+		return
+	}
+
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	if x.watchdog != nil || s.state.Load()&TierHasStopped.bit() != 0 {
+		return
+	}
+	x.watchdog = s.clock().AfterFunc(x.watchdogDeadline, func() {
+		// The timer may fire concurrently with an aborted soft stop.
+		if st := s.state.Load(); st&TierHasStopped.bit() != 0 || st&(TierSoftStop.bit()|TierHardStop.bit()) == 0 {
+			return
+		}
+		if x.onWatchdog != nil {
+			x.onWatchdog(s)
+			return
+		}
+		x.log(slog.LevelWarn, "shutdown watchdog deadline exceeded", "deadline", x.watchdogDeadline)
+	})
+}
+
+// disarmWatchdog stops the watchdog timer of the signaller, and is called once
+// the component has stopped or when a soft stop is aborted, after which the
+// next soft or hard stop arms a new timer.
+func (s *Signaller) disarmWatchdog() {
+	x := s.ext.Load()
+	if x == nil || x.watchdogDeadline <= 0 {
+		return
+	}
+
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	if x.watchdog != nil {
+		x.watchdog.Stop()
+		x.watchdog = nil
+	}
+}
@@ -0,0 +1,86 @@
+package shutdown
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWatchdog(t *testing.T) {
+	clock := newManualClock()
+	var overran []*Signaller
+	s := NewSignaller(WithClock(clock), WithWatchdog(time.Second, func(s *Signaller) {
+		overran = append(overran, s)
+		s.TriggerHardStop()
+	}))
+
+	clock.Advance(time.Second * 2)
+	assert.Empty(t, overran)
+
+	s.TriggerSoftStop()
+	clock.Advance(time.Millisecond * 999)
+	assert.Empty(t, overran)
+
+	clock.Advance(time.Millisecond)
+	assert.Equal(t, []*Signaller{s}, overran)
+	assert.True(t, s.IsHardStopSignalled())
+	assert.Equal(t, time.Second, s.Config().WatchdogDeadline)
+}
+
+func TestWatchdogStopped(t *testing.T) {
+	clock := newManualClock()
+	var overran bool
+	s := NewSignaller(WithClock(clock), WithWatchdog(time.Second, func(s *Signaller) {
+		overran = true
+	}))
+
+	s.TriggerHardStop()
+	s.TriggerHasStopped()
+	clock.Advance(time.Second)
+	assert.False(t, overran)
+}
+
+func TestWatchdogLogged(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}))
+
+	clock := newManualClock()
+	s := NewSignaller(WithName("foo"), WithClock(clock), WithLogger(logger), WithWatchdog(time.Second, nil))
+	s.TriggerSoftStop()
+	buf.Reset()
+
+	clock.Advance(time.Second)
+	assert.Equal(t, "level=WARN msg=\"shutdown watchdog deadline exceeded\" deadline=1s signaller=foo\n", buf.String())
+}
+
+func TestWatchdogAbortSoftStop(t *testing.T) {
+	clock := newManualClock()
+	var overruns int
+	s := NewSignaller(WithClock(clock), WithReversibleSoftStop(), WithWatchdog(time.Second, func(s *Signaller) {
+		overruns++
+	}))
+
+	s.TriggerSoftStop()
+	clock.Advance(time.Millisecond * 500)
+	assert.True(t, s.AbortSoftStop())
+
+	clock.Advance(time.Second)
+	assert.Equal(t, 0, overruns)
+
+	s.TriggerSoftStop()
+	clock.Advance(time.Millisecond * 999)
+	assert.Equal(t, 0, overruns)
+
+	clock.Advance(time.Millisecond)
+	assert.Equal(t, 1, overruns)
+}